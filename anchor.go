@@ -0,0 +1,45 @@
+package parser
+
+import (
+	"github.com/di-wu/parser/op"
+	"strings"
+	"unicode"
+)
+
+// anchored checks whether the parser is at a position that matches the given
+// anchor.
+func (p *Parser) anchored(a op.Anchor) bool {
+	switch a {
+	case op.SOI:
+		return p.cursor.position == 0
+	case op.EOI:
+		p.SkipTrivia()
+		return p.Done()
+	case op.BOL:
+		previous := p.LookBackRune(1)
+		return previous == EOD || previous == '\n' ||
+			(previous == '\r' && p.cursor.Rune != '\n')
+	case op.EOL:
+		return p.Done() || p.cursor.Rune == '\n' || p.cursor.Rune == '\r'
+	case op.WordBoundary:
+		return isWord(p.LookBackRune(1)) != (!p.Done() && isWord(p.cursor.Rune))
+	}
+	return false
+}
+
+// isWord checks whether the given rune is part of a word, see op.WordBoundary
+// and op.Keyword.
+func isWord(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// isReserved checks whether the given word is one of the reserved words of the
+// given value. Words are compared case insensitive if the parser is.
+func (p *Parser) isReserved(r op.Reserved, word string) bool {
+	for _, w := range r.Words {
+		if w == word || p.fold && strings.EqualFold(w, word) {
+			return true
+		}
+	}
+	return false
+}
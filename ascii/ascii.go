@@ -0,0 +1,76 @@
+// Package ascii provides classes for ASCII-only grammars. Classes are
+// implemented with a lookup table instead of range comparisons.
+package ascii
+
+import "github.com/di-wu/parser"
+
+// Class is a set of ASCII character classes. Classes can be combined using the
+// bitwise or operator. e.g. Alpha | Digit is equal to AlphaNum.
+type Class uint8
+
+// Predefined classes.
+const (
+	// Lower matches 'a' to 'z'.
+	Lower Class = 1 << iota
+	// Upper matches 'A' to 'Z'.
+	Upper
+	// Digit matches '0' to '9'.
+	Digit
+	// hex matches 'a' to 'f' and 'A' to 'F'.
+	hex
+	// Space matches ' ', '\t', '\n', '\v', '\f' and '\r'.
+	Space
+	// Punct matches all printable characters that are not a space, letter or
+	// digit.
+	Punct
+	// Control matches all control characters (0x00 to 0x1F and 0x7F).
+	Control
+
+	// Alpha matches all letters.
+	Alpha = Lower | Upper
+	// AlphaNum matches all letters and digits.
+	AlphaNum = Alpha | Digit
+	// HexDigit matches all hexadecimal digits.
+	HexDigit = Digit | hex
+)
+
+// table contains the classes of every ASCII character.
+var table [128]Class
+
+func init() {
+	for r := range table {
+		var c Class
+		switch {
+		case 'a' <= r && r <= 'z':
+			c |= Lower
+		case 'A' <= r && r <= 'Z':
+			c |= Upper
+		case '0' <= r && r <= '9':
+			c |= Digit
+		case r == ' ' || '\t' <= r && r <= '\r':
+			c |= Space
+		case '!' <= r && r <= '~':
+			c |= Punct
+		}
+		if 'a' <= r && r <= 'f' || 'A' <= r && r <= 'F' {
+			c |= hex
+		}
+		if r < ' ' || r == 0x7F {
+			c |= Control
+		}
+		table[r] = c
+	}
+}
+
+// Contains checks whether the given rune is part of the class.
+func (c Class) Contains(r rune) bool {
+	return 0 <= r && r < 128 && table[r]&c != 0
+}
+
+// Check checks whether the current rune of the parser is part of the class.
+func (c Class) Check(p *parser.Parser) (*parser.Cursor, bool) {
+	if !c.Contains(p.Current()) {
+		return nil, false
+	}
+	return p.Mark(), true
+}
@@ -0,0 +1,76 @@
+package ascii_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ascii"
+	"github.com/di-wu/parser/op"
+	"testing"
+	"unicode"
+)
+
+func ExampleClass() {
+	p, _ := parser.New([]byte("x1 = 0xFF"))
+
+	fmt.Println(p.Expect(ascii.Alpha, op.MinZero(ascii.AlphaNum)))
+	fmt.Println(p.SkipWhile(ascii.Space | ascii.Punct))
+	fmt.Println(p.Expect("0x", op.MinOne(ascii.HexDigit)))
+	// Output:
+	// U+0031: 1 <nil>
+	// 3
+	// U+0046: F <nil>
+}
+
+func TestClass(t *testing.T) {
+	for _, test := range []struct {
+		class ascii.Class
+		f     func(r rune) bool
+	}{
+		{ascii.Lower, unicode.IsLower},
+		{ascii.Upper, unicode.IsUpper},
+		{ascii.Alpha, unicode.IsLetter},
+		{ascii.Digit, unicode.IsDigit},
+		{ascii.AlphaNum, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }},
+		{ascii.HexDigit, func(r rune) bool { return unicode.Is(unicode.ASCII_Hex_Digit, r) }},
+		{ascii.Space, func(r rune) bool { return unicode.IsSpace(r) && r != 0x85 && r != 0xA0 }},
+		{ascii.Punct, func(r rune) bool { return unicode.IsPunct(r) || unicode.IsSymbol(r) }},
+		{ascii.Control, unicode.IsControl},
+	} {
+		for r := rune(-1); r <= 0x100; r++ {
+			expected := 0 <= r && r < 0x80 && test.f(r)
+			if test.class.Contains(r) != expected {
+				t.Errorf("%08b: %U, expected %v", test.class, r, expected)
+			}
+		}
+	}
+}
+
+func BenchmarkClass_Contains(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = ascii.AlphaNum.Contains(rune(i & 0x7F))
+	}
+}
+
+func BenchmarkParser_SkipWhile(b *testing.B) {
+	input := []byte("abcdefghijklmnopqrstuvwxyz0123456789 ")
+	p, _ := parser.New(input)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = p.Reset(input)
+		p.SkipWhile(ascii.AlphaNum)
+	}
+}
+
+func BenchmarkParser_SkipWhile_unicode(b *testing.B) {
+	input := []byte("abcdefghijklmnopqrstuvwxyz0123456789 ")
+	p, _ := parser.New(input)
+	alphaNum := op.Or{parser.Letter, parser.Digit}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = p.Reset(input)
+		p.SkipWhile(alphaNum)
+	}
+}
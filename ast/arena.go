@@ -0,0 +1,93 @@
+package ast
+
+import "github.com/di-wu/parser"
+
+// arenaBlockSize is the number of nodes or cursors that an arena allocates at
+// once.
+const arenaBlockSize = 1024
+
+// Arena allocates the nodes of a parser and the cursors of their spans in
+// blocks, instead of one by one. The marks and errors of the underlying parser
+// are still allocated one by one, which are most of the allocations of a parse.
+// An arena only removes about a fifth of them, see BenchmarkParser_SetArena.
+// The zero value is an empty arena ready to use, see Parser.SetArena.
+//
+// Free releases all the nodes at once, so the blocks can be reused by the next
+// parse. Arenas can also be kept in a sync.Pool to reuse them between parsers.
+type Arena struct {
+	nodes        []Node
+	nodeBlocks   [][]Node
+	nodeBlock    int
+	cursors      []parser.Cursor
+	cursorBlocks [][]parser.Cursor
+	cursorBlock  int
+}
+
+// Free releases all the nodes and cursors that were allocated by the arena.
+// They are reused by later allocations, so none of the nodes of the previous
+// parses can be used anymore.
+func (a *Arena) Free() {
+	a.nodes, a.nodeBlock = nil, 0
+	a.cursors, a.cursorBlock = nil, 0
+}
+
+// node returns a new (zeroed) node.
+func (a *Arena) node() *Node {
+	if len(a.nodes) == 0 {
+		if a.nodeBlock == len(a.nodeBlocks) {
+			a.nodeBlocks = append(a.nodeBlocks, make([]Node, arenaBlockSize))
+		}
+		a.nodes = a.nodeBlocks[a.nodeBlock]
+		a.nodeBlock++
+	}
+	n := &a.nodes[0]
+	a.nodes = a.nodes[1:]
+	*n = Node{}
+	return n
+}
+
+// cursor returns a new cursor that is equal to the given one.
+func (a *Arena) cursor(c parser.Cursor) *parser.Cursor {
+	if len(a.cursors) == 0 {
+		if a.cursorBlock == len(a.cursorBlocks) {
+			a.cursorBlocks = append(a.cursorBlocks, make([]parser.Cursor, arenaBlockSize))
+		}
+		a.cursors = a.cursorBlocks[a.cursorBlock]
+		a.cursorBlock++
+	}
+	cursor := &a.cursors[0]
+	a.cursors = a.cursors[1:]
+	*cursor = c
+	return cursor
+}
+
+// SetArena sets the arena that allocates the nodes of the parser and the
+// cursors of their spans. Nodes are allocated one by one if it is nil.
+func (ap *Parser) SetArena(a *Arena) {
+	ap.arena = a
+}
+
+// newNode returns a new node of the given type, allocated by the arena of the
+// parser if it has one. All nodes should be created by it, so they are counted
+// (see WithMaxNodes).
+func (ap *Parser) newNode(typ int) *Node {
+	if typ != -1 {
+		// Groups of nodes are not counted.
+		ap.nodes++
+	}
+	if ap.arena == nil {
+		return &Node{Type: typ}
+	}
+	n := ap.arena.node()
+	n.Type = typ
+	return n
+}
+
+// mark returns a mark to the current position, allocated by the arena of the
+// parser if it has one.
+func (ap *Parser) mark() *parser.Cursor {
+	if ap.arena == nil {
+		return ap.internal.Mark()
+	}
+	return ap.arena.cursor(ap.internal.MarkV())
+}
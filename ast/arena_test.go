@@ -0,0 +1,74 @@
+package ast_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+)
+
+func ExampleParser_SetArena() {
+	var arena ast.Arena
+	p, _ := ast.New([]byte("1+2"))
+	p.SetArena(&arena)
+	n, _ := p.Expect(expression)
+	_ = n // Use the tree.
+
+	// Release all the nodes, e.g. before parsing the next input.
+	arena.Free()
+}
+
+func TestParser_SetArena(t *testing.T) {
+	input := []byte(strings.Repeat("1+2*-3!,", 500) + "4")
+	list := op.SeparatedBy{Element: expression, Separator: ','}
+
+	p, _ := ast.New(input)
+	expected, err := p.Expect(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var arena ast.Arena
+	for i := 0; i < 2; i++ {
+		// The second time, the blocks of the first parse are reused.
+		arena.Free()
+		p, _ := ast.New(input)
+		p.SetArena(&arena)
+		n, err := p.Expect(list)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ast.Equal(expected, n) {
+			t.Fatal(ast.Diff(expected, n))
+		}
+		if n.LastChild.Start.Offset() != len(input)-1 || n.LastChild.End.Offset() != len(input) {
+			t.Error("unexpected span of the last node")
+		}
+	}
+}
+
+// BenchmarkParser_SetArena compares parsing with and without an arena. The
+// arena reduces the allocations from 9840 to 7734 per parse (1.33 to 1.04 MB),
+// the time per parse stays about the same.
+func BenchmarkParser_SetArena(b *testing.B) {
+	input := []byte(strings.Repeat("1+2*-3!,", 100) + "4")
+	list := op.SeparatedBy{Element: expression, Separator: ','}
+	b.Run("heap", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			p, _ := ast.New(input)
+			_, _ = p.Expect(list)
+		}
+	})
+	b.Run("arena", func(b *testing.B) {
+		var arena ast.Arena
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			arena.Free()
+			p, _ := ast.New(input)
+			p.SetArena(&arena)
+			_, _ = p.Expect(list)
+		}
+	})
+}
@@ -1,6 +1,11 @@
 package ast
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+)
 
 // Capture is a structure to indicate that the value should be converted to a
 // node. If one of the children returns a node, then that node gets returned
@@ -11,11 +16,114 @@ type Capture struct {
 	TypeStrings []string
 	// Value is the expression to capture the value of the node.
 	Value interface{}
+	// Convert converts the captured value to the Data of the node, e.g.
+	// parser.Integer.Convert. If the conversion fails, the capture fails with
+	// the error at its position. Only used if the capture creates the node
+	// itself.
+	Convert func(value string) (interface{}, error)
+	// Collapse returns the child node of the value instead of a node of the
+	// capture if the value results in a single child node, to avoid chains of
+	// wrapper nodes. See also Parser.SetCollapse.
+	Collapse bool
+
+	// OnEnter is called with the context of the parser (see SetContext) before
+	// the value is matched, e.g. to open a scope.
+	OnEnter func(context interface{}) error
+	// OnExit is called with the node and the context of the parser after the
+	// value is matched, e.g. to declare a symbol. It is always called after
+	// OnEnter, with a nil node if the value did not match.
+	//
+	// If a hook returns an error, the capture fails with the error at its
+	// position. Hooks are also called for values that are discarded later on,
+	// e.g. alternatives that fail afterwards.
+	OnExit func(n *Node, context interface{}) error
+}
+
+// Capturer is implemented by values that can be expected as a Capture, e.g. a
+// TypedCapture.
+type Capturer interface {
+	Capture() Capture
 }
 
 func (c Capture) String() string {
-	if 0 <= c.Type && c.Type < len(c.TypeStrings) {
-		return fmt.Sprintf("%s", c.TypeStrings[c.Type])
+	if name, ok := TypeName(c.Type, c.TypeStrings); ok {
+		return name
 	}
 	return fmt.Sprintf("{%03d}", c.Type)
 }
+
+// capture matches the value of the capture and returns its node.
+func (ap *Parser) capture(c Capture) (*Node, error) {
+	// Do not capture leading trivia.
+	ap.internal.SkipTrivia()
+	begin := ap.mark()
+	if c.OnEnter != nil {
+		if err := c.OnEnter(ap.context); err != nil {
+			return nil, ap.failure(err, begin)
+		}
+	}
+	node, err := ap.captured(c, begin)
+	if c.OnExit != nil {
+		if exitErr := c.OnExit(node, ap.context); err == nil && exitErr != nil {
+			return nil, ap.failure(exitErr, begin)
+		}
+	}
+	return node, err
+}
+
+// captured matches the value of the capture, starting at the given mark.
+func (ap *Parser) captured(c Capture, begin *parser.Cursor) (*Node, error) {
+	p := ap.internal
+	node, err := ap.Expect(c.Value)
+	if err != nil {
+		return nil, err
+	}
+	if node != nil {
+		if (c.Collapse || ap.collapse) && node.Type == -1 &&
+			node.FirstChild != nil && node.FirstChild == node.LastChild {
+			// Only a single child, no need to wrap it.
+			return node.FirstChild.Remove(), nil
+		}
+		// Return the node.
+		if node.Type == -1 {
+			node.Type = c.Type
+		}
+		if len(node.TypeStrings) == 0 {
+			node.TypeStrings = ap.typeStrings(c)
+		}
+		if node.Start == nil {
+			node.Start, node.End = begin, ap.mark()
+		}
+		return node, nil
+	}
+
+	var value string
+	if p.Offset() != begin.Offset() {
+		value = p.Slice(begin, p.LookBack())
+	} else if ap.skipEmpty {
+		// Matched the empty string.
+		return nil, nil
+	}
+	var data interface{}
+	if c.Convert != nil {
+		if data, err = c.Convert(value); err != nil {
+			return nil, ap.failure(err, begin)
+		}
+	}
+	node = ap.newNode(c.Type)
+	node.TypeStrings = ap.typeStrings(c)
+	node.Value = value
+	node.Data = data
+	node.Start, node.End = begin, ap.mark()
+	return node, nil
+}
+
+// failure converts the error of a user function to a parse error at the given
+// mark. Parse errors and errors about unsupported values are returned as is.
+func (ap *Parser) failure(err error, at *parser.Cursor) error {
+	switch err.(type) {
+	case *parser.ExpectedParseError, *parser.ExpectError:
+		return err
+	}
+	return ap.internal.ExpectedParseError(op.Fail{Message: err.Error()}, at, at)
+}
@@ -0,0 +1,73 @@
+package ast
+
+// ChainLeft represents one or more operands separated by operators, of which
+// the operations are grouped from the left. e.g. "1 - 2 - 3" results in
+//
+//	["Sub",[["Sub",[["Int","1"],["Int","2"]]],["Int","3"]]]
+//
+// If an operator is not followed by an operand, the chain ends before the
+// operator.
+type ChainLeft struct {
+	Operand  interface{}
+	Operator interface{}
+	// Fold combines two operands and their operator into a single node. By
+	// default the operands become the children of the node of the operator.
+	Fold func(left, operator, right *Node) *Node
+}
+
+// ChainRight works the same as ChainLeft, but groups the operations from the
+// right. e.g. "2 ^ 3 ^ 4" results in
+//
+//	["Pow",[["Int","2"],["Pow",[["Int","3"],["Int","4"]]]]]
+type ChainRight struct {
+	Operand  interface{}
+	Operator interface{}
+	// Fold combines two operands and their operator into a single node. By
+	// default the operands become the children of the node of the operator.
+	Fold func(left, operator, right *Node) *Node
+}
+
+// fold combines the given operands and operator with the given function, or
+// the default one if it is nil.
+func fold(f func(left, operator, right *Node) *Node, left, operator, right *Node) *Node {
+	if f != nil {
+		return f(left, operator, right)
+	}
+	if operator == nil {
+		// The operator did not result in a node.
+		operator = &Node{Type: -1}
+	}
+	return operation(operator, left, right)
+}
+
+// chain parses one or more operands separated by operators. Returns the
+// operands and the operators in between them.
+func (ap *Parser) chain(operand, operator interface{}) ([]*Node, []*Node, error) {
+	p := ap.internal
+	node, err := ap.Expect(operand)
+	if err != nil {
+		return nil, nil, err
+	}
+	operands := []*Node{node}
+	var operators []*Node
+	for {
+		mark := p.Mark()
+		o, err := ap.Expect(operator)
+		if err != nil {
+			break
+		}
+		node, err := ap.Expect(operand)
+		if err != nil {
+			// The operator has no operand, leave it unconsumed.
+			p.Jump(mark)
+			break
+		}
+		if p.Offset() == mark.Offset() {
+			// Neither consumed anything, the chain would never end.
+			break
+		}
+		operands = append(operands, node)
+		operators = append(operators, o)
+	}
+	return operands, operators, nil
+}
@@ -0,0 +1,56 @@
+package ast_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+)
+
+func ExampleParser_Expect_chainLeft() {
+	types := []string{"Int", "Sub"}
+	sub := ast.ChainLeft{
+		Operand:  ast.Capture{Type: 0, TypeStrings: types, Value: parser.CheckRuneRange('0', '9')},
+		Operator: ast.Capture{Type: 1, TypeStrings: types, Value: '-'},
+	}
+
+	p, _ := ast.New([]byte("1-2-3-"))
+	fmt.Println(p.Expect(sub))
+	// Output:
+	// ["Sub",[["Sub",[["Int","1"],["Int","2"]]],["Int","3"]]] <nil>
+}
+
+func ExampleParser_Expect_chainRight() {
+	types := []string{"Int", "Pow"}
+	pow := ast.ChainRight{
+		Operand:  ast.Capture{Type: 0, TypeStrings: types, Value: parser.CheckRuneRange('0', '9')},
+		Operator: '^',
+		Fold: func(left, _, right *ast.Node) *ast.Node {
+			node := &ast.Node{Type: 1, TypeStrings: types}
+			node.SetLast(left)
+			node.SetLast(right)
+			return node
+		},
+	}
+
+	p, _ := ast.New([]byte("2^3^4"))
+	fmt.Println(p.Expect(pow))
+	// Output:
+	// ["Pow",[["Int","2"],["Pow",[["Int","3"],["Int","4"]]]]] <nil>
+}
+
+func TestChainLeft_noProgress(t *testing.T) {
+	types := []string{"Int", "Sub"}
+	// Both the operand and the operator can match nothing.
+	sub := ast.ChainLeft{
+		Operand:  ast.Capture{Type: 0, TypeStrings: types, Value: op.MinZero(parser.CheckRuneRange('0', '9'))},
+		Operator: ast.Capture{Type: 1, TypeStrings: types, Value: op.Optional('-')},
+	}
+
+	p, _ := ast.New([]byte("1x"))
+	if node, err := p.Expect(sub); err != nil || node.String() != `["Int","1"]` {
+		t.Error(node, err)
+	}
+}
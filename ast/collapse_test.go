@@ -0,0 +1,76 @@
+package ast_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+)
+
+const (
+	StatementType = iota
+	SumType
+	TermType
+	NumberLiteralType
+)
+
+var (
+	collapseTypes = []string{"Statement", "Sum", "Term", "Number"}
+	number        = ast.Capture{
+		Type:        NumberLiteralType,
+		TypeStrings: collapseTypes,
+		Value:       op.MinOne(parser.CheckRuneRange('0', '9')),
+	}
+	term = ast.Capture{
+		Type:        TermType,
+		TypeStrings: collapseTypes,
+		Value:       op.And{number, op.MinZero(op.And{'*', number})},
+	}
+	sum = ast.Capture{
+		Type:        SumType,
+		TypeStrings: collapseTypes,
+		Value:       op.And{term, op.MinZero(op.And{'+', term})},
+	}
+	statement = ast.Capture{
+		Type:        StatementType,
+		TypeStrings: collapseTypes,
+		Value:       op.And{sum, ';'},
+	}
+)
+
+func ExampleCapture_collapse() {
+	p, _ := ast.New([]byte("1+2*3;"))
+	fmt.Println(p.Expect(statement))
+
+	p, _ = ast.New([]byte("1+2*3;"))
+	p.SetCollapse(true)
+	fmt.Println(p.Expect(statement))
+	// Output:
+	// ["Statement",[["Sum",[["Term",[["Number","1"]]],["Term",[["Number","2"],["Number","3"]]]]]]] <nil>
+	// ["Sum",[["Number","1"],["Term",[["Number","2"],["Number","3"]]]]] <nil>
+}
+
+func TestCapture_collapse(t *testing.T) {
+	collapsed := term
+	collapsed.Collapse = true
+	for _, test := range []struct {
+		input, tree string
+	}{
+		{input: "1", tree: `["Number","1"]`},
+		{input: "1*2", tree: `["Term",[["Number","1"],["Number","2"]]]`},
+	} {
+		p, _ := ast.New([]byte(test.input))
+		n, err := p.Expect(collapsed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n.String() != test.tree {
+			t.Errorf("expected %s, got %s", test.tree, n)
+		}
+		if n.Parent != nil || n.NextSibling != nil {
+			t.Error("collapsed node still references the group")
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Difference is a difference between two trees, see Diff.
+type Difference struct {
+	// Path contains the indices of the children that lead from the root to the
+	// node, e.g. [1 0] is the first child of the second child of the root.
+	Path []int
+	// Expected node, nil if the node is missing from the expected tree.
+	Expected *Node
+	// Actual node, nil if the node is missing from the actual tree.
+	Actual *Node
+}
+
+func (d Difference) String() string {
+	var path strings.Builder
+	path.WriteString("root")
+	for _, i := range d.Path {
+		fmt.Fprintf(&path, "[%d]", i)
+	}
+	return fmt.Sprintf("%s: expected %s, got %s", path.String(), diffString(d.Expected), diffString(d.Actual))
+}
+
+// diffString returns the string representation of the node, or "nothing" if
+// the node is nil.
+func diffString(n *Node) string {
+	if n == nil {
+		return "nothing"
+	}
+	return n.String()
+}
+
+// Diff returns the differences between the expected and actual tree, in the
+// same way as Equal compares them. Nodes of which the type or value differ are
+// reported as a whole, their children are not compared. Returns nil if both
+// trees are equal.
+func Diff(expected, actual *Node) []Difference {
+	return diff(nil, expected, actual, nil)
+}
+
+// diff appends the differences between both nodes at the given path.
+func diff(path []int, expected, actual *Node, differences []Difference) []Difference {
+	if expected == nil || actual == nil || expected.Type != actual.Type || expected.Value != actual.Value {
+		if expected == nil && actual == nil {
+			return differences
+		}
+		return append(differences, Difference{
+			Path:     append([]int(nil), path...),
+			Expected: expected,
+			Actual:   actual,
+		})
+	}
+	x, y := expected.FirstChild, actual.FirstChild
+	for i := 0; x != nil || y != nil; i++ {
+		differences = diff(append(path, i), x, y, differences)
+		if x != nil {
+			x = x.NextSibling
+		}
+		if y != nil {
+			y = y.NextSibling
+		}
+	}
+	return differences
+}
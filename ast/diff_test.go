@@ -0,0 +1,63 @@
+package ast_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/di-wu/parser/ast"
+)
+
+func ExampleDiff() {
+	expected, _ := ast.New([]byte("1+2*3"))
+	actual, _ := ast.New([]byte("1+2*4"))
+	a, _ := expected.Expect(expression)
+	b, _ := actual.Expect(expression)
+	for _, d := range ast.Diff(a, b) {
+		fmt.Println(d)
+	}
+	// Output:
+	// root[1][1]: expected ["Int","3"], got ["Int","4"]
+}
+
+func TestDiff(t *testing.T) {
+	for _, test := range []struct {
+		expected, actual *ast.Node
+		differences      []string
+	}{
+		{expected: nil, actual: nil},
+		{expected: tree("r", tree("a")), actual: tree("r", tree("a"))},
+		{
+			expected:    tree("a"),
+			actual:      nil,
+			differences: []string{`root: expected ["UNKNOWN","a"], got nothing`},
+		},
+		{
+			expected:    tree("r", tree("a", tree("b"))),
+			actual:      tree("s", tree("a")),
+			differences: []string{`root: expected ["UNKNOWN",[["UNKNOWN",[["UNKNOWN","b"]]]]], got ["UNKNOWN",[["UNKNOWN","a"]]]`},
+		},
+		{
+			expected: tree("r", tree("a"), tree("b", tree("c"))),
+			actual:   tree("r", tree("x"), tree("b", tree("c"), tree("d")), tree("e")),
+			differences: []string{
+				`root[0]: expected ["UNKNOWN","a"], got ["UNKNOWN","x"]`,
+				`root[1][1]: expected nothing, got ["UNKNOWN","d"]`,
+				`root[2]: expected nothing, got ["UNKNOWN","e"]`,
+			},
+		},
+	} {
+		differences := ast.Diff(test.expected, test.actual)
+		if len(differences) != len(test.differences) {
+			t.Errorf("expected %d differences, got %v", len(test.differences), differences)
+			continue
+		}
+		for i, d := range differences {
+			if s := d.String(); s != test.differences[i] {
+				t.Errorf("expected %s, got %s", test.differences[i], s)
+			}
+		}
+		if ast.Equal(test.expected, test.actual) != (len(differences) == 0) {
+			t.Error("Diff does not agree with Equal")
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package ast
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// maxDOTValue is the maximum number of runes of a value shown in a DOT graph.
+const maxDOTValue = 32
+
+// WriteDOT writes the tree of the given node as a Graphviz graph. Every node is
+// labeled with its type and its value, values longer than 32 runes are
+// truncated. e.g. `dot -Tsvg` renders the output as an image.
+func WriteDOT(w io.Writer, n *Node) error {
+	if _, err := io.WriteString(w, "digraph AST {\n\tnode [shape=box];\n"); err != nil {
+		return err
+	}
+	var id int
+	if err := writeDOT(w, n, &id); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "}\n")
+	return err
+}
+
+// writeDOT writes the given node and its children. The given id is the id of
+// the node, it is incremented for every written node.
+func writeDOT(w io.Writer, n *Node, id *int) error {
+	self := *id
+	*id++
+	label := n.TypeString()
+	if !n.IsParent() {
+		label += "\n" + truncate(n.Value, maxDOTValue)
+	}
+	if _, err := fmt.Fprintf(w, "\tn%d [label=%s];\n", self, strconv.Quote(label)); err != nil {
+		return err
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if _, err := fmt.Fprintf(w, "\tn%d -> n%d;\n", self, *id); err != nil {
+			return err
+		}
+		if err := writeDOT(w, c, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// truncate shortens the given string to the given number of runes, the last
+// rune is replaced by an ellipsis.
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
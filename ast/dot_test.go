@@ -0,0 +1,40 @@
+package ast_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/di-wu/parser/ast"
+)
+
+func ExampleWriteDOT() {
+	p, _ := ast.New([]byte("1+2*3"))
+	n, _ := p.Expect(expression)
+	_ = ast.WriteDOT(os.Stdout, n)
+	// Output:
+	// digraph AST {
+	// 	node [shape=box];
+	// 	n0 [label="Add"];
+	// 	n0 -> n1;
+	// 	n1 [label="Int\n1"];
+	// 	n0 -> n2;
+	// 	n2 [label="Mul"];
+	// 	n2 -> n3;
+	// 	n3 [label="Int\n2"];
+	// 	n2 -> n4;
+	// 	n4 [label="Int\n3"];
+	// }
+}
+
+func TestWriteDOT(t *testing.T) {
+	var b strings.Builder
+	n := tree(`"` + strings.Repeat("é", 40))
+	if err := ast.WriteDOT(&b, n); err != nil {
+		t.Fatal(err)
+	}
+	label := `n0 [label="UNKNOWN\n\"` + strings.Repeat("é", 30) + `…"];`
+	if !strings.Contains(b.String(), label) {
+		t.Errorf("expected %s in %s", label, b.String())
+	}
+}
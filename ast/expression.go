@@ -0,0 +1,178 @@
+package ast
+
+import "github.com/di-wu/parser"
+
+// Associativity indicates how infix operators of the same precedence group.
+type Associativity int
+
+const (
+	// LeftAssociative operators group from the left, e.g. (a - b) - c.
+	LeftAssociative Associativity = iota
+	// RightAssociative operators group from the right, e.g. a ^ (b ^ c).
+	RightAssociative
+	// NonAssociative operators can not be chained, e.g. a < b < c only matches
+	// a < b.
+	NonAssociative
+)
+
+// Operator is an operator of an Expression.
+type Operator struct {
+	// Type of the node of the operation.
+	Type int
+	// Value matches the operator itself, e.g. '+'. Its nodes are discarded.
+	Value interface{}
+	// Precedence of the operator, operators with a higher precedence bind
+	// tighter.
+	Precedence int
+	// Associativity of the operator, only used by infix operators.
+	Associativity Associativity
+}
+
+// Expression represents an expression with operators, parsed by precedence
+// climbing. Every operation results in a node of the type of its operator, with
+// its operands as children. e.g. "-1 + 2 * 3" results in
+//
+//	["Add",[["Neg",[["Int","1"]]],["Mul",[["Int","2"],["Int","3"]]]]]
+//
+// Operators are tried in the given order, so operators that are a prefix of
+// another operator (e.g. '*' and "**") should come after it. If an infix
+// operator is not followed by an operand, the expression ends before the
+// operator.
+type Expression struct {
+	// TypeStrings contains all the string representations of the available types.
+	TypeStrings []string
+	// Operand matches the operands, e.g. literals and parenthesized expressions.
+	// Operands should result in a node.
+	Operand interface{}
+	// Prefix operators, e.g. negation.
+	Prefix []Operator
+	// Infix operators, e.g. addition.
+	Infix []Operator
+	// Postfix operators, e.g. factorial.
+	Postfix []Operator
+}
+
+// expression parses an expression of which all operators have at least the
+// given precedence.
+func (ap *Parser) expression(e Expression, minimum int) (*Node, error) {
+	p := ap.internal
+	left, err := ap.prefix(e)
+	if err != nil {
+		return nil, err
+	}
+
+	// The highest precedence that the next infix operator can have.
+	maximum := unbounded
+	for {
+		mark := p.Mark()
+		if o, ok := ap.matchOperator(e.Postfix, minimum, unbounded); ok && p.Offset() != mark.Offset() {
+			left = ap.operation(e, o, left)
+			left.End = ap.mark()
+			continue
+		}
+
+		o, ok := ap.matchOperator(e.Infix, minimum, maximum)
+		if !ok {
+			break
+		}
+		next := o.Precedence + 1
+		if o.Associativity == RightAssociative {
+			next = o.Precedence
+		}
+		right, err := ap.expression(e, next)
+		if err != nil {
+			// e.g. the trailing operator of "1 +", leave it unconsumed.
+			p.Jump(mark)
+			break
+		}
+		if p.Offset() == mark.Offset() {
+			// An empty operation, the expression would never end.
+			break
+		}
+		left = ap.operation(e, o, left, right)
+		maximum = unbounded
+		if o.Associativity == NonAssociative {
+			maximum = o.Precedence - 1
+		}
+	}
+	return left, nil
+}
+
+// prefix parses an operand, preceded by any prefix operators. Prefix operators
+// can always occur, e.g. in "2 * -3".
+func (ap *Parser) prefix(e Expression) (*Node, error) {
+	p := ap.internal
+	start := p.Mark()
+	p.SkipTrivia()
+	begin := ap.mark()
+	if o, ok := ap.matchOperator(e.Prefix, -unbounded, unbounded); ok && p.Offset() != begin.Offset() {
+		operand, err := ap.expression(e, o.Precedence)
+		if err != nil {
+			p.Jump(start)
+			return nil, err
+		}
+		node := ap.operation(e, o, operand)
+		node.Start = begin
+		return node, nil
+	}
+	return ap.Expect(e.Operand)
+}
+
+// unbounded is the maximum precedence if there is no upper bound.
+const unbounded = int(^uint(0) >> 1)
+
+// matchOperator matches the first of the given operators of which the precedence is
+// within the given bounds.
+func (ap *Parser) matchOperator(operators []Operator, minimum, maximum int) (Operator, bool) {
+	for _, o := range operators {
+		if o.Precedence < minimum || maximum < o.Precedence {
+			continue
+		}
+		if _, err := ap.Expect(o.Value); err == nil {
+			return o, true
+		}
+	}
+	return Operator{}, false
+}
+
+// operation creates a node of the given operator with the given operands.
+func (ap *Parser) operation(e Expression, o Operator, operands ...*Node) *Node {
+	node := ap.newNode(o.Type)
+	node.TypeStrings = e.TypeStrings
+	return operation(node, operands...)
+}
+
+// operation adds the given operands to the given node of an operation. The
+// node spans from the start of the first operand to the end of the last one.
+func operation(node *Node, operands ...*Node) *Node {
+	var start, end *parser.Cursor
+	for _, operand := range operands {
+		if operand == nil {
+			continue
+		}
+		first, last := operand, operand
+		if operand.Type == -1 && operand.Start == nil {
+			// Groups of nodes have no span themselves.
+			first, last = operand.FirstChild, operand.LastChild
+		}
+		if start == nil && first != nil {
+			start = first.Start
+		}
+		if last != nil {
+			end = last.End
+		}
+		if operand.Type == -1 {
+			// e.g. the nodes of a parenthesized expression.
+			node.Adopt(operand)
+		} else {
+			node.SetLast(operand)
+		}
+	}
+	if start != nil {
+		node.Start = start
+	}
+	if end != nil {
+		node.End = end
+	}
+	return node
+}
@@ -0,0 +1,130 @@
+package ast_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+)
+
+const (
+	IntType = iota
+	AddType
+	SubType
+	MulType
+	PowType
+	NegType
+	FacType
+	LessType
+)
+
+var expression ast.Expression
+
+func init() {
+	expression = ast.Expression{
+		TypeStrings: []string{"Int", "Add", "Sub", "Mul", "Pow", "Neg", "Fac", "Less"},
+		Operand: op.Or{
+			ast.Capture{
+				Type:        IntType,
+				TypeStrings: []string{"Int"},
+				Value:       op.MinOne(parser.CheckRuneRange('0', '9')),
+			},
+			op.And{'(', op.Lazy(func() interface{} { return expression }), ')'},
+		},
+		Prefix: []ast.Operator{
+			{Type: NegType, Value: '-', Precedence: 4},
+		},
+		Infix: []ast.Operator{
+			{Type: LessType, Value: '<', Precedence: 0, Associativity: ast.NonAssociative},
+			{Type: AddType, Value: '+', Precedence: 1},
+			{Type: SubType, Value: '-', Precedence: 1},
+			{Type: PowType, Value: "**", Precedence: 3, Associativity: ast.RightAssociative},
+			{Type: MulType, Value: '*', Precedence: 2},
+		},
+		Postfix: []ast.Operator{
+			{Type: FacType, Value: '!', Precedence: 5},
+		},
+	}
+}
+
+func ExampleParser_Expect_expression() {
+	p, _ := ast.New([]byte("-1+2*3"))
+	fmt.Println(p.Expect(expression))
+	// Output:
+	// ["Add",[["Neg",[["Int","1"]]],["Mul",[["Int","2"],["Int","3"]]]]] <nil>
+}
+
+func ExampleExpression_associativity() {
+	for _, input := range []string{"1-2-3", "2**3**4"} {
+		p, _ := ast.New([]byte(input))
+		fmt.Println(p.Expect(expression))
+	}
+	// Output:
+	// ["Sub",[["Sub",[["Int","1"],["Int","2"]]],["Int","3"]]] <nil>
+	// ["Pow",[["Int","2"],["Pow",[["Int","3"],["Int","4"]]]]] <nil>
+}
+
+func ExampleExpression_unary() {
+	for _, input := range []string{"2*-3", "-3!", "(1+2)*3"} {
+		p, _ := ast.New([]byte(input))
+		fmt.Println(p.Expect(expression))
+	}
+	// Output:
+	// ["Mul",[["Int","2"],["Neg",[["Int","3"]]]]] <nil>
+	// ["Neg",[["Fac",[["Int","3"]]]]] <nil>
+	// ["Mul",[["Add",[["Int","1"],["Int","2"]]],["Int","3"]]] <nil>
+}
+
+func ExampleExpression_nonAssociative() {
+	// The second comparison is not part of the expression, neither is an
+	// operator without right operand.
+	for _, input := range []string{"1<2<3", "1+"} {
+		internal, _ := parser.New([]byte(input))
+		p, _ := ast.NewFromParser(internal)
+		fmt.Println(p.Expect(expression))
+		fmt.Println(internal.Remaining())
+	}
+	// Output:
+	// ["Less",[["Int","1"],["Int","2"]]] <nil>
+	// <3
+	// ["Int","1"] <nil>
+	// +
+}
+
+func TestParser_Expect_expression(t *testing.T) {
+	p, _ := ast.New([]byte("-"))
+	if _, err := p.Expect(expression); err == nil {
+		t.Error("expected an error")
+	}
+
+	p, _ = ast.New([]byte("(-1+2)!*3"))
+	node, _ := p.Expect(expression)
+	for n, span := range map[*ast.Node][2]int{
+		node:                                  {1, 9}, // Parentheses are not part of the operand.
+		node.FirstChild:                       {1, 7},
+		node.FirstChild.FirstChild:            {1, 5},
+		node.FirstChild.FirstChild.FirstChild: {1, 3},
+	} {
+		if n.Start.Offset() != span[0] || n.End.Offset() != span[1] {
+			t.Errorf("%s: expected span %v, got [%d %d]", n, span, n.Start.Offset(), n.End.Offset())
+		}
+	}
+}
+
+func TestParser_Expect_expressionNoProgress(t *testing.T) {
+	// All operators can match nothing.
+	empty := op.Optional('~')
+	e := ast.Expression{
+		TypeStrings: []string{"Int", "Op"},
+		Operand:     ast.Capture{Type: 0, TypeStrings: []string{"Int"}, Value: op.MinZero(parser.CheckRuneRange('0', '9'))},
+		Prefix:      []ast.Operator{{Type: 1, Value: empty, Precedence: 1}},
+		Infix:       []ast.Operator{{Type: 1, Value: empty, Precedence: 1}},
+		Postfix:     []ast.Operator{{Type: 1, Value: empty, Precedence: 1}},
+	}
+	p, _ := ast.New([]byte("1x"))
+	if node, err := p.Expect(e); err != nil || node.String() != `["Int","1"]` {
+		t.Error(node, err)
+	}
+}
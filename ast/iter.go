@@ -0,0 +1,64 @@
+//go:build go1.23
+// +build go1.23
+
+package ast
+
+import "iter"
+
+// ChildSeq returns an iterator over the children of the node. Unlike Children,
+// it does not allocate a slice. The current child can be removed or replaced
+// while iterating.
+func (n *Node) ChildSeq() iter.Seq[*Node] {
+	return func(yield func(*Node) bool) {
+		for c := n.FirstChild; c != nil; {
+			next := c.NextSibling
+			if !yield(c) {
+				return
+			}
+			c = next
+		}
+	}
+}
+
+// DescendantsPreOrder returns an iterator over all the descendants of the node,
+// not including the node itself. Every node is yielded before its children.
+func (n *Node) DescendantsPreOrder() iter.Seq[*Node] {
+	return func(yield func(*Node) bool) {
+		preOrder(n, yield)
+	}
+}
+
+// preOrder yields the descendants of the given node. Returns false if the
+// iteration was stopped.
+func preOrder(n *Node, yield func(*Node) bool) bool {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if !yield(c) || !preOrder(c, yield) {
+			return false
+		}
+		c = next
+	}
+	return true
+}
+
+// DescendantsPostOrder returns an iterator over all the descendants of the
+// node, not including the node itself. Every node is yielded after its
+// children.
+func (n *Node) DescendantsPostOrder() iter.Seq[*Node] {
+	return func(yield func(*Node) bool) {
+		postOrder(n, yield)
+	}
+}
+
+// postOrder yields the descendants of the given node. Returns false if the
+// iteration was stopped.
+func postOrder(n *Node, yield func(*Node) bool) bool {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if !postOrder(c, yield) || !yield(c) {
+			return false
+		}
+		c = next
+	}
+	return true
+}
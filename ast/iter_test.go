@@ -0,0 +1,58 @@
+//go:build go1.23
+// +build go1.23
+
+package ast_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func ExampleNode_DescendantsPreOrder() {
+	r := tree("r", tree("a", tree("b")), tree("c"))
+	for n := range r.DescendantsPreOrder() {
+		fmt.Print(n.Value, " ")
+	}
+	// Output:
+	// a b c
+}
+
+func TestNode_ChildSeq(t *testing.T) {
+	r := tree("r", tree("a"), tree("x"), tree("b"), tree("x"))
+	var values []string
+	for c := range r.ChildSeq() {
+		values = append(values, c.Value)
+		if c.Value == "x" {
+			c.Remove()
+		}
+	}
+	if s := strings.Join(values, " "); s != "a x b x" {
+		t.Error(s)
+	}
+	if s := sexpr(t, r); s != "(r a b)" {
+		t.Error(s)
+	}
+}
+
+func TestNode_DescendantsPostOrder(t *testing.T) {
+	r := tree("r", tree("a", tree("b"), tree("c")), tree("d", tree("e")))
+	var values []string
+	for n := range r.DescendantsPostOrder() {
+		values = append(values, n.Value)
+	}
+	if s := strings.Join(values, " "); s != "b c a e d" {
+		t.Error(s)
+	}
+
+	values = nil
+	for n := range r.DescendantsPreOrder() {
+		if n.Value == "c" {
+			break
+		}
+		values = append(values, n.Value)
+	}
+	if s := strings.Join(values, " "); s != "a b" {
+		t.Error(s)
+	}
+}
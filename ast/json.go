@@ -1,8 +1,13 @@
 package ast
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
+
+	"github.com/di-wu/parser"
 )
 
 func (n Node) String() string {
@@ -20,11 +25,49 @@ func (n Node) String() string {
 	return jsonString
 }
 
+// nodeJSON is the JSON representation of a node, see Node.MarshalJSON.
+type nodeJSON struct {
+	Type     int            `json:"type"`
+	Name     string         `json:"name,omitempty"`
+	Value    string         `json:"value,omitempty"`
+	Error    string         `json:"error,omitempty"`
+	Start    *parser.Cursor `json:"start,omitempty"`
+	End      *parser.Cursor `json:"end,omitempty"`
+	Children []*nodeJSON    `json:"children,omitempty"`
+}
+
+// MarshalJSON encodes the node and its children as a JSON object, e.g.
+//
+//	{"type":0,"name":"Int","value":"1","start":{"offset":0,"row":0,"column":0},...}
+//
+// Names, errors and positions are omitted if the node has none. See
+// MarshalJSONString for a more compact representation.
 func (n *Node) MarshalJSON() ([]byte, error) {
-	jsonString, err := n.MarshalJSONString()
-	return []byte(jsonString), err
+	return json.Marshal(n.json())
 }
 
+// json converts the node and its children to their JSON representation.
+func (n *Node) json() *nodeJSON {
+	v := &nodeJSON{
+		Type:  n.Type,
+		Value: n.Value,
+		Start: n.Start,
+		End:   n.End,
+	}
+	if name, ok := TypeName(n.Type, n.TypeStrings); ok {
+		v.Name = name
+	}
+	if n.Error != nil {
+		v.Error = n.Error.Error()
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		v.Children = append(v.Children, c.json())
+	}
+	return v
+}
+
+// MarshalJSONString encodes the types and values of the node and its children
+// as nested arrays, e.g. [-1,[[0,"a"],[1,"b"]]].
 func (n *Node) MarshalJSONString() (string, error) {
 	if !n.IsParent() {
 		return fmt.Sprintf("[%d,\"%v\"]", n.Type, escape(n.Value)), nil
@@ -44,19 +87,68 @@ func (n *Node) MarshalJSONString() (string, error) {
 	return jsonString, nil
 }
 
+// UnmarshalJSON decodes the output of both MarshalJSON and MarshalJSONString.
+// The type strings of the node are passed on to its children. If the node has
+// no type strings, they are rebuilt from the encoded names.
 func (n *Node) UnmarshalJSON(bytes []byte) error {
-	p, err := New(bytes)
-	if err != nil {
-		return err
+	if trimmed := strings.TrimSpace(string(bytes)); strings.HasPrefix(trimmed, "[") {
+		p, err := New([]byte(trimmed))
+		if err != nil {
+			return err
+		}
+		astNode, err := node(p)
+		if err != nil {
+			return err
+		}
+		*n = parseNode(astNode, n.TypeStrings)
+		return nil
 	}
-	astNode, err := node(p)
-	if err != nil {
+
+	var v nodeJSON
+	if err := json.Unmarshal(bytes, &v); err != nil {
 		return err
 	}
-	*n = parseNode(astNode, n.TypeStrings)
+	typeStrings := n.TypeStrings
+	if len(typeStrings) == 0 {
+		typeStrings = v.typeStrings(nil)
+	}
+	*n = *v.node(typeStrings)
 	return nil
 }
 
+// typeStrings adds the names of the node and its children to the given type
+// strings. Types without name are "UNKNOWN".
+func (v *nodeJSON) typeStrings(typeStrings []string) []string {
+	if v.Name != "" && 0 <= v.Type {
+		for len(typeStrings) <= v.Type {
+			typeStrings = append(typeStrings, "UNKNOWN")
+		}
+		typeStrings[v.Type] = v.Name
+	}
+	for _, c := range v.Children {
+		typeStrings = c.typeStrings(typeStrings)
+	}
+	return typeStrings
+}
+
+// node converts the JSON representation back to a node.
+func (v *nodeJSON) node(typeStrings []string) *Node {
+	n := &Node{
+		Type:        v.Type,
+		TypeStrings: typeStrings,
+		Value:       v.Value,
+		Start:       v.Start,
+		End:         v.End,
+	}
+	if v.Error != "" {
+		n.Error = errors.New(v.Error)
+	}
+	for _, c := range v.Children {
+		n.SetLast(c.node(typeStrings))
+	}
+	return n
+}
+
 func parseNode(n *Node, typeStrings []string) Node {
 	var (
 		node     = Node{TypeStrings: typeStrings}
@@ -70,7 +162,7 @@ func parseNode(n *Node, typeStrings []string) Node {
 		}
 	} else {
 		value := child.Value[1 : len(child.Value)-1]
-		node.Value = value
+		node.Value = unescape(value)
 	}
 	return node
 }
@@ -125,6 +217,9 @@ func unescape(s string) string {
 			case '\\':
 				unescaped += "\\"
 			}
+			// The escaped rune does not start another escape sequence.
+			previous = 0
+			continue
 		} else if v != '\\' {
 			unescaped += string(v)
 		}
@@ -1,7 +1,12 @@
 package ast
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"testing"
+
+	"github.com/di-wu/parser"
 	"github.com/di-wu/parser/op"
 )
 
@@ -30,5 +35,77 @@ func ExampleNode_UnmarshalJSON() {
 	_ = node.UnmarshalJSON([]byte("[-1,[[0,\"a\"],[0,\"a\"],[0,\"a\"],[0,\"a\"],[0,\"a\"],[1,\"\\n\"]]]"))
 	fmt.Println(node.MarshalJSONString())
 	// Output:
-	// [-1,[[0,"a"],[0,"a"],[0,"a"],[0,"a"],[0,"a"],[1,"\\n"]]] <nil>
+	// [-1,[[0,"a"],[0,"a"],[0,"a"],[0,"a"],[0,"a"],[1,"\n"]]] <nil>
+}
+
+func ExampleNode_MarshalJSON() {
+	p, _ := New([]byte("ab"))
+	n, _ := p.Expect(Capture{
+		Type:        1,
+		TypeStrings: []string{"Letter", "Letters"},
+		Value: op.MinOne(Capture{
+			Type:        0,
+			TypeStrings: []string{"Letter", "Letters"},
+			Value:       parser.CheckRuneRange('a', 'z'),
+		}),
+	})
+	data, _ := json.Marshal(n)
+	fmt.Println(string(data))
+	// Output:
+	// {"type":1,"name":"Letters","start":{"offset":0,"row":0,"column":0},"end":{"offset":2,"row":0,"column":2},"children":[{"type":0,"name":"Letter","value":"a","start":{"offset":0,"row":0,"column":0},"end":{"offset":1,"row":0,"column":1}},{"type":0,"name":"Letter","value":"b","start":{"offset":1,"row":0,"column":1},"end":{"offset":2,"row":0,"column":2}}]}
+}
+
+func TestNode_UnmarshalJSON(t *testing.T) {
+	p, _ := New([]byte("a\nb\\\"c"))
+	letter := Capture{
+		Type:        2,
+		TypeStrings: []string{"", "", "Letter", "Letters"},
+		Value:       op.Or{parser.CheckRuneRange('a', 'z'), '"', '\\'},
+	}
+	n, err := p.Expect(Capture{
+		Type:        3,
+		TypeStrings: letter.TypeStrings,
+		Value:       op.And{letter, '\n', op.MinOne(letter)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.LastChild.Error = errors.New("some error")
+	data, err := json.Marshal(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded Node
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !Equal(n, &decoded) || n.String() != decoded.String() {
+		t.Errorf("expected %s, got %s", n, decoded)
+	}
+	expected, actual := nodes(n), nodes(&decoded)
+	for i, x := range expected {
+		y := actual[i]
+		if x.Start.Offset() != y.Start.Offset() || x.End.Offset() != y.End.Offset() {
+			t.Errorf("%s: expected span [%d %d], got [%d %d]", x, x.Start.Offset(), x.End.Offset(), y.Start.Offset(), y.End.Offset())
+		}
+		if fmt.Sprint(x.Start.Position()) != fmt.Sprint(y.Start.Position()) {
+			t.Errorf("%s: expected position %v, got %v", x, fmt.Sprint(x.Start.Position()), fmt.Sprint(y.Start.Position()))
+		}
+		if x.Error != nil && (y.Error == nil || x.Error.Error() != y.Error.Error()) {
+			t.Errorf("expected error %v, got %v", x.Error, y.Error)
+		}
+	}
+}
+
+// nodes returns the node and all its descendants in depth-first order.
+func nodes(n *Node) []*Node {
+	var ns []*Node
+	Inspect(n, func(n *Node) bool {
+		if n != nil {
+			ns = append(ns, n)
+		}
+		return true
+	})
+	return ns
 }
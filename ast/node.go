@@ -1,8 +1,14 @@
 package ast
 
+import "github.com/di-wu/parser"
+
 // ParseNode represents a function to parse ast nodes.
 type ParseNode func(p *Parser) (*Node, error)
 
+// ErrorType is the type of the nodes that contain an error that was recovered
+// from, see op.Recover. The value of an error node is the skipped input.
+const ErrorType = -2
+
 // Node is a simple node in a tree with double linked lists instead of slices to
 // keep track of its siblings and children. A node is either a value or a
 // parent node.
@@ -13,6 +19,19 @@ type Node struct {
 	TypeStrings []string
 	// Value of the node. Only possible if it has no children.
 	Value string
+	// Error that was recovered from. Only set for nodes of the ErrorType.
+	Error error
+	// Data is the value that the Convert function of the capture returned.
+	Data interface{}
+	// Start is a mark to the first rune of the node, End a mark to the position
+	// directly after the node. Both are nil if the node was not captured from
+	// the input.
+	Start, End *parser.Cursor
+	// Leading is the text before the node that is not part of another node,
+	// e.g. whitespace, comments and punctuation. Trailing is the same for the
+	// text after the last child of the node. Both are only set by parsers in
+	// concrete mode, see Parser.SetConcrete.
+	Leading, Trailing string
 
 	// Parent is the parent node.
 	Parent *Node
@@ -24,13 +43,40 @@ type Node struct {
 	FirstChild *Node
 	// LastChild is the last child of the node.
 	LastChild *Node
+
+	// attributes contains the annotations of the node, see SetAttr. Only
+	// allocated once an attribute is set.
+	attributes map[string]interface{}
+}
+
+// SetAttr annotates the node with the given attribute, e.g. the type or scope
+// that was resolved by a later pass. Overwrites the previous value.
+func (n *Node) SetAttr(key string, value interface{}) {
+	if n.attributes == nil {
+		n.attributes = make(map[string]interface{})
+	}
+	n.attributes[key] = value
+}
+
+// GetAttr returns the value of the given attribute and whether it is set.
+func (n *Node) GetAttr(key string) (interface{}, bool) {
+	value, ok := n.attributes[key]
+	return value, ok
+}
+
+// DeleteAttr removes the given attribute from the node.
+func (n *Node) DeleteAttr(key string) {
+	delete(n.attributes, key)
 }
 
 // TypeString returns the strings representation of the type. Same as TypeStrings[Type]. Returns "UNKNOWN" if not
-// string representation is found or len(TypeStrings) == 0.
+// string representation is found or len(TypeStrings) == 0. Returns "ERROR" for error nodes.
 func (n *Node) TypeString() string {
-	if 0 <= n.Type && n.Type < len(n.TypeStrings) {
-		return n.TypeStrings[n.Type]
+	if n.Type == ErrorType {
+		return "ERROR"
+	}
+	if name, ok := TypeName(n.Type, n.TypeStrings); ok {
+		return name
 	}
 	return "UNKNOWN"
 }
@@ -52,7 +98,54 @@ func (n *Node) Children() []*Node {
 	return cs
 }
 
-// Remove removes itself from the tree.
+// Clone returns a deep copy of the node and its children, without parent or
+// siblings.
+func (n *Node) Clone() *Node {
+	if n == nil {
+		return nil
+	}
+	c := &Node{
+		Type:        n.Type,
+		TypeStrings: n.TypeStrings,
+		Value:       n.Value,
+		Error:       n.Error,
+		Data:        n.Data,
+		Start:       n.Start,
+		End:         n.End,
+		Leading:     n.Leading,
+		Trailing:    n.Trailing,
+	}
+	for key, value := range n.attributes {
+		c.SetAttr(key, value)
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		c.SetLast(child.Clone())
+	}
+	return c
+}
+
+// Equal returns whether both trees have the same structure, i.e. whether the
+// nodes and their children have the same types and values. The spans, errors,
+// attributes and the parents of the given nodes are not compared.
+func Equal(a, b *Node) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Type != b.Type || a.Value != b.Value {
+		return false
+	}
+	x, y := a.FirstChild, b.FirstChild
+	for ; x != nil && y != nil; x, y = x.NextSibling, y.NextSibling {
+		if !Equal(x, y) {
+			return false
+		}
+	}
+	return x == nil && y == nil
+}
+
+// Remove removes itself from the tree, its children stay attached to it. The
+// first and last child of the parent are updated if needed. Returns the node
+// itself.
 func (n *Node) Remove() *Node {
 	if n.Parent != nil {
 		// Set the first child to the next.
@@ -79,6 +172,50 @@ func (n *Node) Remove() *Node {
 	return n
 }
 
+// Detach removes itself from the tree, its children take its place in the
+// tree. e.g. detaching (b) from (a (b (c d)) e) results in (a c d e). Returns
+// the node itself, without children.
+func (n *Node) Detach() *Node {
+	for n.FirstChild != nil {
+		n.SetPrevious(n.FirstChild.Remove())
+	}
+	return n.Remove()
+}
+
+// Replace replaces itself with the given node, which takes over its parent and
+// siblings. The node itself is removed from the tree and returned, its children
+// stay attached to it.
+func (n *Node) Replace(other *Node) *Node {
+	if other == n {
+		return n
+	}
+	other.Remove()
+	switch {
+	case n.PreviousSibling != nil:
+		n.PreviousSibling.SetNext(other)
+	case n.NextSibling != nil:
+		n.NextSibling.SetPrevious(other)
+	case n.Parent != nil:
+		n.Parent.SetFirst(other)
+	}
+	return n.Remove()
+}
+
+// ReplaceChildren replaces all the children of the node with the given nodes.
+// Returns the previous children, which are removed from the tree.
+func (n *Node) ReplaceChildren(children ...*Node) []*Node {
+	previous := n.Children()
+	for _, c := range previous {
+		c.Remove()
+	}
+	for _, c := range children {
+		n.SetLast(c)
+	}
+	return previous
+}
+
+// Adopt moves all the children of the other node to the end of the children of
+// the node.
 func (n *Node) Adopt(other *Node) {
 	if other.FirstChild == nil {
 		// Nothing to adapt.
@@ -92,16 +229,18 @@ func (n *Node) Adopt(other *Node) {
 func (n *Node) SetPrevious(sibling *Node) {
 	sibling.Remove()
 	sibling.Parent = n.Parent
+	// (a) <-> (b) | b.SetPrevious(c)
+	// (a) <-> (c) <-> (b)
 	if n.PreviousSibling != nil {
 		// Already has a sibling.
-		// 1. Update next of previous node.
-		// 2. Assign sibling as previous.
-		// 3. Copy over previous of node.
-		// 4. Add node as next.
-		n.PreviousSibling.NextSibling = sibling     // (1)
-		n.PreviousSibling = sibling                 // (2)
-		sibling.PreviousSibling = n.PreviousSibling // (3)
-		sibling.NextSibling = n                     // (4)
+		// 1. Copy over previous of node.
+		// 2. Update next of previous node.
+		// 3. Reference each other.
+		sibling.PreviousSibling = n.PreviousSibling // (1)
+		n.PreviousSibling.NextSibling = sibling     // (2)
+		sibling.NextSibling = n                     // (3)
+		n.PreviousSibling = sibling
+		return
 	}
 	// Does not have a previous sibling yet.
 	// 1. Reference each other.
@@ -117,18 +256,17 @@ func (n *Node) SetPrevious(sibling *Node) {
 func (n *Node) SetNext(sibling *Node) {
 	sibling.Remove()
 	sibling.Parent = n.Parent
-	// (a) <-> (b) | a.AddSibling(b)
+	// (a) <-> (b) | a.SetNext(c)
 	// (a) <-> (c) <-> (b)
 	if n.NextSibling != nil {
 		// Already has a sibling.
-		// 1. Update previous of next node.
-		// 2. Assign sibling as next.
-		// 3. Copy over next of node.
-		// 4. Add node as previous.
-		n.NextSibling.PreviousSibling = sibling // (1)
-		n.NextSibling = sibling                 // (2)
-		sibling.NextSibling = n.NextSibling     // (3)
-		sibling.PreviousSibling = n             // (4)
+		// 1. Copy over next of node.
+		// 2. Update previous of next node.
+		// 3. Reference each other.
+		sibling.NextSibling = n.NextSibling     // (1)
+		n.NextSibling.PreviousSibling = sibling // (2)
+		sibling.PreviousSibling = n             // (3)
+		n.NextSibling = sibling
 		return
 	}
 	// Does not have a next sibling yet.
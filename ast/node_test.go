@@ -0,0 +1,196 @@
+package ast_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/di-wu/parser/ast"
+)
+
+// tree returns a node with the given value and children.
+func tree(value string, children ...*ast.Node) *ast.Node {
+	n := &ast.Node{Value: value}
+	for _, c := range children {
+		n.SetLast(c)
+	}
+	return n
+}
+
+// sexpr returns the values of the node and its children, e.g. "(a b c)". It
+// also checks whether all the references are consistent.
+func sexpr(t *testing.T, n *ast.Node) string {
+	if !n.IsParent() {
+		return n.Value
+	}
+	var values []string
+	var previous *ast.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Parent != n || c.PreviousSibling != previous {
+			t.Errorf("%s: inconsistent references of %s", n.Value, c.Value)
+		}
+		values = append(values, sexpr(t, c))
+		previous = c
+	}
+	if n.LastChild != previous {
+		t.Errorf("%s: last child is %v, expected %s", n.Value, n.LastChild, previous.Value)
+	}
+	return fmt.Sprintf("(%s %s)", n.Value, strings.Join(values, " "))
+}
+
+func TestNode_Remove(t *testing.T) {
+	for i, expected := range []string{"(r b c)", "(r a c)", "(r a b)"} {
+		r := tree("r", tree("a"), tree("b"), tree("c"))
+		removed := r.Children()[i].Remove()
+		if removed.Parent != nil || removed.PreviousSibling != nil || removed.NextSibling != nil {
+			t.Errorf("%s: still references the tree", removed.Value)
+		}
+		if s := sexpr(t, r); s != expected {
+			t.Errorf("expected %s, got %s", expected, s)
+		}
+	}
+
+	r := tree("r", tree("a"))
+	r.FirstChild.Remove()
+	if r.FirstChild != nil || r.LastChild != nil {
+		t.Error("expected no children")
+	}
+}
+
+func TestNode_Detach(t *testing.T) {
+	r := tree("r", tree("a"), tree("b", tree("c"), tree("d")), tree("e"))
+	b := r.Children()[1].Detach()
+	if s := sexpr(t, r); s != "(r a c d e)" {
+		t.Error(s)
+	}
+	if b.IsParent() || b.Parent != nil {
+		t.Error("expected a detached node")
+	}
+
+	r = tree("r", tree("b", tree("c")))
+	r.FirstChild.Detach()
+	if s := sexpr(t, r); s != "(r c)" {
+		t.Error(s)
+	}
+}
+
+func TestNode_SetPrevious(t *testing.T) {
+	r := tree("r", tree("a"), tree("c"))
+	r.LastChild.SetPrevious(tree("b"))
+	r.FirstChild.SetPrevious(tree("0"))
+	if s := sexpr(t, r); s != "(r 0 a b c)" {
+		t.Error(s)
+	}
+}
+
+func TestNode_SetNext(t *testing.T) {
+	r := tree("r", tree("a"), tree("c"))
+	r.FirstChild.SetNext(tree("b"))
+	r.LastChild.SetNext(tree("d"))
+	if s := sexpr(t, r); s != "(r a b c d)" {
+		t.Error(s)
+	}
+}
+
+func TestNode_Replace(t *testing.T) {
+	for i, expected := range []string{"(r x (b d) c)", "(r a x c)", "(r a (b d) x)"} {
+		r := tree("r", tree("a"), tree("b", tree("d")), tree("c"))
+		replaced := r.Children()[i].Replace(tree("x"))
+		if replaced.Parent != nil || replaced.PreviousSibling != nil || replaced.NextSibling != nil {
+			t.Errorf("%s: still references the tree", replaced.Value)
+		}
+		if s := sexpr(t, r); s != expected {
+			t.Errorf("expected %s, got %s", expected, s)
+		}
+	}
+
+	// Replace a node by one of its own children, e.g. to unwrap it.
+	r := tree("r", tree("a", tree("b")))
+	r.FirstChild.Replace(r.FirstChild.FirstChild)
+	if s := sexpr(t, r); s != "(r b)" {
+		t.Error(s)
+	}
+}
+
+func TestNode_ReplaceChildren(t *testing.T) {
+	r := tree("r", tree("a"), tree("b"))
+	previous := r.ReplaceChildren(tree("c"), r.LastChild)
+	if s := sexpr(t, r); s != "(r c b)" {
+		t.Error(s)
+	}
+	if len(previous) != 2 || previous[0].Parent != nil {
+		t.Error(previous)
+	}
+}
+
+func TestNode_Clone(t *testing.T) {
+	r := tree("r", tree("a", tree("b")), tree("c"))
+	c := r.FirstChild.Clone()
+	if c.Parent != nil || c.NextSibling != nil {
+		t.Error("clone still references the tree")
+	}
+	if s := sexpr(t, c); s != "(a b)" {
+		t.Error(s)
+	}
+	if c.FirstChild == r.FirstChild.FirstChild {
+		t.Error("children are not copied")
+	}
+	if !ast.Equal(r.FirstChild, c) {
+		t.Error("expected clone to be equal")
+	}
+}
+
+func TestEqual(t *testing.T) {
+	for _, test := range []struct {
+		a, b  *ast.Node
+		equal bool
+	}{
+		{a: nil, b: nil, equal: true},
+		{a: tree("a"), b: nil},
+		{a: tree("a"), b: tree("a"), equal: true},
+		{a: tree("a"), b: tree("b")},
+		{a: tree("r", tree("a")), b: tree("r", tree("a")), equal: true},
+		{a: tree("r", tree("a")), b: tree("r", tree("a"), tree("b"))},
+		{a: tree("r", tree("a", tree("b"))), b: tree("r", tree("a"), tree("b"))},
+		{a: &ast.Node{Type: 1}, b: &ast.Node{Type: 2}},
+	} {
+		if ast.Equal(test.a, test.b) != test.equal || ast.Equal(test.b, test.a) != test.equal {
+			t.Errorf("%v, %v: expected equal to be %v", test.a, test.b, test.equal)
+		}
+	}
+
+	// Only the structure below the nodes is compared.
+	r := tree("r", tree("a"), tree("a"))
+	if !ast.Equal(r.FirstChild, r.LastChild) {
+		t.Error("expected siblings to be equal")
+	}
+}
+
+func ExampleNode_SetAttr() {
+	n := &ast.Node{Value: "x"}
+	n.SetAttr("type", "int")
+	fmt.Println(n.GetAttr("type"))
+	n.DeleteAttr("type")
+	fmt.Println(n.GetAttr("type"))
+	// Output:
+	// int true
+	// <nil> false
+}
+
+func TestNode_SetAttr(t *testing.T) {
+	var n ast.Node
+	if _, ok := n.GetAttr("a"); ok {
+		t.Error("expected no attributes")
+	}
+	n.DeleteAttr("a")
+
+	n.SetAttr("a", 1)
+	c := n.Clone()
+	c.SetAttr("a", 2)
+	if v, _ := n.GetAttr("a"); v != 1 {
+		t.Errorf("expected the attributes of the clone to be a copy, got %v", v)
+	}
+	if v, _ := c.GetAttr("a"); v != 2 {
+		t.Errorf("expected 2, got %v", v)
+	}
+}
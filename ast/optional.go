@@ -0,0 +1,10 @@
+package ast
+
+// Optional represents an optional value that never fails. If the value is
+// absent, a copy of the Default node is returned instead, e.g. a node that
+// represents an omitted initializer. Without a Default node it behaves the same
+// as op.Optional.
+type Optional struct {
+	Value   interface{}
+	Default *Node
+}
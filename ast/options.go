@@ -0,0 +1,53 @@
+package ast
+
+import (
+	"fmt"
+
+	"github.com/di-wu/parser"
+)
+
+// Option configures a Parser, see New.
+type Option func(p *Parser)
+
+// WithSkipEmptyCaptures drops the nodes of captures that matched the empty
+// string, e.g. of optional values. By default, they result in nodes with an
+// empty value.
+func WithSkipEmptyCaptures() Option {
+	return func(p *Parser) {
+		p.skipEmpty = true
+	}
+}
+
+// WithMaxNodes limits the number of nodes that the parser creates, including
+// the nodes of values that are discarded later on (e.g. failed alternatives).
+// If the limit is exceeded, the parser stops and returns a NodeLimitError.
+// This protects against (e.g. attacker controlled) input that results in huge
+// trees. Values less than 1 disable the limit.
+func WithMaxNodes(n int) Option {
+	return func(p *Parser) {
+		p.maxNodes = n
+	}
+}
+
+// WithNestedGroups keeps the nodes of a nested op.And together in a group node
+// (of type -1), instead of merging them into the nodes of the enclosing op.And.
+// e.g. And{a, And{b, c}} results in [a, [b, c]] instead of [a, b, c].
+func WithNestedGroups() Option {
+	return func(p *Parser) {
+		p.nested = true
+	}
+}
+
+// NodeLimitError indicates that the maximum number of nodes is exceeded. See
+// WithMaxNodes.
+type NodeLimitError struct {
+	// The maximum number of nodes.
+	Max int
+	// The position at which the maximum got exceeded.
+	Cursor parser.Cursor
+}
+
+func (e *NodeLimitError) Error() string {
+	row, column := e.Cursor.Position()
+	return fmt.Sprintf("ast [%02d:%03d]: maximum of %d nodes exceeded", row, column, e.Max)
+}
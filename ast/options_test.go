@@ -0,0 +1,69 @@
+package ast_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+)
+
+func ExampleWithSkipEmptyCaptures() {
+	digits := ast.Capture{
+		TypeStrings: []string{"Digits"},
+		Value:       op.MinZero(parser.CheckRuneRange('0', '9')),
+	}
+
+	p, _ := ast.New([]byte("a"))
+	fmt.Println(p.Expect(digits, 'a'))
+	p, _ = ast.New([]byte("a"), ast.WithSkipEmptyCaptures())
+	fmt.Println(p.Expect(digits, 'a'))
+	// Output:
+	// ["UNKNOWN",[["Digits",""]]] <nil>
+	// <nil> <nil>
+}
+
+func ExampleWithNestedGroups() {
+	letter := ast.Capture{
+		TypeStrings: []string{"Letter"},
+		Value:       parser.CheckRuneRange('a', 'z'),
+	}
+	word := op.And{letter, op.And{letter, letter}}
+
+	p, _ := ast.New([]byte("abc"))
+	fmt.Println(p.Expect(word))
+	p, _ = ast.New([]byte("abc"), ast.WithNestedGroups())
+	fmt.Println(p.Expect(word))
+	// Output:
+	// ["UNKNOWN",[["Letter","a"],["Letter","b"],["Letter","c"]]] <nil>
+	// ["UNKNOWN",[["Letter","a"],["UNKNOWN",[["Letter","b"],["Letter","c"]]]]] <nil>
+}
+
+func TestWithMaxNodes(t *testing.T) {
+	input := []byte(strings.Repeat("1+", 10) + "1")
+	p, _ := ast.New(input, ast.WithMaxNodes(21))
+	if _, err := p.Expect(expression); err != nil {
+		t.Fatal(err)
+	}
+
+	p, _ = ast.New(input, ast.WithMaxNodes(20))
+	_, err := p.Expect(op.Or{expression, 'x'})
+	var limit *ast.NodeLimitError
+	if !errors.As(err, &limit) || limit.Max != 20 {
+		t.Fatalf("expected a node limit error, got %v", err)
+	}
+	if s := err.Error(); s != "ast [00:021]: maximum of 20 nodes exceeded" {
+		t.Error(s)
+	}
+
+	// The limit applies until the parser is reset.
+	if err := p.Reset([]byte("1")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Expect(expression); err != nil {
+		t.Error(err)
+	}
+}
@@ -1,6 +1,9 @@
 package ast
 
 import (
+	"errors"
+	"unicode/utf8"
+
 	"github.com/di-wu/parser"
 	"github.com/di-wu/parser/op"
 )
@@ -17,18 +20,43 @@ func Parse(data []byte, node ParseNode) (*Node, error) {
 // Parser represents a general purpose AST parser.
 type Parser struct {
 	internal *parser.Parser
+	// extension is the hook of the internal parser, run is the match function
+	// that is passed to it.
+	extension parser.Extension
+	run       func(i interface{}) (interface{}, error)
 
 	converter func(interface{}) interface{}
 	operator  func(interface{}) (*Node, error)
+	context   interface{}
+
+	concrete bool
+	collapse bool
+	arena    *Arena
+
+	skipEmpty bool
+	nested    bool
+	// types contains the names of the node types, see WithTypes.
+	types    []string
+	maxNodes int
+	// nodes is the number of created nodes, see WithMaxNodes.
+	nodes int
+	// depth is the number of nested calls to Expect.
+	depth int
 }
 
 // New creates a new Parser.
-func New(input []byte) (*Parser, error) {
+func New(input []byte, options ...Option) (*Parser, error) {
 	internal, err := parser.New(input)
 	if err != nil {
 		return nil, err
 	}
-	return NewFromParser(internal)
+	return NewFromParser(internal, options...)
+}
+
+// Reset resets the parser to the start of the given input. See parser.Reset.
+func (ap *Parser) Reset(input []byte) error {
+	ap.nodes = 0
+	return ap.internal.Reset(input)
 }
 
 // SetConverter allows you to add additional (prioritized) converters to the
@@ -43,21 +71,88 @@ func (ap *Parser) SetOperator(o func(i interface{}) (*Node, error)) {
 	ap.operator = o
 }
 
+// SetCollapse sets whether all captures collapse, see Capture.Collapse.
+func (ap *Parser) SetCollapse(collapse bool) {
+	ap.collapse = collapse
+}
+
+// SetContext sets the user context that is passed to the hooks of captures,
+// e.g. a symbol table. See Capture.OnEnter and Capture.OnExit.
+func (ap *Parser) SetContext(context interface{}) {
+	ap.context = context
+}
+
+// Context returns the user context of the parser.
+func (ap *Parser) Context() interface{} {
+	return ap.context
+}
+
 // NewFromParser creates a new Parser from a parser.Parser. This allows you to
 // customize the internal parser. If no customization is needed, use New.
-func NewFromParser(p *parser.Parser) (*Parser, error) {
-	return &Parser{
-		internal: p,
-	}, nil
+func NewFromParser(p *parser.Parser, options ...Option) (*Parser, error) {
+	ap := &Parser{
+		internal:  p,
+		extension: p.Extension(),
+	}
+	ap.run = ap.expect
+	for _, option := range options {
+		option(ap)
+	}
+	return ap, nil
 }
 
-// Expect checks whether the buffer contains the given value.
-func (ap *Parser) Expect(i interface{}) (*Node, error) {
+// Expect checks whether the buffer contains the given value. The attached
+// states of the internal parser are restored on failure.
+//
+// Multiple values are expected in sequence, the same as passing an op.And.
+func (ap *Parser) Expect(i interface{}, is ...interface{}) (*Node, error) {
+	if len(is) != 0 {
+		i = append(op.And{i}, is...)
+	}
 	i = ConvertAliases(i)
 	if ap.converter != nil {
 		i = ap.converter(i)
 	}
 
+	var start *parser.Cursor
+	if ap.concrete && ap.depth == 0 {
+		start = ap.internal.Mark()
+	}
+	ap.depth++
+	value, err := ap.extension.Run(i, ap.run)
+	ap.depth--
+	if err != nil {
+		return nil, err
+	}
+	node, _ := value.(*Node)
+	if _, ok := i.(*op.Memo); ok {
+		// Memoized nodes are shared, while the returned nodes get adopted by
+		// their parents.
+		node = node.Clone()
+	}
+	if start != nil && node != nil {
+		ap.attachTrivia(node, start)
+	}
+	return node, nil
+}
+
+// expect matches the given (converted) value, see parser.Extension.Run.
+func (ap *Parser) expect(i interface{}) (interface{}, error) {
+	node, err := ap.operate(i)
+	if err == nil && 0 < ap.maxNodes && ap.maxNodes < ap.nodes {
+		err = ap.extension.Stop(&NodeLimitError{
+			Max:    ap.maxNodes,
+			Cursor: ap.internal.MarkV(),
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// operate matches the given value with the operator of the parser, if any.
+func (ap *Parser) operate(i interface{}) (*Node, error) {
 	p := ap.internal
 	start := p.Mark()
 	if ap.operator != nil {
@@ -73,8 +168,15 @@ func (ap *Parser) Expect(i interface{}) (*Node, error) {
 			return node, err
 		}
 	}
+	return ap.match(i)
+}
+
+// match matches the given (converted) value.
+func (ap *Parser) match(i interface{}) (*Node, error) {
+	p := ap.internal
+	start := p.Mark()
 	switch v := i.(type) {
-	case rune, string, parser.AnonymousClass:
+	case rune, string, *op.Trie, parser.AnonymousClass, op.Backref, op.Anchor, op.Keyword, *parser.Compiled:
 		// Just check if it matches.
 		if _, err := p.Expect(v); err != nil {
 			return nil, err
@@ -89,27 +191,19 @@ func (ap *Parser) Expect(i interface{}) (*Node, error) {
 		return node, nil
 
 	case Capture:
-		node, err := ap.Expect(v.Value)
+		node, err := ap.capture(v)
 		if err != nil {
 			p.Jump(start)
 			return nil, err
 		}
-		if node != nil {
-			// Return the node.
-			if node.Type == -1 {
-				node.Type = v.Type
-			}
-			if len(node.TypeStrings) == 0 {
-				node.TypeStrings = v.TypeStrings
-			}
-			return node, nil
-		}
+		return node, nil
 
-		return &Node{
-			Type:        v.Type,
-			TypeStrings: v.TypeStrings,
-			Value:       p.Slice(start, p.LookBack()),
-		}, nil
+	case Stream:
+		if err := ap.stream(v); err != nil {
+			p.Jump(start)
+			return nil, err
+		}
+		return nil, nil
 
 	case LoopUp:
 		i, err := v.Get()
@@ -118,6 +212,135 @@ func (ap *Parser) Expect(i interface{}) (*Node, error) {
 		}
 		return ap.Expect(i)
 
+	// The text of captures, scans, reserved words and predicates is handled
+	// by the internal parser, see parser.Extension.Run.
+	case op.Capture:
+		return ap.Expect(v.Value)
+	case op.Scan:
+		return ap.Expect(v.Value)
+	case op.Reserved:
+		return ap.Expect(v.Value)
+	case op.Where:
+		return ap.Expect(v.Value)
+
+	// The same goes for labels and traces.
+	case op.Label:
+		return ap.Expect(v.Value)
+	case op.Trace:
+		return ap.Expect(v.Value)
+
+	case op.Lazy:
+		return ap.Expect(v())
+	case op.Rule:
+		value, ok := v.Rules.Lookup(v.Name)
+		if !ok {
+			return nil, &parser.UndefinedRuleError{Name: v.Name}
+		}
+		node, err := ap.Expect(value)
+		if err != nil {
+			return nil, parser.NewRuleError(v.Name, err)
+		}
+		return node, nil
+
+	case Expression:
+		node, err := ap.expression(v, 0)
+		if err != nil {
+			p.Jump(start)
+			return nil, err
+		}
+		return node, nil
+
+	case ChainLeft:
+		operands, operators, err := ap.chain(v.Operand, v.Operator)
+		if err != nil {
+			p.Jump(start)
+			return nil, err
+		}
+		node := operands[0]
+		for i, operator := range operators {
+			node = fold(v.Fold, node, operator, operands[i+1])
+		}
+		return node, nil
+	case ChainRight:
+		operands, operators, err := ap.chain(v.Operand, v.Operator)
+		if err != nil {
+			p.Jump(start)
+			return nil, err
+		}
+		node := operands[len(operands)-1]
+		for i := len(operators) - 1; 0 <= i; i-- {
+			node = fold(v.Fold, operands[i], operators[i], node)
+		}
+		return node, nil
+
+	case Optional:
+		node, err := ap.Expect(v.Value)
+		if err != nil {
+			return v.Default.Clone(), nil
+		}
+		return node, nil
+
+	case op.Lexeme:
+		p.SkipTrivia()
+		node, err := ap.lexeme(v.Value)
+		if err != nil {
+			p.Jump(start)
+			return nil, err
+		}
+		return node, nil
+	case op.NoSkip:
+		return ap.lexeme(v.Value)
+
+	case op.CaseInsensitive:
+		fold := p.CaseInsensitive()
+		p.SetCaseInsensitive(true)
+		node, err := ap.Expect(v.Value)
+		p.SetCaseInsensitive(fold)
+		return node, err
+
+	case *op.Memo:
+		return ap.Expect(v.Value)
+
+	case op.Indented:
+		column := p.SkipIndentation()
+		if column <= p.IndentLevel() {
+			return nil, p.ExpectedParseError(v, start, p.Mark())
+		}
+		p.PushIndent(column)
+		node, err := ap.Expect(v.Value)
+		if err != nil {
+			p.Jump(start)
+			return nil, err
+		}
+		p.PopIndent()
+		return node, nil
+	case op.Aligned:
+		if p.SkipIndentation() != p.IndentLevel() {
+			return nil, p.ExpectedParseError(v, start, p.Mark())
+		}
+		node, err := ap.Expect(v.Value)
+		if err != nil {
+			p.Jump(start)
+			return nil, err
+		}
+		return node, nil
+
+	case op.Recover:
+		node, err := ap.Expect(v.Value)
+		if err != nil {
+			last, ok := ap.extension.Recover(err, v.Sync)
+			if !ok {
+				return nil, err
+			}
+			// Keep the skipped input in the tree.
+			node = ap.newNode(ErrorType)
+			node.Error = err
+			if last != nil {
+				node.Value = p.Slice(start, last)
+			}
+		}
+		return node, nil
+
 	case op.Not:
 		defer p.Jump(start)
 		if _, err := ap.Expect(v.Value); err == nil {
@@ -126,19 +349,34 @@ func (ap *Parser) Expect(i interface{}) (*Node, error) {
 		}
 	case op.Ensure:
 		if n, err := ap.Expect(v.Value); err != nil {
+			// The cut does not reach beyond the lookahead.
+			if cut, ok := cutOf(err); ok {
+				err = cut.Err
+			}
 			return n, err
 		}
 		p.Jump(start)
+	case op.Fail:
+		return nil, p.ExpectedParseError(v, start, start)
+	case op.Cut:
 	case op.And:
-		node := &Node{Type: -1}
+		node := ap.newNode(-1)
+		var cut bool
 		for _, i := range v {
+			if _, ok := i.(op.Cut); ok {
+				cut = true
+				continue
+			}
 			n, err := ap.Expect(i)
 			if err != nil {
 				p.Jump(start)
+				if cut {
+					return nil, cutError(err)
+				}
 				return nil, err
 			}
 			if n != nil {
-				if n.Type == -1 {
+				if n.Type == -1 && !ap.nested {
 					node.Adopt(n)
 				} else {
 					node.SetLast(n)
@@ -164,10 +402,31 @@ func (ap *Parser) Expect(i interface{}) (*Node, error) {
 				break
 			}
 			p.Jump(start)
+			if cut, ok := cutOf(err); ok {
+				// Committed to this alternative.
+				return nil, cut.Err
+			}
 		}
 		if !hit {
-			return nil, p.ExpectedParseError(v, start, p.Peek())
+			return nil, p.FarthestParseError(v, start, p.Peek())
 		}
+	case op.Longest:
+		var (
+			node *Node
+			end  *parser.Cursor
+		)
+		for _, i := range v {
+			n, err := ap.Expect(i)
+			if err == nil && (end == nil || end.Offset() < p.Offset()) {
+				node, end = n, p.Mark()
+			}
+			p.Jump(start)
+		}
+		if end == nil {
+			return nil, p.FarthestParseError(v, start, start)
+		}
+		p.Jump(end)
+		return node, nil
 	case op.XOr:
 		var (
 			node *Node
@@ -193,13 +452,87 @@ func (ap *Parser) Expect(i interface{}) (*Node, error) {
 			return node, nil
 		}
 
+	case op.Between:
+		p.SkipTrivia()
+		opened := p.Mark()
+		// Nodes of the delimiters are discarded.
+		if _, err := ap.Expect(v.Open); err != nil {
+			p.Jump(start)
+			return nil, err
+		}
+		// Report the text of the opening delimiter, not its capture.
+		open := v.Open
+		if p.Offset() != opened.Offset() {
+			text := p.Slice(opened, p.LookBack())
+			if r, size := utf8.DecodeRuneInString(text); size == len(text) {
+				open = r
+			} else {
+				open = text
+			}
+		}
+		node, err := ap.Expect(v.Body)
+		if err != nil {
+			p.Jump(start)
+			return nil, err
+		}
+		if _, err := ap.Expect(v.Close); err != nil {
+			err = ap.extension.UnclosedParseError(open, opened, err)
+			p.Jump(start)
+			return nil, err
+		}
+		return node, nil
+
+	case op.SeparatedBy:
+		node := ap.newNode(-1)
+		adopt := func(n *Node) {
+			if n == nil {
+				return
+			}
+			if n.Type == -1 {
+				node.Adopt(n)
+			} else {
+				node.SetLast(n)
+			}
+		}
+		n, err := ap.Expect(v.Element)
+		if err != nil {
+			return nil, err
+		}
+		adopt(n)
+		for {
+			offset, mark := p.Offset(), p.Mark()
+			// Nodes of the separators are discarded.
+			if _, err := ap.Expect(v.Separator); err != nil {
+				break
+			}
+			n, err := ap.Expect(v.Element)
+			if err != nil {
+				if !v.Trailing {
+					p.Jump(mark)
+				}
+				break
+			}
+			adopt(n)
+			if p.Offset() == offset {
+				if err := ap.extension.NoProgress(v); err != nil {
+					return nil, err
+				}
+				break
+			}
+		}
+		if node.IsParent() {
+			// Only return node if it has children.
+			return node, nil
+		}
+
 	case op.Range:
 		var (
 			count int
 			last  *parser.Cursor
-			node  = &Node{Type: -1}
+			node  = ap.newNode(-1)
 		)
 		for {
+			offset := p.Offset()
 			n, err := ap.Expect(v.Value)
 			if err != nil {
 				break
@@ -214,6 +547,11 @@ func (ap *Parser) Expect(i interface{}) (*Node, error) {
 			last = p.LookBack()
 			count++
 
+			if p.Offset() == offset {
+				if err := ap.extension.NoProgress(v); err != nil {
+					return nil, err
+				}
+			}
 			if v.Max != -1 && count == v.Max {
 				// Break if you have parsed the maximum amount of values.
 				// This way count will never be larger than v.Max.
@@ -241,12 +579,45 @@ func (ap *Parser) Expect(i interface{}) (*Node, error) {
 	return nil, nil
 }
 
+// lexeme expects the given value without skipping trivia.
+func (ap *Parser) lexeme(i interface{}) (*Node, error) {
+	p := ap.internal
+	trivia := p.Trivia()
+	p.SetTrivia(nil)
+	defer p.SetTrivia(trivia)
+	return ap.Expect(i)
+}
+
+// cutError wraps the given error in a parser.CutError, because it occurred
+// after passing an op.Cut. Errors that already passed a cut are returned as is.
+func cutError(err error) error {
+	if _, ok := cutOf(err); ok {
+		return err
+	}
+	return &parser.CutError{Err: err}
+}
+
+// cutOf returns the parser.CutError of the given error, if it has one.
+func cutOf(err error) (*parser.CutError, bool) {
+	switch v := err.(type) {
+	case *parser.ExpectedParseError:
+		// Most errors are failures of alternatives, avoid errors.As.
+		return nil, false
+	case *parser.CutError:
+		return v, true
+	}
+	var cut *parser.CutError
+	return cut, errors.As(err, &cut)
+}
+
 // ConvertAliases converts various default primitive types to aliases for type
 // matching.
 func ConvertAliases(i interface{}) interface{} {
 	switch v := i.(type) {
 	case func(p *Parser) (*Node, error):
 		return ParseNode(v)
+	case Capturer:
+		return v.Capture()
 
 	default:
 		return parser.ConvertAliases(i)
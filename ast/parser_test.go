@@ -5,6 +5,8 @@ import (
 	"github.com/di-wu/parser"
 	"github.com/di-wu/parser/ast"
 	"github.com/di-wu/parser/op"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -100,6 +102,105 @@ func ExampleParser_Expect_capture() {
 	// ["Digit","2"] <nil>
 }
 
+func ExampleParser_Expect_captureConvert() {
+	octet := ast.Capture{
+		TypeStrings: []string{"Octet"},
+		Value:       op.MinOne(parser.CheckRuneRange('0', '9')),
+		Convert: func(value string) (interface{}, error) {
+			return strconv.ParseUint(value, 10, 8)
+		},
+	}
+
+	p, _ := ast.New([]byte("255"))
+	n, err := p.Expect(octet)
+	fmt.Println(n.Data, err)
+	p, _ = ast.New([]byte("256"))
+	fmt.Println(p.Expect(octet))
+	// Output:
+	// 255 <nil>
+	// <nil> parse conflict [00:000]: strconv.ParseUint: parsing "256": value out of range
+}
+
+func TestParser_Expect_captureConvert(t *testing.T) {
+	p, _ := ast.New([]byte("abc"))
+	n, err := p.Expect(ast.Capture{Value: "abc", Convert: func(value string) (interface{}, error) {
+		return len(value), nil
+	}})
+	if err != nil || n.Data != 3 {
+		t.Errorf("expected 3, got %v %v", n, err)
+	}
+
+	p, _ = ast.New([]byte("abc"))
+	if n, err := p.Expect(ast.Capture{Value: "abc"}); err != nil || n.Data != nil {
+		t.Errorf("expected no data, got %v %v", n, err)
+	}
+}
+
+func ExampleParser_Expect_captureHooks() {
+	identifier := op.MinOne(parser.CheckRuneRange('a', 'z'))
+	declaration := ast.Capture{
+		TypeStrings: []string{"Declaration"},
+		Value:       op.And{"let ", identifier},
+		OnExit: func(n *ast.Node, context interface{}) error {
+			if n != nil {
+				context.(map[string]bool)[n.Value[4:]] = true
+			}
+			return nil
+		},
+	}
+	reference := ast.Capture{
+		TypeStrings: []string{"Reference"},
+		Value:       identifier,
+		OnExit: func(n *ast.Node, context interface{}) error {
+			if n != nil && !context.(map[string]bool)[n.Value] {
+				return fmt.Errorf("undefined: %s", n.Value)
+			}
+			return nil
+		},
+	}
+
+	p, _ := ast.New([]byte("let x;x;y"))
+	p.SetContext(make(map[string]bool))
+	fmt.Println(p.Expect(declaration, ';', reference, ';', reference))
+	// Output:
+	// <nil> parse conflict [00:008]: undefined: y
+}
+
+func TestParser_Expect_captureHooks(t *testing.T) {
+	var events []string
+	capture := func(name string, value interface{}) ast.Capture {
+		return ast.Capture{
+			TypeStrings: []string{name},
+			Value:       value,
+			OnEnter: func(context interface{}) error {
+				events = append(events, "enter "+name)
+				return nil
+			},
+			OnExit: func(n *ast.Node, context interface{}) error {
+				events = append(events, fmt.Sprintf("exit %s %v", name, n != nil))
+				return nil
+			},
+		}
+	}
+
+	p, _ := ast.New([]byte("ab"))
+	if _, err := p.Expect(capture("outer", op.And{capture("a", 'a'), capture("c", 'c')})); err == nil {
+		t.Error("expected an error")
+	}
+	expected := "enter outer,enter a,exit a true,enter c,exit c false,exit outer false"
+	if s := strings.Join(events, ","); s != expected {
+		t.Errorf("expected %s, got %s", expected, s)
+	}
+
+	failing := ast.Capture{
+		Value:   'a',
+		OnEnter: func(context interface{}) error { return fmt.Errorf("oops") },
+	}
+	if _, err := p.Expect(failing); err == nil || !strings.Contains(err.Error(), "oops") {
+		t.Errorf("expected the error of the hook, got %v", err)
+	}
+}
+
 func ExampleParser_Expect_not() {
 	p, _ := ast.New([]byte("bar"))
 
@@ -114,6 +215,21 @@ func ExampleParser_Expect_not() {
 	// <nil> <nil>
 }
 
+func ExampleParser_Expect_ensure() {
+	p, _ := ast.New([]byte("foo("))
+	name := ast.Capture{Type: 1, Value: op.MinOne(parser.CheckRuneRange('a', 'z'))}
+
+	// Only a name that is followed by '(' is a call.
+	fmt.Println(p.Expect(op.And{
+		name,
+		op.Ensure{Value: ast.Capture{Type: 2, Value: '('}},
+	}))
+	fmt.Println(p.Expect('('))
+	// Output:
+	// ["UNKNOWN",[["UNKNOWN","foo"]]] <nil>
+	// <nil> <nil>
+}
+
 func TestParser_Expect_not(t *testing.T) {
 	p, _ := ast.New([]byte("bar\nbaz"))
 	any := ast.Capture{
@@ -188,7 +304,7 @@ func ExampleParser_Expect_or() {
 	// Output:
 	// ["d","d"] <nil>
 	// ["UNKNOWN","at"] <nil>
-	// <nil> parse conflict [00:004]: expected op.Or or[d {000} !{000}] but got 'a'
+	// <nil> parse conflict [00:004]: expected op.Or d / {000} / !{000} but got 'a'
 }
 
 func TestParser_Expect_and_or(t *testing.T) {
@@ -226,6 +342,28 @@ func TestParser_Expect_and_or(t *testing.T) {
 	}
 }
 
+func TestParser_Expect_cut(t *testing.T) {
+	for _, test := range []struct {
+		input string
+		value interface{}
+	}{
+		{"fx", op.Not{Value: op.And{'f', op.Cut{}, '(', ')'}}},
+		{"a1", op.Optional(op.And{'a', op.Cut{}, 'b'})},
+		{"a1", op.Or{op.Or{op.And{'a', op.Cut{}, 'b'}, 'a'}, "a1"}},
+	} {
+		p, _ := ast.New([]byte(test.input))
+		if _, err := p.Expect(test.value); err != nil {
+			t.Errorf("%q %v: %v", test.input, test.value, err)
+		}
+	}
+
+	// The cut prevents the second alternative.
+	p, _ := ast.New([]byte("a1"))
+	if _, err := p.Expect(op.Or{op.And{'a', op.Cut{}, 'b'}, "a1"}); err == nil {
+		t.Error("expected an error")
+	}
+}
+
 func ExampleParser_Expect_xor() {
 	p, _ := ast.New([]byte("data"))
 
@@ -256,8 +394,89 @@ func ExampleParser_Expect_xor() {
 	fmt.Println(p.Expect(op.XOr{d, da, data}))
 	fmt.Println(p.Expect(op.XOr{a, t}))
 	// Output:
-	// <nil> parse conflict [00:001]: expected op.XOr xor[d da data] but got "da"
-	// <nil> parse conflict [00:000]: expected op.XOr xor[{000} {000}] but got 'd'
+	// <nil> parse conflict [00:001]: expected op.XOr d ^ da ^ data but got "da"
+	// <nil> parse conflict [00:000]: expected op.XOr {000} ^ {000} but got 'd'
+}
+
+func ExampleParser_Expect_longest() {
+	p, _ := ast.New([]byte("<<="))
+
+	fmt.Println(p.Expect(op.Longest{
+		ast.Capture{Type: 1, Value: "<<"},
+		ast.Capture{Type: 2, Value: "<<="},
+		ast.Capture{Type: 3, Value: '<'},
+	}))
+	// Output:
+	// ["UNKNOWN","<<="] <nil>
+}
+
+func ExampleParser_Expect_where() {
+	keywords := map[string]bool{"if": true, "else": true}
+	identifier := op.Where{
+		Value: ast.Capture{Type: 1, Value: op.MinOne(parser.CheckRuneRange('a', 'z'))},
+		Pred: func(matched string) bool {
+			return !keywords[matched]
+		},
+	}
+
+	p, _ := ast.New([]byte("if"))
+	fmt.Println(p.Expect(identifier))
+	fmt.Println(p.Expect(op.And{"if", op.Not{Value: identifier}}))
+	// Output:
+	// <nil> parse conflict [00:002]: expected op.Where where({001}) but got "if"
+	// <nil> <nil>
+}
+
+func ExampleParser_Expect_scan() {
+	var value int
+	number := op.Scan{
+		Value: ast.Capture{Type: 1, Value: op.MinOne(parser.CheckRuneRange('0', '9'))},
+		Into:  &value,
+	}
+
+	p, _ := ast.New([]byte("42"))
+	fmt.Println(p.Expect(number))
+	fmt.Println(value)
+	// Output:
+	// ["UNKNOWN","42"] <nil>
+	// 42
+}
+
+func TestParser_Expect_scanBacktrack(t *testing.T) {
+	var names []string
+	name := op.Scan{Value: op.MinOne(parser.CheckRuneRange('a', 'z')), Into: &names}
+	p, _ := ast.New([]byte("foo;"))
+	if _, err := p.Expect(op.Or{op.And{name, '('}, op.And{name, ';'}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 1 || names[0] != "foo" {
+		t.Error(names)
+	}
+}
+
+func TestParser_Expect_scanLookahead(t *testing.T) {
+	var n string
+	p, _ := ast.New([]byte("ab"))
+	if _, err := p.Expect(op.And{op.Ensure{Value: op.Scan{Value: "ab", Into: &n}}, "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if n != "" {
+		t.Errorf("stored %q within a lookahead", n)
+	}
+}
+
+func ExampleParser_Expect_backref() {
+	p, _ := ast.New([]byte("<<EOF\ntext\nEOF"))
+	word := op.MinOne(parser.CheckRuneRange('A', 'Z'))
+	line := op.MinZero(parser.CheckRuneRange('a', 'z'))
+
+	fmt.Println(p.Expect(op.And{
+		"<<", op.Capture{Name: "end", Value: ast.Capture{Type: 1, Value: word}}, '\n',
+		ast.Capture{Type: 2, Value: line}, '\n',
+		op.Backref{Name: "end"},
+	}))
+	// Output:
+	// ["UNKNOWN",[["UNKNOWN","EOF"],["UNKNOWN","text"]]] <nil>
 }
 
 func ExampleParser_Expect_range() {
@@ -271,5 +490,290 @@ func ExampleParser_Expect_range() {
 	fmt.Println(p.Expect(op.Min(4, 'a'))) // err
 	// Output:
 	// ["3A","aaa"] <nil>
-	// <nil> parse conflict [00:003]: expected op.Range 'a'{4:-1} but got "aaa"
+	// <nil> parse conflict [00:003]: expected op.Range 'a'{4,} but got "aaa"
+}
+
+func ExampleParser_Expect_optional() {
+	p, _ := ast.New([]byte("x=1;y;"))
+	name := ast.Capture{Type: 1, Value: parser.CheckRuneRange('a', 'z')}
+	value := ast.Capture{Type: 2, Value: parser.CheckRuneRange('0', '9')}
+	declaration := op.And{
+		name,
+		ast.Optional{
+			Value:   op.And{'=', value},
+			Default: &ast.Node{Type: 2, Value: "0"},
+		},
+		';',
+	}
+
+	fmt.Println(p.Expect(declaration))
+	fmt.Println(p.Expect(declaration))
+	// Output:
+	// ["UNKNOWN",[["UNKNOWN","x"],["UNKNOWN","1"]]] <nil>
+	// ["UNKNOWN",[["UNKNOWN","y"],["UNKNOWN","0"]]] <nil>
+}
+
+func ExampleParser_Expect_separatedBy() {
+	p, _ := ast.New([]byte("[a, b, c]"))
+	comma := ast.Capture{Type: 2, Value: op.And{',', ' '}}
+
+	fmt.Println(p.Expect(op.And{
+		'[',
+		op.SeparatedBy{
+			Element:   ast.Capture{Type: 1, Value: parser.CheckRuneRange('a', 'z')},
+			Separator: comma,
+		},
+		']',
+	}))
+	// Output:
+	// ["UNKNOWN",[["UNKNOWN","a"],["UNKNOWN","b"],["UNKNOWN","c"]]] <nil>
+}
+
+func ExampleParser_Expect_between() {
+	letter := ast.Capture{Type: 1, Value: parser.CheckRuneRange('a', 'z')}
+	group := op.Between{
+		Open:  ast.Capture{Type: 2, Value: '('},
+		Body:  op.MinOne(letter),
+		Close: ast.Capture{Type: 2, Value: ')'},
+	}
+
+	p, _ := ast.New([]byte("(ab)"))
+	fmt.Println(p.Expect(group))
+	p, _ = ast.New([]byte("(ab"))
+	fmt.Println(p.Expect(group))
+	// Output:
+	// ["UNKNOWN",[["UNKNOWN","a"],["UNKNOWN","b"]]] <nil>
+	// <nil> parse conflict [00:003]: expected int32 ')' but got "", unclosed '(' opened at [00:000]
+}
+
+func ExampleParser_Expect_caseInsensitive() {
+	p, _ := ast.New([]byte("Ünïcode"))
+	fmt.Println(p.Expect(op.CaseInsensitive{
+		Value: ast.Capture{
+			TypeStrings: []string{"Keyword"},
+			Value:       "üNÏCODE",
+		},
+	}))
+	// Output:
+	// ["Keyword","Ünïcode"] <nil>
+}
+
+func ExampleParser_Expect_sequence() {
+	p, _ := ast.New([]byte("1 <= 2"))
+	digit := ast.Capture{
+		TypeStrings: []string{"Digit"},
+		Value:       parser.CheckRuneRange('0', '9'),
+	}
+
+	fmt.Println(p.Expect(digit, " <= ", digit))
+	// Output:
+	// ["UNKNOWN",[["Digit","1"],["Digit","2"]]] <nil>
+}
+
+func ExampleParser_Expect_trivia() {
+	internal, _ := parser.New([]byte(" 1 +  2 "), parser.WithTrivia(' '))
+	p, _ := ast.NewFromParser(internal)
+	digit := ast.Capture{
+		TypeStrings: []string{"Digit"},
+		Value:       parser.CheckRuneRange('0', '9'),
+	}
+
+	fmt.Println(p.Expect(ast.Capture{
+		TypeStrings: []string{"Sum"},
+		Value:       op.And{digit, '+', digit},
+	}))
+	// Output:
+	// ["Sum",[["Digit","1"],["Digit","2"]]] <nil>
+}
+
+func nested(p *ast.Parser) (*ast.Node, error) {
+	return p.Expect(ast.Capture{
+		TypeStrings: []string{"Nested"},
+		Value:       op.And{'[', op.Optional(nested), ']'},
+	})
+}
+
+func TestParser_Expect_maxDepth(t *testing.T) {
+	input := []byte(strings.Repeat("[", 10000) + strings.Repeat("]", 10000))
+	internal, _ := parser.New(input, parser.WithMaxDepth(100))
+	p, _ := ast.NewFromParser(internal)
+
+	if _, err := p.Expect(nested); err == nil {
+		t.Fatal("expected an error")
+	} else if _, ok := err.(*parser.DepthExceededError); !ok {
+		t.Fatal(err)
+	}
+}
+
+// counter counts the number of values, it is attached to the parser.
+type counter struct {
+	n int
+}
+
+func (c *counter) Snapshot() interface{} {
+	return c.n
+}
+
+func (c *counter) Restore(snapshot interface{}) {
+	c.n = snapshot.(int)
+}
+
+func TestParser_Expect_states(t *testing.T) {
+	internal, _ := parser.New([]byte("ab"))
+	p, _ := ast.NewFromParser(internal)
+	start := internal.Mark()
+	// Transactions are only started if states are attached, so expecting a
+	// rune does not allocate more than the internal parser does.
+	want := testing.AllocsPerRun(100, func() {
+		internal.Jump(start)
+		_, _ = internal.Expect('a')
+	})
+	if allocs := testing.AllocsPerRun(100, func() {
+		internal.Jump(start)
+		_, _ = p.Expect('a')
+	}); allocs != want {
+		t.Errorf("expected %v allocations, got %v", want, allocs)
+	}
+
+	c := new(counter)
+	internal.Attach(c)
+
+	// The state is restored on failure.
+	internal.Jump(start)
+	count := func(p *ast.Parser) (*ast.Node, error) {
+		c.n++
+		return p.Expect('a')
+	}
+	if _, err := p.Expect(op.Or{op.And{count, 'c'}, op.And{'a', 'b'}}); err != nil {
+		t.Fatal(err)
+	}
+	if c.n != 0 {
+		t.Errorf("expected the state to be restored, got %d", c.n)
+	}
+}
+
+func TestParser_Expect_noProgress(t *testing.T) {
+	a := ast.Capture{Value: 'a'}
+	internal, _ := parser.New([]byte("aab"), parser.WithProgressCheck())
+	p, _ := ast.NewFromParser(internal)
+	if _, err := p.Expect(op.MinZero(op.Optional(a)), 'b'); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestParser_Expect_memoization(t *testing.T) {
+	var calls int
+	number := &op.Memo{Value: ast.ParseNode(func(p *ast.Parser) (*ast.Node, error) {
+		calls++
+		return p.Expect(ast.Capture{
+			Type:  1,
+			Value: op.MinOne(parser.CheckRuneRange('0', '9')),
+		})
+	})}
+
+	internal, _ := parser.New([]byte("12-"), parser.WithMemoization(1024))
+	p, _ := ast.NewFromParser(internal)
+	node, err := p.Expect(op.Or{
+		op.And{number, '+'},
+		op.And{number, '-'},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+	if children := node.Children(); len(children) != 1 || children[0].Value != "12" {
+		t.Error(node)
+	}
+
+	// Memoized nodes are not shared.
+	memo := &op.Memo{Value: ast.Capture{Value: 'a'}}
+	internal, _ = parser.New([]byte("a"))
+	p, _ = ast.NewFromParser(internal)
+	start := internal.Mark()
+	first, _ := p.Expect(memo)
+	internal.Jump(start)
+	second, _ := p.Expect(memo)
+	if first == nil || second == nil || first == second || second.Value != "a" {
+		t.Error(first, second)
+	}
+	if !internal.Done() {
+		t.Error(internal.Current())
+	}
+}
+
+func TestParser_Expect_leftRecursion(t *testing.T) {
+	// expr <- expr '-' digit / digit
+	digit := ast.Capture{Type: 1, Value: parser.CheckRuneRange('0', '9')}
+	expr := new(op.Memo)
+	expr.Value = op.Or{
+		ast.Capture{Type: 2, Value: op.And{expr, '-', digit}},
+		digit,
+	}
+
+	internal, _ := parser.New([]byte("1-2-3"))
+	p, _ := ast.NewFromParser(internal)
+	node, err := p.Expect(expr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Left associative: ((1-2)-3).
+	if node.Type != 2 || node.LastChild.Value != "3" ||
+		node.FirstChild.Type != 2 || node.FirstChild.FirstChild.Value != "1" {
+		t.Error(node)
+	}
+	if !internal.Done() {
+		t.Error(internal.Current())
+	}
+}
+
+func ExampleParser_Expect_recover() {
+	internal, _ := parser.New([]byte("a;b;a;"), parser.WithRecovery())
+	p, _ := ast.NewFromParser(internal)
+	statement := op.Recover{
+		Value: ast.Capture{
+			TypeStrings: []string{"Statement"},
+			Value:       op.And{'a', ';'},
+		},
+		Sync: ';',
+	}
+
+	node, _ := p.Expect(op.MinOne(statement))
+	fmt.Println(node)
+	fmt.Println(node.Children()[1].Error)
+	// Output:
+	// ["UNKNOWN",[["Statement","a;"],["ERROR","b;"],["Statement","a;"]]]
+	// parse conflict [00:002]: expected int32 'a' but got 'b'
+}
+
+func ExampleParser_Expect_keyword() {
+	identifier := ast.Capture{
+		Value: op.Reserved{
+			Value: op.MinOne(parser.CheckRuneRange('a', 'z')),
+			Words: []string{"let"},
+		},
+	}
+	let := op.And{op.Keyword("let"), ' ', identifier}
+
+	p, _ := ast.New([]byte("let lettuce"))
+	fmt.Println(p.Expect(let))
+	p, _ = ast.New([]byte("let let"))
+	fmt.Println(p.Expect(let))
+	// Output:
+	// ["UNKNOWN",[["UNKNOWN","lettuce"]]] <nil>
+	// <nil> parse conflict [00:007]: expected op.Reserved reserved(func+) but got "let"
+}
+
+func ExampleParser_Expect_spans() {
+	word := ast.Capture{Value: op.MinOne(parser.CheckRuneRange('a', 'z'))}
+
+	p, _ := ast.New([]byte("foo bar"))
+	node, _ := p.Expect(op.And{word, ' ', word})
+	for _, child := range node.Children() {
+		fmt.Println(child.Value, child.Start.Offset(), child.End.Offset())
+	}
+	// Output:
+	// foo 0 3
+	// bar 4 7
 }
@@ -0,0 +1,43 @@
+package ast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Pretty returns an indented rendering of the node and its children, one node
+// per line with its type, its span and its value. e.g.
+//
+//	Add [00:000-00:003]
+//	  Int [00:000-00:001] "1"
+//	  Int [00:002-00:003] "2"
+//
+// The span is omitted if the node was not captured from the input.
+func (n *Node) Pretty() string {
+	var b strings.Builder
+	n.pretty(&b, 0)
+	return b.String()
+}
+
+func (n *Node) pretty(b *strings.Builder, depth int) {
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString(n.TypeString())
+	if n.Start != nil && n.End != nil {
+		startRow, startColumn := n.Start.Position()
+		endRow, endColumn := n.End.Position()
+		fmt.Fprintf(b, " [%02d:%03d-%02d:%03d]", startRow, startColumn, endRow, endColumn)
+	}
+	if !n.IsParent() {
+		b.WriteString(" ")
+		b.WriteString(strconv.Quote(n.Value))
+	}
+	if n.Error != nil {
+		b.WriteString(": ")
+		b.WriteString(n.Error.Error())
+	}
+	b.WriteString("\n")
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		c.pretty(b, depth+1)
+	}
+}
@@ -0,0 +1,30 @@
+package ast_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/di-wu/parser/ast"
+)
+
+func ExampleNode_Pretty() {
+	p, _ := ast.New([]byte("-1+2*3"))
+	n, _ := p.Expect(expression)
+	fmt.Print(n.Pretty())
+	// Output:
+	// Add [00:000-00:006]
+	//   Neg [00:000-00:002]
+	//     Int [00:001-00:002] "1"
+	//   Mul [00:003-00:006]
+	//     Int [00:003-00:004] "2"
+	//     Int [00:005-00:006] "3"
+}
+
+func TestNode_Pretty(t *testing.T) {
+	n := tree("r", tree("a\n"), &ast.Node{Type: ast.ErrorType, Value: "x", Error: errors.New("oops")})
+	expected := "UNKNOWN\n  UNKNOWN \"a\\n\"\n  ERROR \"x\": oops\n"
+	if s := n.Pretty(); s != expected {
+		t.Errorf("expected %q, got %q", expected, s)
+	}
+}
@@ -0,0 +1,47 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/di-wu/parser"
+)
+
+// Text returns the source of the node, based on its span in the input of the
+// given parser. Groups of nodes span from their first to their last child.
+// Returns an empty string if the node has no span.
+func (n *Node) Text(p *parser.Parser) string {
+	start, end := n.Start, n.End
+	if start == nil || end == nil {
+		for c := n.FirstChild; c != nil && start == nil; c = c.NextSibling {
+			start = c.Start
+		}
+		for c := n.LastChild; c != nil && end == nil; c = c.PreviousSibling {
+			end = c.End
+		}
+		if start == nil || end == nil {
+			return ""
+		}
+	}
+	return p.Text(start, end)
+}
+
+// Reconstruct reproduces the source of the node from its values and trivia,
+// which are only kept by parsers in concrete mode (see Parser.SetConcrete).
+// Unlike Node.Text, it reflects the changes made to the tree, e.g. replaced
+// nodes or values.
+func Reconstruct(n *Node) string {
+	var b strings.Builder
+	reconstruct(&b, n)
+	return b.String()
+}
+
+func reconstruct(b *strings.Builder, n *Node) {
+	b.WriteString(n.Leading)
+	if !n.IsParent() {
+		b.WriteString(n.Value)
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		reconstruct(b, c)
+	}
+	b.WriteString(n.Trailing)
+}
@@ -0,0 +1,43 @@
+package ast_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+)
+
+func ExampleReconstruct() {
+	internal, _ := parser.New([]byte("1 + /* two */ 2"), parser.WithTrivia(op.Or{' ', parser.CheckBlockComment("/*", "*/", false)}))
+	p, _ := ast.NewFromParser(internal)
+	p.SetConcrete(true)
+	n, _ := p.Expect(expression)
+
+	// Replace the second operand, but keep its comment.
+	n.LastChild.Value = "3"
+	fmt.Println(ast.Reconstruct(n))
+	// Output:
+	// 1 + /* two */ 3
+}
+
+func TestNode_Text(t *testing.T) {
+	internal, _ := parser.New([]byte("-1+(2*3)"))
+	p, _ := ast.NewFromParser(internal)
+	n, _ := p.Expect(expression)
+	for _, test := range []struct {
+		node *ast.Node
+		text string
+	}{
+		{node: n, text: "-1+(2*3"},
+		{node: n.FirstChild, text: "-1"},
+		{node: n.LastChild, text: "2*3"},
+		{node: n.LastChild.LastChild, text: "3"},
+		{node: tree("a"), text: ""},
+	} {
+		if s := test.node.Text(internal); s != test.text {
+			t.Errorf("%s: expected %q, got %q", test.node, test.text, s)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package ast
+
+import (
+	"fmt"
+
+	"github.com/di-wu/parser"
+)
+
+// Stream represents a value of which the node is passed to the Emit function as
+// soon as it is matched, instead of being added to the tree. e.g.
+// op.MinZero(Stream{Value: record, Emit: f}) parses any number of records
+// without holding all their nodes in memory. Combined with a streaming parser
+// (see parser.NewReader) neither the input nor the tree needs to fit in memory.
+//
+// If Emit returns an error, the value fails with the error at its position.
+// Nodes are emitted as soon as they are matched, also if an enclosing value
+// fails afterwards.
+type Stream struct {
+	Value interface{}
+	Emit  func(n *Node) error
+}
+
+func (s Stream) String() string {
+	return fmt.Sprintf("stream(%s)", parser.Stringer(s.Value))
+}
+
+// stream matches the value of the stream and emits its node.
+func (ap *Parser) stream(s Stream) error {
+	p := ap.internal
+	p.SkipTrivia()
+	begin := p.Mark()
+	node, err := ap.Expect(s.Value)
+	if err != nil {
+		return err
+	}
+	if node == nil {
+		// Nothing to emit.
+		return nil
+	}
+	if err := s.Emit(node); err != nil {
+		return ap.failure(err, begin)
+	}
+	return nil
+}
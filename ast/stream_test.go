@@ -0,0 +1,59 @@
+package ast_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+)
+
+func ExampleStream() {
+	record := ast.Capture{
+		TypeStrings: []string{"Record"},
+		Value:       op.And{op.MinOne(parser.CheckNotRune('\n')), '\n'},
+	}
+	records := ast.Stream{
+		Value: record,
+		Emit: func(n *ast.Node) error {
+			fmt.Printf("%q\n", n.Value)
+			return nil
+		},
+	}
+
+	internal, _ := parser.NewReader(strings.NewReader("a=1\nb=2\nc=3\n"), 16)
+	p, _ := ast.NewFromParser(internal)
+	fmt.Println(p.Expect(op.MinZero(records)))
+	// Output:
+	// "a=1\n"
+	// "b=2\n"
+	// "c=3\n"
+	// <nil> <nil>
+}
+
+func TestStream(t *testing.T) {
+	var count int
+	records := ast.Stream{
+		Value: ast.Capture{Value: op.Lexeme{Value: op.MinOne(parser.CheckRuneRange('a', 'z'))}},
+		Emit: func(n *ast.Node) error {
+			if count++; n.Value == "stop" {
+				return fmt.Errorf("stopped")
+			}
+			return nil
+		},
+	}
+
+	internal, _ := parser.New([]byte("a b c"), parser.WithTrivia(' '))
+	p, _ := ast.NewFromParser(internal)
+	if n, err := p.Expect(op.MinZero(records)); n != nil || err != nil || count != 3 {
+		t.Errorf("expected 3 records, got %d: %v %v", count, n, err)
+	}
+
+	internal, _ = parser.New([]byte("a stop"), parser.WithTrivia(' '))
+	p, _ = ast.NewFromParser(internal)
+	if _, err := p.Expect(records, records); err == nil || !strings.Contains(err.Error(), "[00:002]: stopped") {
+		t.Errorf("expected the error of Emit, got %v", err)
+	}
+}
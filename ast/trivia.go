@@ -0,0 +1,43 @@
+package ast
+
+import "github.com/di-wu/parser"
+
+// SetConcrete enables or disables the concrete mode of the parser. In concrete
+// mode, all the consumed text that is not part of the value of a node (e.g.
+// whitespace, comments and punctuation) is kept as the Leading and Trailing
+// trivia of the nodes. This allows the source to be reproduced exactly from the
+// tree, e.g. by formatters.
+func (ap *Parser) SetConcrete(concrete bool) {
+	ap.concrete = concrete
+}
+
+// attachTrivia attaches the text from the given mark up to the current position
+// to the given (outermost) node and its children.
+func (ap *Parser) attachTrivia(n *Node, start *parser.Cursor) {
+	end := ap.trivia(n, start)
+	n.Trailing += ap.internal.Text(end, ap.internal.Mark())
+}
+
+// trivia attaches the text in between the given mark and the start of the node
+// to the node, the same for the children of the node. Returns a mark to the
+// end of the node.
+func (ap *Parser) trivia(n *Node, from *parser.Cursor) *parser.Cursor {
+	p := ap.internal
+	if n.Start == nil || n.End == nil {
+		// e.g. groups of nodes, which have no span themselves.
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			from = ap.trivia(c, from)
+		}
+		return from
+	}
+
+	n.Leading = p.Text(from, n.Start)
+	at := n.Start
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		at = ap.trivia(c, at)
+	}
+	if n.IsParent() {
+		n.Trailing = p.Text(at, n.End)
+	}
+	return n.End
+}
@@ -0,0 +1,74 @@
+package ast_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+)
+
+func ExampleParser_SetConcrete() {
+	internal, _ := parser.New([]byte(" (1 + 2) * 3 "), parser.WithTrivia(' '))
+	p, _ := ast.NewFromParser(internal)
+	p.SetConcrete(true)
+	n, _ := p.Expect(expression, parser.EOD)
+	ast.Inspect(n, func(n *ast.Node) bool {
+		if n == nil {
+			return false
+		}
+		fmt.Printf("%s %q %q %q\n", n.TypeString(), n.Leading, n.Value, n.Trailing)
+		return true
+	})
+	// Output:
+	// UNKNOWN "" "" " "
+	// Mul " (" "" ""
+	// Add "" "" ""
+	// Int "" "1" ""
+	// Int " + " "2" ""
+	// Int ") * " "3" ""
+}
+
+func TestParser_SetConcrete(t *testing.T) {
+	trivia := op.Or{' ', '\n', parser.CheckBlockComment("/*", "*/", false)}
+	for _, input := range []string{
+		"1",
+		"  -1 ",
+		"1 /* one */ + /* two */ 2\n",
+		"((1))!",
+		"2 ** -(3 + 4) * 5 ",
+	} {
+		internal, _ := parser.New([]byte(input), parser.WithTrivia(trivia))
+		p, _ := ast.NewFromParser(internal)
+		p.SetConcrete(true)
+		n, err := p.Expect(expression, parser.EOD)
+		if err != nil {
+			t.Errorf("%q: %v", input, err)
+			continue
+		}
+		if s := ast.Reconstruct(n); s != input {
+			t.Errorf("expected %q, got %q", input, s)
+		}
+	}
+
+	p, _ := ast.New([]byte("1+2"))
+	n, _ := p.Expect(expression)
+	ast.Inspect(n, func(n *ast.Node) bool {
+		if n != nil && (n.Leading != "" || n.Trailing != "") {
+			t.Error("expected no trivia outside of concrete mode")
+		}
+		return true
+	})
+}
+
+func TestParser_Expect_lexemeTrivia(t *testing.T) {
+	internal, _ := parser.New([]byte("   a"), parser.WithTrivia(' '))
+	p, _ := ast.NewFromParser(internal)
+	if _, err := p.Expect(op.Lexeme{Value: ast.Capture{Value: 'b'}}); err == nil {
+		t.Error("expected an error")
+	}
+	if offset := internal.Offset(); offset != 0 {
+		t.Errorf("trivia consumed, offset %d", offset)
+	}
+}
@@ -0,0 +1,85 @@
+//go:build go1.18
+// +build go1.18
+
+package ast
+
+// TypedCapture is a Capture of which the captured value is converted to a
+// payload of type T. It can be used anywhere a Capture can be used, the
+// payload is stored in the Data of the resulting node.
+type TypedCapture[T any] struct {
+	// Type of the node.
+	Type int
+	// TypeStrings contains all the string representations of the available types.
+	TypeStrings []string
+	// Value is the expression to capture the value of the node.
+	Value interface{}
+	// Convert converts the captured value to the payload of the node. If the
+	// conversion fails, the capture fails with the error at its position.
+	Convert func(value string) (T, error)
+}
+
+// Capture returns the (dynamic) capture that the typed capture represents.
+func (c TypedCapture[T]) Capture() Capture {
+	capture := Capture{
+		Type:        c.Type,
+		TypeStrings: c.TypeStrings,
+		Value:       c.Value,
+	}
+	if c.Convert != nil {
+		capture.Convert = func(value string) (interface{}, error) {
+			return c.Convert(value)
+		}
+	}
+	return capture
+}
+
+func (c TypedCapture[T]) String() string {
+	return c.Capture().String()
+}
+
+// TypedNode is a node with a payload of type T.
+type TypedNode[T any] struct {
+	*Node
+	// Payload is the converted value of the node.
+	Payload T
+}
+
+// Typed returns a typed view of the given node. Returns false if the data of
+// the node is not of type T.
+func Typed[T any](n *Node) (TypedNode[T], bool) {
+	if n == nil {
+		return TypedNode[T]{}, false
+	}
+	payload, ok := n.Data.(T)
+	return TypedNode[T]{Node: n, Payload: payload}, ok
+}
+
+// TypedChildren returns the children of the given node that have a payload of
+// type T, skipping all other children.
+func TypedChildren[T any](n *Node) []TypedNode[T] {
+	var children []TypedNode[T]
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if child, ok := Typed[T](c); ok {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// ExpectTyped works the same as Parser.Expect, but returns the node of the
+// given capture together with its payload.
+func ExpectTyped[T any](p *Parser, c TypedCapture[T]) (TypedNode[T], error) {
+	node, err := p.Expect(c)
+	if err != nil {
+		return TypedNode[T]{}, err
+	}
+	if node == nil {
+		// E.g. an empty capture that was skipped.
+		return TypedNode[T]{}, nil
+	}
+	typed, _ := Typed[T](node)
+	return typed, nil
+}
+
+// Ensure that typed captures are converted by the parser.
+var _ Capturer = TypedCapture[string]{}
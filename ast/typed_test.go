@@ -0,0 +1,85 @@
+//go:build go1.18
+// +build go1.18
+
+package ast_test
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+)
+
+func ExampleExpectTyped() {
+	integer := ast.TypedCapture[int]{
+		TypeStrings: []string{"Int"},
+		Value:       op.MinOne(parser.CheckRuneRange('0', '9')),
+		Convert:     strconv.Atoi,
+	}
+
+	p, _ := ast.New([]byte("42"))
+	n, err := ast.ExpectTyped(p, integer)
+	fmt.Println(n.Payload+1, n, err)
+	// Output:
+	// 43 ["Int","42"] <nil>
+}
+
+func ExampleTypedChildren() {
+	integer := ast.TypedCapture[int]{
+		Type:    1,
+		Value:   op.MinOne(parser.CheckRuneRange('0', '9')),
+		Convert: strconv.Atoi,
+	}
+	list := ast.Capture{
+		Value: op.SeparatedBy{Element: integer, Separator: ','},
+	}
+
+	p, _ := ast.New([]byte("1,2,3"))
+	n, _ := p.Expect(list)
+	var sum int
+	for _, c := range ast.TypedChildren[int](n) {
+		sum += c.Payload
+	}
+	fmt.Println(sum)
+	// Output:
+	// 6
+}
+
+func TestExpectTyped(t *testing.T) {
+	integer := ast.TypedCapture[int]{
+		TypeStrings: []string{"Int"},
+		Value:       op.MinOne(parser.CheckRuneRange('0', '9')),
+		Convert:     strconv.Atoi,
+	}
+
+	p, _ := ast.New([]byte("99999999999999999999"))
+	if _, err := ast.ExpectTyped(p, integer); err == nil || !strings.Contains(err.Error(), "value out of range") {
+		t.Errorf("expected the conversion error, got %v", err)
+	}
+
+	p, _ = ast.New([]byte("a"))
+	if n, err := ast.ExpectTyped(p, integer); err == nil || n.Node != nil {
+		t.Errorf("expected an error, got %v", n)
+	}
+
+	if s := fmt.Sprint(integer); s != "Int" {
+		t.Errorf("expected the name of the type, got %q", s)
+	}
+}
+
+func TestTyped(t *testing.T) {
+	n := &ast.Node{Value: "1", Data: 1}
+	if v, ok := ast.Typed[int](n); !ok || v.Payload != 1 || v.Value != "1" {
+		t.Errorf("expected a typed node, got %v %v", v, ok)
+	}
+	if _, ok := ast.Typed[string](n); ok {
+		t.Error("expected the data not to be a string")
+	}
+	if _, ok := ast.Typed[int](nil); ok {
+		t.Error("expected nil not to be typed")
+	}
+}
@@ -0,0 +1,27 @@
+package ast
+
+// WithTypes sets the names of the node types, the type of every name is its
+// index. Nodes of captures without TypeStrings get these names, so the slice
+// does not need to be passed to every Capture. e.g. WithTypes(NodeTypes...)
+// names the types of the AST grammar.
+func WithTypes(names ...string) Option {
+	return func(p *Parser) {
+		p.types = names
+	}
+}
+
+// TypeName returns the name of the given type, based on the given type strings.
+func TypeName(id int, typeStrings []string) (string, bool) {
+	if 0 <= id && id < len(typeStrings) {
+		return typeStrings[id], true
+	}
+	return "", false
+}
+
+// typeStrings returns the type strings of the nodes of the given capture.
+func (ap *Parser) typeStrings(c Capture) []string {
+	if len(c.TypeStrings) != 0 {
+		return c.TypeStrings
+	}
+	return ap.types
+}
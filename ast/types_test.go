@@ -0,0 +1,47 @@
+package ast_test
+
+import (
+	"fmt"
+
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+)
+
+func ExampleWithTypes() {
+	const (
+		ListType = iota
+		ItemType
+	)
+	item := ast.Capture{Type: ItemType, Value: op.MinOne(parser.CheckRuneRange('a', 'z'))}
+	list := ast.Capture{Type: ListType, Value: op.And{item, op.MinZero(op.And{',', item})}}
+
+	p, _ := ast.New([]byte("a,bc"), ast.WithTypes("List", "Item"))
+	fmt.Println(p.Expect(list))
+	// Output:
+	// ["List",[["Item","a"],["Item","bc"]]] <nil>
+}
+
+func ExampleWithTypes_grammars() {
+	// Both grammars number their types from 0.
+	a := ast.Capture{Type: 0, Value: 'a'}
+	b := ast.Capture{Type: 0, Value: 'b'}
+
+	pa, _ := ast.New([]byte("a"), ast.WithTypes("A"))
+	fmt.Println(pa.Expect(a))
+	pb, _ := ast.New([]byte("b"), ast.WithTypes("B"))
+	fmt.Println(pb.Expect(b))
+	// Output:
+	// ["A","a"] <nil>
+	// ["B","b"] <nil>
+}
+
+func ExampleTypeName() {
+	fmt.Println(ast.TypeName(1, []string{"A", "B"}))
+	fmt.Println(ast.TypeName(2, []string{"A", "B"}))
+	fmt.Println(ast.TypeName(-1, nil))
+	// Output:
+	// B true
+	//  false
+	//  false
+}
@@ -0,0 +1,188 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Unmarshaler is implemented by types that unmarshal a node themselves, e.g. to
+// convert the value of a node to a custom type.
+type Unmarshaler interface {
+	UnmarshalNode(n *Node) error
+}
+
+// UnmarshalError is an error that occurs when a node can not be unmarshaled
+// into a value of the given type.
+type UnmarshalError struct {
+	Node *Node
+	Type reflect.Type
+	Err  error
+}
+
+func (e *UnmarshalError) Error() string {
+	if e.Node == nil {
+		return fmt.Sprintf("ast: can not unmarshal into %v: %v", e.Type, e.Err)
+	}
+	var position string
+	if e.Node.Start != nil {
+		row, column := e.Node.Start.Position()
+		position = fmt.Sprintf(" [%02d:%03d]", row, column)
+	}
+	return fmt.Sprintf("ast: can not unmarshal %s%s into %v: %v", e.Node.TypeString(), position, e.Type, e.Err)
+}
+
+func (e *UnmarshalError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	nodeType        = reflect.TypeOf((*Node)(nil))
+	unmarshalerType = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+)
+
+// Unmarshal stores the node in the value pointed to by v, similar to
+// encoding/json. Values are unmarshaled based on their type:
+//
+//   - *Node: the node itself.
+//   - Unmarshaler: calls UnmarshalNode.
+//   - strings, booleans and numbers: the value of the node, parsed by strconv.
+//     Integers can have a base prefix, e.g. 0x1F.
+//   - slices: every child of the node is unmarshaled into an element.
+//   - pointers: allocates a new value if the pointer is nil.
+//   - structs: every exported field is unmarshaled from the children of which
+//     the type name (see TypeString) equals the name of the field. If the
+//     field is a slice, all matching children are unmarshaled into it,
+//     otherwise only the first. Fields without matching children are left
+//     untouched.
+//
+// The name of a struct field can be changed with the "ast" tag, e.g.
+// `ast:"Int"`. The tag `ast:",value"` unmarshals the node itself into the
+// field instead of one of its children, e.g. to get the value of the node or
+// the node itself. Fields with the tag `ast:"-"` are ignored.
+func Unmarshal(n *Node, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &UnmarshalError{
+			Type: reflect.TypeOf(v),
+			Err:  fmt.Errorf("expected a non-nil pointer"),
+		}
+	}
+	return unmarshal(n, rv.Elem())
+}
+
+// unmarshal stores the node in the given (settable) value.
+func unmarshal(n *Node, v reflect.Value) error {
+	if v.Type() == nodeType {
+		v.Set(reflect.ValueOf(n))
+		return nil
+	}
+	if v.Kind() != reflect.Ptr && v.CanAddr() && v.Addr().Type().Implements(unmarshalerType) {
+		return v.Addr().Interface().(Unmarshaler).UnmarshalNode(n)
+	}
+
+	var err error
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return unmarshal(n, v.Elem())
+	case reflect.String:
+		v.SetString(n.Value)
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(n.Value); err == nil {
+			v.SetBool(b)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		if i, err = strconv.ParseInt(n.Value, 0, v.Type().Bits()); err == nil {
+			v.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var u uint64
+		if u, err = strconv.ParseUint(n.Value, 0, v.Type().Bits()); err == nil {
+			v.SetUint(u)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(n.Value, v.Type().Bits()); err == nil {
+			v.SetFloat(f)
+		}
+	case reflect.Slice:
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if err := appendNode(c, v); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		return unmarshalStruct(n, v)
+	default:
+		err = fmt.Errorf("unsupported type")
+	}
+	if err != nil {
+		return &UnmarshalError{Node: n, Type: v.Type(), Err: err}
+	}
+	return nil
+}
+
+// appendNode unmarshals the node into a new element of the given slice.
+func appendNode(n *Node, slice reflect.Value) error {
+	elem := reflect.New(slice.Type().Elem()).Elem()
+	if err := unmarshal(n, elem); err != nil {
+		return err
+	}
+	slice.Set(reflect.Append(slice, elem))
+	return nil
+}
+
+// unmarshalStruct unmarshals the children of the node into the fields of the
+// given struct.
+func unmarshalStruct(n *Node, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			// Unexported field.
+			continue
+		}
+		tag := field.Tag.Get("ast")
+		if tag == "-" {
+			continue
+		}
+		name, option := tag, ""
+		if i := strings.Index(tag, ","); i != -1 {
+			name, option = tag[:i], tag[i+1:]
+		}
+		if option == "value" {
+			if err := unmarshal(n, v.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		f := v.Field(i)
+		// Slices that unmarshal themselves are unmarshaled from a single child.
+		many := f.Kind() == reflect.Slice && !f.Addr().Type().Implements(unmarshalerType)
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.TypeString() != name {
+				continue
+			}
+			if !many {
+				if err := unmarshal(c, f); err != nil {
+					return err
+				}
+				break
+			}
+			if err := appendNode(c, f); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
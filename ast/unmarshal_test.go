@@ -0,0 +1,117 @@
+package ast_test
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+)
+
+const (
+	ConfigType = iota
+	PairType
+	KeyType
+	NumberType
+)
+
+var configTypes = []string{"Config", "Pair", "Key", "Number"}
+
+var config = ast.Capture{
+	Type:        ConfigType,
+	TypeStrings: configTypes,
+	Value:       op.And{pair, op.MinZero(op.And{',', pair})},
+}
+
+var pair = ast.Capture{
+	Type:        PairType,
+	TypeStrings: configTypes,
+	Value: op.And{
+		ast.Capture{Type: KeyType, TypeStrings: configTypes, Value: op.MinOne(parser.CheckRuneRange('a', 'z'))},
+		'=',
+		op.MinOne(ast.Capture{Type: NumberType, TypeStrings: configTypes, Value: op.MinOne(parser.CheckRuneRange('0', '9'))}),
+	},
+}
+
+func ExampleUnmarshal() {
+	p, _ := ast.New([]byte("a=1,b=23"))
+	n, _ := p.Expect(config)
+
+	var c struct {
+		Pairs []struct {
+			Key   string
+			Value int `ast:"Number"`
+		} `ast:"Pair"`
+	}
+	fmt.Println(ast.Unmarshal(n, &c))
+	fmt.Println(c)
+	// Output:
+	// <nil>
+	// {[{a 1} {b 23}]}
+}
+
+// upper is a string that unmarshals itself.
+type upper string
+
+func (u *upper) UnmarshalNode(n *ast.Node) error {
+	*u = upper(strings.ToUpper(n.Value))
+	return nil
+}
+
+func TestUnmarshal(t *testing.T) {
+	p, _ := ast.New([]byte("a=1,b=2,c=345"))
+	n, _ := p.Expect(config)
+
+	var c struct {
+		Node  *ast.Node `ast:",value"`
+		First *struct {
+			Key     upper
+			Numbers []uint8 `ast:"Number"`
+			Node    *ast.Node
+		} `ast:"Pair"`
+		Pairs   []ast.Node `ast:"Pair"`
+		Ignored string     `ast:"-"`
+		ignored string
+	}
+	if err := ast.Unmarshal(n, &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Node != n {
+		t.Error("expected the node itself")
+	}
+	if c.First == nil || c.First.Key != "A" || len(c.First.Numbers) != 1 || c.First.Numbers[0] != 1 {
+		t.Errorf("unexpected first pair %v", c.First)
+	}
+	if c.First.Node != nil {
+		t.Error("no children should match")
+	}
+	if len(c.Pairs) != 3 {
+		t.Errorf("expected 3 pairs, got %d", len(c.Pairs))
+	}
+
+	var numbers []struct {
+		Numbers []uint8 `ast:"Number"`
+	}
+	err := ast.Unmarshal(n, &numbers)
+	var unmarshalErr *ast.UnmarshalError
+	if !errors.As(err, &unmarshalErr) || !errors.Is(err, strconv.ErrRange) {
+		t.Fatalf("expected a range error, got %v", err)
+	}
+	if s := err.Error(); !strings.HasPrefix(s, "ast: can not unmarshal Number [00:010] into uint8") {
+		t.Error(s)
+	}
+
+	for _, v := range []interface{}{nil, numbers, (*int)(nil)} {
+		if err := ast.Unmarshal(n, v); err == nil {
+			t.Errorf("%T: expected an error", v)
+		}
+	}
+	var m map[string]string
+	if err := ast.Unmarshal(n, &m); err == nil {
+		t.Error("expected an error for unsupported types")
+	}
+}
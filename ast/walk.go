@@ -0,0 +1,66 @@
+package ast
+
+// Action indicates how Walk continues after visiting a node.
+type Action int
+
+const (
+	// Continue visits the children of the node and its following siblings.
+	Continue Action = iota
+	// SkipChildren does not visit the children of the node. It is the same as
+	// Continue after the children were visited.
+	SkipChildren
+	// Stop stops the walk.
+	Stop
+)
+
+// Visitor contains the functions that are called by Walk. Both are optional.
+type Visitor struct {
+	// Pre is called for every node before its children are visited.
+	Pre func(n *Node) Action
+	// Post is called for every node after its children are visited.
+	Post func(n *Node) Action
+}
+
+// Walk traverses the tree of the given node in depth-first order. The current
+// node can be removed or replaced by the visitor, the walk continues with the
+// sibling that followed it. Returns false if the walk was stopped.
+func Walk(n *Node, v Visitor) bool {
+	if n == nil {
+		return true
+	}
+	action := Continue
+	if v.Pre != nil {
+		action = v.Pre(n)
+	}
+	switch action {
+	case Stop:
+		return false
+	case Continue:
+		for c := n.FirstChild; c != nil; {
+			next := c.NextSibling
+			if !Walk(c, v) {
+				return false
+			}
+			c = next
+		}
+	}
+	if v.Post != nil {
+		return v.Post(n) != Stop
+	}
+	return true
+}
+
+// Inspect traverses the tree of the given node in depth-first order, the same
+// as go/ast.Inspect. The children of a node are only visited if the given
+// function returns true, followed by a call with nil.
+func Inspect(n *Node, f func(n *Node) bool) {
+	if n == nil || !f(n) {
+		return
+	}
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		Inspect(c, f)
+		c = next
+	}
+	f(nil)
+}
@@ -0,0 +1,94 @@
+package ast_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/di-wu/parser/ast"
+)
+
+func ExampleWalk() {
+	r := tree("r", tree("a", tree("b")), tree("c"))
+
+	var depth int
+	ast.Walk(r, ast.Visitor{
+		Pre: func(n *ast.Node) ast.Action {
+			fmt.Printf("%s%s\n", strings.Repeat("  ", depth), n.Value)
+			depth++
+			return ast.Continue
+		},
+		Post: func(n *ast.Node) ast.Action {
+			depth--
+			return ast.Continue
+		},
+	})
+	// Output:
+	// r
+	//   a
+	//     b
+	//   c
+}
+
+func ExampleInspect() {
+	r := tree("r", tree("a", tree("b")), tree("c"))
+
+	ast.Inspect(r, func(n *ast.Node) bool {
+		if n == nil {
+			// All children of the node are visited.
+			fmt.Print(") ")
+			return true
+		}
+		fmt.Print("(", n.Value, " ")
+		return n.Value != "a"
+	})
+	// Output:
+	// (r (a (c ) )
+}
+
+func TestWalk(t *testing.T) {
+	var visited []string
+	visit := func(n *ast.Node) ast.Action {
+		visited = append(visited, n.Value)
+		if n.Value == "c" {
+			return ast.Stop
+		}
+		return ast.Continue
+	}
+	r := tree("r", tree("a", tree("b")), tree("c"), tree("d"))
+	if ast.Walk(r, ast.Visitor{Pre: visit}) {
+		t.Error("expected the walk to stop")
+	}
+	if s := strings.Join(visited, " "); s != "r a b c" {
+		t.Error(s)
+	}
+
+	visited = nil
+	if ast.Walk(r, ast.Visitor{Post: visit}) {
+		t.Error("expected the walk to stop")
+	}
+	if s := strings.Join(visited, " "); s != "b a c" {
+		t.Error(s)
+	}
+}
+
+func TestWalk_remove(t *testing.T) {
+	r := tree("r", tree("a"), tree("x", tree("y")), tree("x"), tree("b"))
+	var visited []string
+	ast.Walk(r, ast.Visitor{
+		Pre: func(n *ast.Node) ast.Action {
+			visited = append(visited, n.Value)
+			if n.Value == "x" {
+				n.Remove()
+				return ast.SkipChildren
+			}
+			return ast.Continue
+		},
+	})
+	if s := sexpr(t, r); s != "(r a b)" {
+		t.Error(s)
+	}
+	if s := strings.Join(visited, " "); s != "r a x x b" {
+		t.Error(s)
+	}
+}
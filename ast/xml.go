@@ -0,0 +1,90 @@
+package ast
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// MarshalXML encodes the node as an element named after its type, e.g.
+//
+//	<Add type="1" start="0" end="3"><Int type="0" start="0" end="1">1</Int>...</Add>
+//
+// The value of the node is its character data. Nodes of which the type has no
+// name, or a name that is not a valid XML name, are encoded as "node" elements.
+func (n *Node) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	start := xml.StartElement{Name: xml.Name{Local: "node"}}
+	if name, ok := TypeName(n.Type, n.TypeStrings); ok && isXMLName(name) {
+		start.Name.Local = name
+	} else if n.Type == ErrorType {
+		start.Name.Local = "ERROR"
+	}
+	attr := func(name, value string) {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+	}
+	attr("type", strconv.Itoa(n.Type))
+	if n.Start != nil {
+		attr("start", strconv.Itoa(n.Start.Offset()))
+	}
+	if n.End != nil {
+		attr("end", strconv.Itoa(n.End.Offset()))
+	}
+	if n.Error != nil {
+		attr("error", n.Error.Error())
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if !n.IsParent() && n.Value != "" {
+		if err := e.EncodeToken(xml.CharData(n.Value)); err != nil {
+			return err
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if err := e.Encode(c); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// isXMLName returns whether the given name can be used as the name of an
+// element. Names starting with "xml" are reserved.
+func isXMLName(name string) bool {
+	if name == "" || strings.HasPrefix(strings.ToLower(name), "xml") {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_' || unicode.IsLetter(r):
+		case 0 < i && (r == '-' || r == '.' || unicode.IsDigit(r)):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// SExpr returns the node as an s-expression, e.g. (Add (Int "1") (Int "2")).
+// Values are quoted the same as Go strings.
+func (n *Node) SExpr() string {
+	var b strings.Builder
+	n.sexpr(&b)
+	return b.String()
+}
+
+func (n *Node) sexpr(b *strings.Builder) {
+	b.WriteString("(")
+	b.WriteString(n.TypeString())
+	if !n.IsParent() {
+		b.WriteString(" ")
+		b.WriteString(strconv.Quote(n.Value))
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(" ")
+		c.sexpr(b)
+	}
+	b.WriteString(")")
+}
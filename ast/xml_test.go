@@ -0,0 +1,61 @@
+package ast_test
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/di-wu/parser/ast"
+)
+
+func ExampleNode_MarshalXML() {
+	p, _ := ast.New([]byte("1+2"))
+	n, _ := p.Expect(expression)
+	data, _ := xml.MarshalIndent(n, "", "  ")
+	fmt.Println(string(data))
+	// Output:
+	// <Add type="1" start="0" end="3">
+	//   <Int type="0" start="0" end="1">1</Int>
+	//   <Int type="0" start="2" end="3">2</Int>
+	// </Add>
+}
+
+func ExampleNode_SExpr() {
+	p, _ := ast.New([]byte("-1+2*3"))
+	n, _ := p.Expect(expression)
+	fmt.Println(n.SExpr())
+	// Output:
+	// (Add (Neg (Int "1")) (Mul (Int "2") (Int "3")))
+}
+
+func TestNode_MarshalXML(t *testing.T) {
+	for _, test := range []struct {
+		node *ast.Node
+		xml  string
+	}{
+		{node: &ast.Node{Type: -1, Value: "<&>"}, xml: `<node type="-1">&lt;&amp;&gt;</node>`},
+		{node: &ast.Node{Type: 0, TypeStrings: []string{"a b"}}, xml: `<node type="0"></node>`},
+		{node: &ast.Node{Type: 0, TypeStrings: []string{"xmlNode"}}, xml: `<node type="0"></node>`},
+		{
+			node: &ast.Node{Type: ast.ErrorType, Value: "x", Error: errors.New(`"oops"`)},
+			xml:  `<ERROR type="-2" error="&#34;oops&#34;">x</ERROR>`,
+		},
+		{node: tree("r", tree("a"), tree("b")), xml: `<node type="0"><node type="0">a</node><node type="0">b</node></node>`},
+	} {
+		data, err := xml.Marshal(test.node)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := string(data); s != test.xml {
+			t.Errorf("expected %s, got %s", test.xml, s)
+		}
+	}
+}
+
+func TestNode_SExpr(t *testing.T) {
+	n := tree("r", tree("a\n"), tree(`"b"`, tree("c")))
+	if s := n.SExpr(); s != `(UNKNOWN (UNKNOWN "a\n") (UNKNOWN (UNKNOWN "c")))` {
+		t.Error(s)
+	}
+}
@@ -0,0 +1,70 @@
+package parser
+
+// Balanced is a Class that matches a region that is enclosed by one of the given
+// pairs of delimiters, including any nested pairs, e.g. "{ a(b) { c } }". The
+// region does not match if a delimiter is not closed, or closed by the wrong
+// delimiter. Pairs with the same opening and closing delimiter (e.g. {'|', '|'})
+// can not be nested directly, the second delimiter closes the first one.
+type Balanced struct {
+	// Pairs contains the opening and closing delimiters, e.g. {'{', '}'}.
+	Pairs [][2]rune
+	// Skip is matched as a whole within the region, delimiters within it are
+	// ignored. e.g. DoubleQuoted to skip string literals like "}".
+	Skip interface{}
+}
+
+func (b Balanced) Check(p *Parser) (*Cursor, bool) {
+	closing, ok := b.closing(p.Current())
+	if !ok {
+		return nil, false
+	}
+	stack := []rune{closing}
+	last := p.Mark()
+	p.Next()
+	for !p.Done() {
+		if b.Skip != nil {
+			offset := p.Offset()
+			if mark, ok := p.Check(b.Skip); ok && p.Offset() != offset {
+				if mark != nil {
+					last = mark
+				}
+				continue
+			}
+		}
+		r := p.Current()
+		if r == stack[len(stack)-1] {
+			stack = stack[:len(stack)-1]
+		} else if closing, ok := b.closing(r); ok {
+			stack = append(stack, closing)
+		} else if b.closes(r) {
+			// Closed by the wrong delimiter.
+			return last, false
+		}
+		last = p.Mark()
+		if len(stack) == 0 {
+			return last, true
+		}
+		p.Next()
+	}
+	return last, false
+}
+
+// closing returns the closing delimiter of the given opening delimiter.
+func (b Balanced) closing(open rune) (rune, bool) {
+	for _, pair := range b.Pairs {
+		if pair[0] == open {
+			return pair[1], true
+		}
+	}
+	return 0, false
+}
+
+// closes checks whether the given rune is a closing delimiter.
+func (b Balanced) closes(r rune) bool {
+	for _, pair := range b.Pairs {
+		if pair[1] == r {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package parser_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+	"testing"
+)
+
+func ExampleBalanced() {
+	block := parser.Balanced{
+		Pairs: [][2]rune{{'{', '}'}, {'(', ')'}},
+		Skip:  parser.DoubleQuoted,
+	}
+
+	p, _ := parser.New([]byte(`{ f("}") { g() } } rest`))
+	start := p.Mark()
+	last, err := p.Expect(block)
+	fmt.Println(p.Slice(start, last), err)
+	// Output:
+	// { f("}") { g() } } <nil>
+}
+
+func ExampleBalanced_unbalanced() {
+	block := parser.Balanced{
+		Pairs: [][2]rune{{'{', '}'}, {'[', ']'}},
+		Skip:  parser.DoubleQuoted,
+	}
+
+	// Mismatched, unclosed and unopened delimiters. The quoted delimiter is
+	// skipped, so the block is not closed.
+	for _, input := range []string{"{[}]", "{{}", "}", `{"}"`} {
+		p, _ := parser.New([]byte(input))
+		_, err := p.Expect(block)
+		fmt.Println(err)
+	}
+	// Output:
+	// parse conflict [00:002]: expected parser.AnonymousClass parser.Class.Check but got "{[}"
+	// parse conflict [00:003]: expected parser.AnonymousClass parser.Class.Check but got "{{}"
+	// parse conflict [00:001]: expected parser.AnonymousClass parser.Class.Check but got '}'
+	// parse conflict [00:004]: expected parser.AnonymousClass parser.Class.Check but got "{\"}\""
+}
+
+func TestBalanced_sameDelimiters(t *testing.T) {
+	block := parser.Balanced{
+		Pairs: [][2]rune{{'|', '|'}, {'(', ')'}},
+		Skip:  op.MinZero(' '),
+	}
+	p, _ := parser.New([]byte("|a (b|c|)| d"))
+	if _, ok := p.Check(block); !ok || p.Remaining() != " d" {
+		t.Errorf("expected the region to be closed, got %q", p.Remaining())
+	}
+	p, _ = parser.New([]byte("|a (b|c)| d"))
+	if _, ok := p.Check(block); ok {
+		t.Error("expected the region to be closed by the wrong delimiter")
+	}
+}
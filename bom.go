@@ -0,0 +1,169 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Byte order marks.
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+)
+
+// WithBOM makes the parser detect a byte order mark (BOM) at the start of the
+// input. The BOM gets stripped and UTF-16 encoded input gets transcoded to
+// UTF-8. Note that all positions are relative to the (transcoded) input after
+// the BOM.
+func WithBOM() Option {
+	return func(p *Parser) {
+		p.bom = true
+	}
+}
+
+// stripBOM strips the byte order mark from the buffer of the parser.
+func (p *Parser) stripBOM() {
+	switch {
+	case bytes.HasPrefix(p.buffer, bomUTF8):
+		p.buffer = p.buffer[len(bomUTF8):]
+		if p.text != "" {
+			p.text = p.text[len(bomUTF8):]
+		}
+	case bytes.HasPrefix(p.buffer, bomUTF16BE):
+		p.buffer = transcodeUTF16(p.buffer[len(bomUTF16BE):], binary.BigEndian)
+		p.text = ""
+	case bytes.HasPrefix(p.buffer, bomUTF16LE):
+		p.buffer = transcodeUTF16(p.buffer[len(bomUTF16LE):], binary.LittleEndian)
+		p.text = ""
+	}
+}
+
+// transcodeUTF16 converts the given UTF-16 encoded bytes to UTF-8. A trailing
+// odd byte gets replaced by utf8.RuneError.
+func transcodeUTF16(b []byte, order binary.ByteOrder) []byte {
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = order.Uint16(b[2*i:])
+	}
+	runes := utf16.Decode(units)
+	if len(b)%2 != 0 {
+		runes = append(runes, utf8.RuneError)
+	}
+
+	var (
+		buffer  = make([]byte, 0, len(runes))
+		scratch [utf8.UTFMax]byte
+	)
+	for _, r := range runes {
+		n := utf8.EncodeRune(scratch[:], r)
+		buffer = append(buffer, scratch[:n]...)
+	}
+	return buffer
+}
+
+// stripReaderBOM strips the byte order mark from the given reader. UTF-16
+// encoded data gets transcoded to UTF-8 while reading.
+func stripReaderBOM(r io.Reader) io.Reader {
+	br := bufio.NewReader(r)
+	b, _ := br.Peek(len(bomUTF8))
+	switch {
+	case bytes.HasPrefix(b, bomUTF8):
+		_, _ = br.Discard(len(bomUTF8))
+	case bytes.HasPrefix(b, bomUTF16BE):
+		_, _ = br.Discard(len(bomUTF16BE))
+		return &utf16Reader{reader: br, order: binary.BigEndian}
+	case bytes.HasPrefix(b, bomUTF16LE):
+		_, _ = br.Discard(len(bomUTF16LE))
+		return &utf16Reader{reader: br, order: binary.LittleEndian}
+	}
+	return br
+}
+
+// utf16Reader transcodes UTF-16 encoded data to UTF-8.
+type utf16Reader struct {
+	reader io.Reader
+	order  binary.ByteOrder
+	err    error
+
+	// unit is a code unit that was read, but not decoded yet.
+	unit    rune
+	hasUnit bool
+	// pending contains the encoded bytes that are not read yet.
+	pending []byte
+	scratch [utf8.UTFMax]byte
+}
+
+func (r *utf16Reader) Read(p []byte) (int, error) {
+	var n int
+	for n < len(p) {
+		if len(r.pending) == 0 {
+			if r.err != nil && !r.hasUnit {
+				break
+			}
+			current, err := r.readRune()
+			if err != nil {
+				r.err = err
+				break
+			}
+			r.pending = r.scratch[:utf8.EncodeRune(r.scratch[:], current)]
+		}
+		c := copy(p[n:], r.pending)
+		r.pending = r.pending[c:]
+		n += c
+	}
+	if n != 0 {
+		return n, nil
+	}
+	return 0, r.err
+}
+
+// readRune reads the next rune, combining surrogate pairs.
+func (r *utf16Reader) readRune() (rune, error) {
+	first, err := r.readUnit()
+	if err != nil {
+		return 0, err
+	}
+	if r.err != nil {
+		// Reached the end while reading the unit.
+		return first, nil
+	}
+	if !utf16.IsSurrogate(first) {
+		return first, nil
+	}
+
+	second, err := r.readUnit()
+	if err != nil {
+		// Unpaired surrogate at the end of the data.
+		r.err = err
+		return utf8.RuneError, nil
+	}
+	if current := utf16.DecodeRune(first, second); current != utf8.RuneError {
+		return current, nil
+	}
+	// Not a valid pair, decode the second unit on its own next time.
+	r.unit, r.hasUnit = second, true
+	return utf8.RuneError, nil
+}
+
+// readUnit reads a single UTF-16 code unit.
+func (r *utf16Reader) readUnit() (rune, error) {
+	if r.hasUnit {
+		r.hasUnit = false
+		return r.unit, nil
+	}
+	var b [2]byte
+	if _, err := io.ReadFull(r.reader, b[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			// Trailing odd byte.
+			r.err = io.EOF
+			return utf8.RuneError, nil
+		}
+		return 0, err
+	}
+	return rune(r.order.Uint16(b[:])), nil
+}
@@ -0,0 +1,57 @@
+package parser_test
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/di-wu/parser"
+	"testing"
+)
+
+func ExampleWithBOM() {
+	p, _ := parser.New([]byte("\xEF\xBB\xBFabc"), parser.WithBOM())
+	fmt.Println(p.Expect("abc"))
+
+	// UTF-16 (little endian) encoded "abc".
+	p, _ = parser.New([]byte("\xFF\xFEa\x00b\x00c\x00"), parser.WithBOM())
+	fmt.Println(p.Expect("abc"))
+	// Output:
+	// U+0063: c <nil>
+	// U+0063: c <nil>
+}
+
+func TestWithBOM(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		input []byte
+	}{
+		{"UTF-8", []byte("\xEF\xBB\xBFa😀c")},
+		{"UTF-16BE", []byte("\xFE\xFF\x00a\xD8\x3D\xDE\x00\x00c")},
+		{"UTF-16LE", []byte("\xFF\xFEa\x00\x3D\xD8\x00\xDEc\x00")},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := parser.New(test.input, parser.WithBOM())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := p.Expect("a😀c"); err != nil {
+				t.Error(err)
+			}
+
+			p, err = parser.NewReader(bytes.NewReader(test.input), 8, parser.WithBOM())
+			if err != nil {
+				t.Fatal(err)
+			}
+			start := p.Mark()
+			end, err := p.Expect("a😀c")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := p.Slice(start, end); s != "a😀c" {
+				t.Error(s)
+			}
+			if !p.Next().Done() {
+				t.Error(p.Current())
+			}
+		})
+	}
+}
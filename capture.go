@@ -0,0 +1,30 @@
+package parser
+
+// captured is an immutable list of named captures. Cursors keep a reference to
+// the list, so jumping to a mark restores the captures of that mark.
+type captured struct {
+	name, text string
+	parent     *captured
+}
+
+// capture stores the given text under the given name, see op.Capture. The
+// captures are part of the position of the parser, the same as the user state
+// stack.
+func (p *Parser) capture(name, text string) {
+	p.cursor.captures = &captured{
+		name:   name,
+		text:   text,
+		parent: p.cursor.captures,
+	}
+}
+
+// captured returns the text that was most recently captured under the given
+// name, see op.Backref.
+func (p *Parser) captured(name string) (string, bool) {
+	for c := p.cursor.captures; c != nil; c = c.parent {
+		if c.name == name {
+			return c.text, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,34 @@
+package parser_test
+
+import (
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+	"testing"
+)
+
+func TestParser_Expect_captured(t *testing.T) {
+	p, _ := parser.New([]byte("ab"))
+	if _, err := p.Expect(op.Backref{Name: "x"}); err == nil {
+		t.Error("nothing is captured yet")
+	}
+
+	// Captures of values that get backtracked are discarded.
+	if _, err := p.Expect(op.Or{op.And{op.Capture{Name: "x", Value: 'a'}, 'c'}, 'a'}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Expect(op.Backref{Name: "x"}); err == nil {
+		t.Error("capture was not discarded")
+	}
+
+	// Captures are kept by the enclosing values.
+	_ = p.Reset([]byte("abab"))
+	if _, err := p.Expect(op.And{op.Capture{Name: "x", Value: 'a'}, op.Capture{Name: "y", Value: op.Optional('c')}, 'b'}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Expect(op.And{op.Backref{Name: "y"}, op.Backref{Name: "x"}}); err != nil {
+		t.Error(err)
+	}
+	if p.Current() != 'b' {
+		t.Errorf("expected b, got %q", p.Current())
+	}
+}
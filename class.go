@@ -94,6 +94,33 @@ func CheckRuneCI(expected rune) AnonymousClass {
 	})
 }
 
+// CheckNotRune returns an AnonymousClass that checks whether the current rune of
+// the parser does NOT match the given rune. It never matches EOD.
+func CheckNotRune(r rune) AnonymousClass {
+	return CheckRuneFunc(func(actual rune) bool {
+		return actual != r && actual != EOD
+	})
+}
+
+// Not returns an AnonymousClass that consumes a single rune if the given value
+// does NOT match at the current position. It never matches EOD.
+//
+// e.g. Not("*/") matches every rune that does not start the end of a comment.
+func Not(i interface{}) AnonymousClass {
+	return func(p *Parser) (*Cursor, bool) {
+		if p.Done() {
+			return nil, false
+		}
+		mark := p.Mark()
+		_, ok := p.Check(i)
+		p.Jump(mark)
+		if ok {
+			return nil, false
+		}
+		return mark, true
+	}
+}
+
 // CheckRuneRange returns an AnonymousClass that checks whether the current rune of
 // the parser is inside the given range (inclusive).
 func CheckRuneRange(min, max rune) AnonymousClass {
@@ -110,6 +137,26 @@ func CheckRuneFunc(f func(r rune) bool) AnonymousClass {
 	}
 }
 
+// CheckRangeTable returns an AnonymousClass that checks whether the current rune
+// of the parser is in one of the given range tables.
+func CheckRangeTable(tables ...*unicode.RangeTable) AnonymousClass {
+	return CheckRuneFunc(func(r rune) bool {
+		return unicode.IsOneOf(tables, r)
+	})
+}
+
+// Predefined classes based on Unicode categories.
+var (
+	// Letter matches any Unicode letter (category L).
+	Letter = CheckRangeTable(unicode.Letter)
+	// Digit matches any Unicode decimal digit (category Nd).
+	Digit = CheckRangeTable(unicode.Digit)
+	// Space matches any Unicode white space character.
+	Space = CheckRuneFunc(unicode.IsSpace)
+	// Punct matches any Unicode punctuation character (category P).
+	Punct = CheckRangeTable(unicode.Punct)
+)
+
 // CheckString returns an AnonymousClass that checks whether the current
 // sequence runes of the parser matches the given string. The same result can be
 // achieved by using p.Expect(s). Where 'p' is a reference to the parser an 's'
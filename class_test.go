@@ -3,8 +3,10 @@ package parser_test
 import (
 	"fmt"
 	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
 	"strconv"
 	"testing"
+	"unicode"
 )
 
 func ExampleCheckRuneCI() {
@@ -113,3 +115,41 @@ func ExampleAnonymousClass_error() {
 	// Output:
 	// U+0030: 0 false
 }
+
+func ExampleCheckRangeTable() {
+	p, _ := parser.New([]byte("Ωμέγα ٣, x"))
+	greek := parser.CheckRangeTable(unicode.Greek)
+
+	fmt.Println(p.Expect(op.MinOne(greek)))
+	fmt.Println(p.Expect(parser.Space, parser.Digit, parser.Punct, parser.Space))
+	fmt.Println(p.Expect(parser.Letter))
+	// Output:
+	// U+03B1: α <nil>
+	// U+0020:   <nil>
+	// U+0078: x <nil>
+}
+
+func ExampleCheckNoneOf() {
+	p, _ := parser.New([]byte(`"a\"b"`))
+	body := op.MinZero(op.Or{
+		parser.CheckNoneOf("\"\\\n"),
+		op.And{'\\', parser.CheckNotRune('\n')},
+	})
+
+	fmt.Println(p.Expect('"', body, '"'))
+	fmt.Println(p.Done())
+	// Output:
+	// U+0022: " <nil>
+	// true
+}
+
+func ExampleNot() {
+	p, _ := parser.New([]byte("/* a * b */"))
+	comment := op.And{"/*", op.MinZero(parser.Not("*/")), "*/"}
+
+	fmt.Println(p.Expect(comment))
+	fmt.Println(p.Check(parser.Not('x')))
+	// Output:
+	// U+002F: / <nil>
+	// <nil> false
+}
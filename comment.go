@@ -0,0 +1,55 @@
+package parser
+
+// CheckLineComment returns an AnonymousClass that matches a comment that starts
+// with the given prefix (e.g. "//", "#" or "--") and ends at the end of the
+// line. The line ending is not part of the comment.
+func CheckLineComment(prefix string) AnonymousClass {
+	start := CheckString(prefix)
+	return func(p *Parser) (*Cursor, bool) {
+		last, ok := start(p)
+		if !ok {
+			return last, false
+		}
+		for !p.Done() && p.Current() != '\n' && p.Current() != '\r' {
+			last = p.Mark()
+			p.Next()
+		}
+		return last, true
+	}
+}
+
+// CheckBlockComment returns an AnonymousClass that matches a comment between the
+// given delimiters, e.g. "/*" and "*/" or "(*" and "*)". If nested, comments
+// within the comment need to be closed as well, e.g. "(* a (* b *) c *)".
+func CheckBlockComment(open, close string, nested bool) AnonymousClass {
+	opening, closing := CheckString(open), CheckString(close)
+	return func(p *Parser) (*Cursor, bool) {
+		last, ok := opening(p)
+		if !ok {
+			return last, false
+		}
+		for depth := 1; !p.Done(); {
+			start := p.MarkV()
+			if mark, ok := closing(p); ok {
+				last = mark
+				if depth--; depth == 0 {
+					return last, true
+				}
+				continue
+			}
+			p.Jump(&start)
+			if nested {
+				if mark, ok := opening(p); ok {
+					last = mark
+					depth++
+					continue
+				}
+				p.Jump(&start)
+			}
+			last = p.Mark()
+			p.Next()
+		}
+		// Not closed.
+		return last, false
+	}
+}
@@ -0,0 +1,59 @@
+package parser_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+	"testing"
+)
+
+func ExampleCheckLineComment() {
+	trivia := op.Or{' ', '\n', parser.CheckLineComment("//"), parser.CheckBlockComment("/*", "*/", false)}
+
+	p, _ := parser.New([]byte("a // first\n/* second */ b"), parser.WithTrivia(trivia))
+	fmt.Println(p.Expect('a'))
+	fmt.Println(p.Expect('b'))
+	// Output:
+	// U+0061: a <nil>
+	// U+0062: b <nil>
+}
+
+func ExampleCheckLineComment_lineEnding() {
+	// The line ending is not part of the comment.
+	for _, input := range []string{"# a\nb", "# a\r\nb", "#"} {
+		p, _ := parser.New([]byte(input))
+		_, err := p.Expect(parser.CheckLineComment("#"))
+		fmt.Printf("%q %v\n", p.Remaining(), err)
+	}
+	// Output:
+	// "\nb" <nil>
+	// "\r\nb" <nil>
+	// "" <nil>
+}
+
+func ExampleCheckBlockComment() {
+	for _, nested := range []bool{false, true} {
+		p, _ := parser.New([]byte("(* (* a *) b *) c"))
+		_, err := p.Expect(parser.CheckBlockComment("(*", "*)", nested))
+		fmt.Printf("%q %v\n", p.Remaining(), err)
+	}
+	// Output:
+	// " b *) c" <nil>
+	// " c" <nil>
+}
+
+func TestCheckBlockComment_unclosed(t *testing.T) {
+	for _, nested := range []bool{false, true} {
+		// The '*' of the opening delimiter does not close "(*)".
+		for _, input := range []string{"(* a", "(*)", "(* (* a *"} {
+			p, _ := parser.New([]byte(input))
+			if _, ok := p.Check(parser.CheckBlockComment("(*", "*)", nested)); ok {
+				t.Errorf("%q: expected the comment to be unclosed", input)
+			}
+		}
+	}
+	p, _ := parser.New([]byte("(* (* a *)"))
+	if _, ok := p.Check(parser.CheckBlockComment("(*", "*)", true)); ok {
+		t.Error("expected the outer comment to be unclosed")
+	}
+}
@@ -0,0 +1,207 @@
+package parser
+
+import "github.com/di-wu/parser/op"
+
+// Compiled is a value that got lowered into a chain of closures by Compile.
+// It can be passed to Parser.Expect like any other value.
+type Compiled struct {
+	value interface{}
+	match compiled
+}
+
+// compiled matches a compiled value, it enters and leaves the value itself.
+type compiled func(p *Parser) (*Cursor, error)
+
+// Compile lowers the given value into a chain of closures once, so that the
+// value does not need to be inspected again every time it is expected. This
+// is useful for values that are expected repeatedly, e.g. in a loop.
+//
+// Sequences, alternatives, repetitions, lookaheads and the basic values (runes,
+// strings, tries and classes) are compiled, all other values are expected as
+// usual. A compiled value matches exactly the same as the original value. The
+// original value is expected instead if the parser has a converter or an
+// operator, since these can change every value.
+//
+// Values of the ast package can not be compiled, compiled values can be used in
+// them but do not produce any nodes.
+func Compile(i interface{}) *Compiled {
+	return &Compiled{
+		value: i,
+		match: compile(i),
+	}
+}
+
+// Value returns the value that was compiled.
+func (c *Compiled) Value() interface{} {
+	return c.value
+}
+
+func (c *Compiled) String() string {
+	return op.String(c.value)
+}
+
+// interpreted returns whether compiled values should be expected as their
+// original value, because the parser can change values while expecting them.
+func (p *Parser) interpreted() bool {
+	return p.converter != nil || p.operator != nil
+}
+
+// compile lowers the given value into a closure.
+func compile(i interface{}) compiled {
+	// Values are entered as given, the same as Parser.Expect does.
+	switch v := ConvertAliases(i).(type) {
+	case *Compiled:
+		return v.match
+	case rune, string, *op.Trie, AnonymousClass:
+		return enclose(i, func(p *Parser) (*Cursor, error) {
+			return p.match(v)
+		})
+
+	case op.Not:
+		value := compile(v.Value)
+		return enclose(i, func(p *Parser) (*Cursor, error) {
+			start := p.Mark()
+			defer p.Jump(start)
+			if last, err := value(p); err == nil {
+				return nil, p.ExpectedParseError(v, start, last)
+			}
+			return nil, nil
+		})
+	case op.Ensure:
+		value := compile(v.Value)
+		return enclose(i, func(p *Parser) (*Cursor, error) {
+			start, scans := p.Mark(), len(p.scans)
+			if last, err := value(p); err != nil {
+				err, _ = cutError(err)
+				return last, err
+			}
+			p.Jump(start)
+			// Nothing is consumed, so the scanned values are not stored either.
+			p.scans = p.scans[:scans]
+			return nil, nil
+		})
+
+	case op.And:
+		values := make([]compiled, len(v))
+		for n, value := range v {
+			if _, ok := value.(op.Cut); ok {
+				// A nil value marks the position of the cut.
+				continue
+			}
+			values[n] = compile(value)
+		}
+		return enclose(i, func(p *Parser) (*Cursor, error) {
+			state := state{p: p}
+			start := p.Mark()
+			var (
+				last *Cursor
+				cut  bool
+			)
+			for _, value := range values {
+				if value == nil {
+					cut = true
+					continue
+				}
+				mark, err := value(p)
+				if err != nil {
+					if _, ok := cutError(err); cut || ok {
+						p.Jump(start)
+						return nil, p.cutParseError(err)
+					}
+					if last == nil {
+						last = start
+					}
+					return nil, p.FarthestParseError(v, start, p.Jump(last).Peek())
+				}
+				if mark != nil {
+					last = mark
+				}
+			}
+			state.Ok(last)
+			return state.End(), nil
+		})
+	case op.Or:
+		values := compileAll(v)
+		return enclose(i, func(p *Parser) (*Cursor, error) {
+			state := state{p: p}
+			start := p.Mark()
+			var last *Cursor
+			for _, value := range values {
+				mark, err := value(p)
+				if err == nil {
+					last = mark
+					break
+				}
+				if err, ok := cutError(err); ok {
+					return nil, err
+				}
+			}
+			if last == nil {
+				return nil, p.FarthestParseError(v, start, start)
+			}
+			state.Ok(last)
+			return state.End(), nil
+		})
+
+	case op.Range:
+		value := compile(v.Value)
+		return enclose(i, func(p *Parser) (*Cursor, error) {
+			state := state{p: p}
+			start := p.Mark()
+			var (
+				count int
+				last  *Cursor
+			)
+			for {
+				offset := p.cursor.position
+				mark, err := value(p)
+				if err != nil {
+					break
+				}
+				if mark != nil {
+					last = mark
+				}
+				count++
+
+				if p.cursor.position == offset {
+					if err := p.noProgress(v); err != nil {
+						return nil, err
+					}
+				}
+				if v.Max != -1 && count == v.Max {
+					break
+				}
+			}
+			if count < v.Min {
+				return nil, p.ExpectedParseError(v, start, last)
+			}
+			state.Ok(last)
+			return state.End(), nil
+		})
+
+	default:
+		return func(p *Parser) (*Cursor, error) {
+			return p.Expect(v)
+		}
+	}
+}
+
+// compileAll compiles all the given values.
+func compileAll(values []interface{}) []compiled {
+	cs := make([]compiled, len(values))
+	for i, value := range values {
+		cs[i] = compile(value)
+	}
+	return cs
+}
+
+// enclose makes sure the given value gets entered and left around the given
+// closure, the same way as Parser.Expect does.
+func enclose(i interface{}, f compiled) compiled {
+	match := func(p *Parser, _ interface{}) (*Cursor, error) {
+		return f(p)
+	}
+	return func(p *Parser) (*Cursor, error) {
+		return p.run(i, match)
+	}
+}
@@ -0,0 +1,128 @@
+package parser_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+)
+
+func ExampleCompile() {
+	digit := parser.CheckRuneRange('0', '9')
+	number := parser.Compile(op.And{
+		op.Optional('-'),
+		op.MinOne(digit),
+		op.Optional(op.And{'.', op.MinOne(digit)}),
+	})
+
+	p, _ := parser.New([]byte("-3.14 42 x"))
+	for !p.Done() {
+		mark, err := p.Expect(number)
+		if err != nil {
+			fmt.Println(err)
+			break
+		}
+		fmt.Printf("%U: %c\n", mark.Rune, mark.Rune)
+		p.Expect(op.Optional(' '))
+	}
+	// Output:
+	// U+0034: 4
+	// U+0032: 2
+	// parse conflict [00:010]: expected op.And '-'? func+ ('.' func+)? but got 'x'
+}
+
+func TestCompile(t *testing.T) {
+	digit := parser.CheckRuneRange('0', '9')
+	letter := parser.CheckRuneRange('a', 'z')
+	for _, test := range []struct {
+		value  interface{}
+		inputs []string
+	}{
+		{
+			value:  op.And{"if", ' ', op.Cut{}, digit, ';'},
+			inputs: []string{"if 1;", "if x;", "iff;", "if 1"},
+		},
+		{
+			value:  op.Or{op.And{"0x", op.MinOne(digit)}, op.MinOne(digit), op.Fail{Message: "number"}},
+			inputs: []string{"0x12", "012", "x"},
+		},
+		{
+			value:  op.MinMax(2, 3, op.Or{letter, '_'}),
+			inputs: []string{"a", "a_b", "abcd", "1"},
+		},
+		{
+			value:  op.And{op.Not{Value: "end"}, op.MinOne(letter), op.Ensure{Value: ';'}},
+			inputs: []string{"end;", "ends;", "abc", "abc;"},
+		},
+		{
+			value:  []interface{}{op.AnyString("a", "ab", "abc"), 'c', op.MinZero(' ')},
+			inputs: []string{"abc", "abcc  ", "ac", "b"},
+		},
+		{
+			value:  op.MinMax(0, 3, op.Optional('a')),
+			inputs: []string{"aa", "b"},
+		},
+	} {
+		compiled := parser.Compile(test.value)
+		for _, input := range test.inputs {
+			p, _ := parser.New([]byte(input))
+			want, wantErr := p.Expect(test.value)
+			wantNext := p.Mark()
+
+			p, _ = parser.New([]byte(input))
+			got, err := p.Expect(compiled)
+			if fmt.Sprint(got, err) != fmt.Sprint(want, wantErr) {
+				t.Errorf("%s %q: expected %v %v, got %v %v", compiled, input, want, wantErr, got, err)
+			}
+			if next := p.Mark(); *next != *wantNext {
+				t.Errorf("%s %q: expected the parser at %v, got %v", compiled, input, wantNext, next)
+			}
+		}
+	}
+}
+
+func TestCompile_converter(t *testing.T) {
+	p, _ := parser.New([]byte("b"))
+	p.SetConverter(func(i interface{}) interface{} {
+		if i == 'a' {
+			return 'b'
+		}
+		return i
+	})
+	if _, err := p.Expect(parser.Compile(op.And{'a'})); err != nil {
+		t.Error(err)
+	}
+}
+
+func BenchmarkCompile(b *testing.B) {
+	digit := parser.CheckRuneRange('0', '9')
+	number := op.And{
+		op.Optional('-'),
+		op.MinOne(digit),
+		op.Optional(op.And{'.', op.MinOne(digit)}),
+		op.Optional(' '),
+	}
+	input := []byte(strings.Repeat("-3.14 42 ", 64))
+	for _, bench := range []struct {
+		name  string
+		value interface{}
+	}{
+		{name: "interpreted", value: number},
+		{name: "compiled", value: parser.Compile(number)},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			p, _ := parser.New(input)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = p.Reset(input)
+				for !p.Done() {
+					if _, err := p.Expect(bench.value); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package parser
+
+import "context"
+
+// contextInterval is the number of (nested) expectations in between two checks
+// of the context of the parser.
+const contextInterval = 256
+
+// NewContext creates a new Parser that stops parsing once the given context is
+// done. Parser.Expect returns the error of the context (e.g.
+// context.DeadlineExceeded) in that case.
+func NewContext(ctx context.Context, input []byte, options ...Option) (*Parser, error) {
+	return New(input, append(options, WithContext(ctx))...)
+}
+
+// WithContext makes the parser stop parsing once the given context is done.
+// The context is checked at the start of every outermost expectation and
+// periodically within nested expectations.
+func WithContext(ctx context.Context) Option {
+	return func(p *Parser) {
+		p.ctx = ctx
+	}
+}
+
+// checkContext periodically checks whether the context of the parser is done.
+func (p *Parser) checkContext() error {
+	if p.ctx == nil {
+		return nil
+	}
+	p.ctxCount++
+	if p.depth != 0 && p.ctxCount%contextInterval != 0 {
+		return nil
+	}
+	return p.ctx.Err()
+}
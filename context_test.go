@@ -0,0 +1,38 @@
+package parser_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+	"strings"
+	"testing"
+	"time"
+)
+
+func ExampleNewContext() {
+	ctx, cancel := context.WithCancel(context.Background())
+	p, _ := parser.NewContext(ctx, []byte("abc"))
+
+	fmt.Println(p.Expect('a'))
+	cancel()
+	fmt.Println(p.Expect('b'))
+	// Output:
+	// U+0061: a <nil>
+	// <nil> context canceled
+}
+
+func TestNewContext_deadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	slow := func(p *parser.Parser) (*parser.Cursor, bool) {
+		time.Sleep(10 * time.Microsecond)
+		return p.Check('a')
+	}
+	input := []byte(strings.Repeat("a", 100000))
+	p, _ := parser.NewContext(ctx, input)
+	if _, err := p.Expect(op.MinZero(slow)); err != context.DeadlineExceeded {
+		t.Error(err)
+	}
+}
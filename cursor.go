@@ -1,6 +1,9 @@
 package parser
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+)
 
 // Cursor allows you to record your current position so you can return to it
 // later. Keeps track of its own position in the buffer of the parser.
@@ -14,6 +17,22 @@ type Cursor struct {
 	position int
 	// The row and column of the current rune, NOT in bytes!
 	row, column int
+	// The column of the current rune as shown in an editor, tabs are expanded
+	// based on the tab width of the parser.
+	visual int
+	// The column and visual column of the previous rune, so LookBack does not
+	// need to scan the line. Negative if unknown.
+	previousColumn, previousVisual int
+	// The name of the file the cursor points into, if any.
+	filename string
+	// The number of invalid UTF-8 bytes that were replaced or skipped.
+	invalid int
+	// The user state stack at the position of the cursor.
+	user *userState
+	// The indentation levels at the position of the cursor.
+	indent *indentLevel
+	// The named captures at the position of the cursor.
+	captures *captured
 }
 
 // Position returns the row and column of the cursors location.
@@ -21,10 +40,70 @@ func (c *Cursor) Position() (int, int) {
 	return c.row, c.column
 }
 
+// VisualColumn returns the column of the cursor as shown in an editor. Tabs
+// advance the column to the next tab stop. See WithTabWidth.
+func (c *Cursor) VisualColumn() int {
+	return c.visual
+}
+
+// Filename returns the name of the file the cursor points into. Returns an
+// empty string if no filename was given. See WithFilename.
+func (c *Cursor) Filename() string {
+	return c.filename
+}
+
+// Offset returns the byte offset of the cursor in the input.
+func (c *Cursor) Offset() int {
+	return c.position
+}
+
+// Invalid returns the number of invalid UTF-8 bytes that were encountered when
+// decoding the current rune. Depending on the InvalidUTF8 policy of the parser,
+// these bytes are either replaced by the current rune (utf8.RuneError) or
+// skipped, in which case they directly precede the current rune.
+func (c *Cursor) Invalid() int {
+	return c.invalid
+}
+
 func (c *Cursor) String() string {
 	return fmt.Sprintf("%U: %c", c.Rune, c.Rune)
 }
 
+// cursorJSON is the JSON representation of a cursor.
+type cursorJSON struct {
+	Offset   int    `json:"offset"`
+	Row      int    `json:"row"`
+	Column   int    `json:"column"`
+	Filename string `json:"filename,omitempty"`
+}
+
+// MarshalJSON encodes the position of the cursor, e.g. to store the positions
+// of the nodes of a parsed tree.
+func (c *Cursor) MarshalJSON() ([]byte, error) {
+	return json.Marshal(cursorJSON{
+		Offset:   c.position,
+		Row:      c.row,
+		Column:   c.column,
+		Filename: c.filename,
+	})
+}
+
+// UnmarshalJSON decodes the position of the cursor. The decoded cursor only
+// reports its position, it can not be used to jump back into a parser.
+func (c *Cursor) UnmarshalJSON(data []byte) error {
+	var v cursorJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*c = Cursor{
+		position: v.Offset,
+		row:      v.Row,
+		column:   v.Column,
+		filename: v.Filename,
+	}
+	return nil
+}
+
 // state manages the state of a parser. It contains a pointer to the last
 // successfully parsed rune.
 type state struct {
@@ -40,8 +119,12 @@ func (s *state) Ok(last *Cursor) {
 	}
 	s.end = last
 	// We jump to the given cursor (last parsed rune) because it is not
-	// guaranteed that the already parser did not pass it.
+	// guaranteed that the already parser did not pass it. The user state,
+	// indentation and captures are kept, they might have been pushed after the
+	// last mark.
+	user, indent, captures := s.p.cursor.user, s.p.cursor.indent, s.p.cursor.captures
 	s.p.Jump(last).Next()
+	s.p.cursor.user, s.p.cursor.indent, s.p.cursor.captures = user, indent, captures
 }
 
 // End returns a mark to the last successfully parsed rune.
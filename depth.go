@@ -0,0 +1,89 @@
+package parser
+
+// WithMaxDepth limits the number of nested expectations. If the limit is
+// exceeded, the parser stops and returns a DepthExceededError. This prevents
+// deeply nested (e.g. attacker controlled) input from overflowing the stack of
+// recursive grammars. Values less than 1 disable the limit.
+func WithMaxDepth(n int) Option {
+	return func(p *Parser) {
+		p.maxDepth = n
+	}
+}
+
+// enter marks the start of expecting a value. It returns an error if the
+// parser should stop parsing, e.g. if the maximum depth is exceeded or the
+// context of the parser is done. Once an error is returned, all following
+// calls return the same error until the outermost expectation is left.
+func (p *Parser) enter(i interface{}) error {
+	if p.fatal != nil {
+		return p.fatal
+	}
+	if err := p.checkContext(); err != nil {
+		p.fatal = err
+		return err
+	}
+	if p.depth == 0 {
+		// Start of the outermost expectation.
+		p.farthest.ok = false
+	}
+	if 0 < p.maxDepth && p.maxDepth <= p.depth {
+		p.fatal = &DepthExceededError{
+			Depth:  p.maxDepth,
+			Cursor: *p.cursor,
+		}
+		return p.fatal
+	}
+	p.depth++
+	if p.trace != nil {
+		p.trace.enter(p, i)
+	}
+	if p.profiler != nil {
+		p.profiler.enter(i)
+	}
+	if p.hooks != nil {
+		p.hooks.enter(p, i)
+	}
+	return nil
+}
+
+// leave marks the end of expecting a value, it should only be called if the
+// corresponding call to enter did not return an error. The given error is the
+// result of expecting the value. It returns the error that stopped the parser,
+// if any. Leaving the outermost expectation stores the values that were
+// scanned (see op.Scan), an error of storing them is returned as well.
+func (p *Parser) leave(i interface{}, err error) error {
+	if p.fatal != nil {
+		err = p.fatal
+	}
+	if p.trace != nil {
+		p.trace.leave(p, i, err)
+	}
+	if p.profiler != nil {
+		p.profiler.leave(i, err)
+	}
+	if p.hooks != nil {
+		p.hooks.leave(p, i, err)
+	}
+	p.depth--
+	if p.depth == 0 {
+		// Left the outermost expectation.
+		if p.fatal == nil && err == nil {
+			return p.writeScans()
+		}
+		err = p.fatal
+		p.fatal = nil
+		p.scans = p.scans[:0]
+		return err
+	}
+	return p.fatal
+}
+
+// stop stops the parser with the given error, the same as exceeding the
+// maximum depth. If the parser is already stopped, the error that stopped it
+// is returned instead.
+func (p *Parser) stop(err error) error {
+	if p.fatal == nil {
+		p.fatal = err
+	}
+	return p.fatal
+}
@@ -0,0 +1,55 @@
+package parser_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+	"strings"
+	"testing"
+)
+
+// nested matches nested brackets, e.g. "[[[]]]".
+func nested(p *parser.Parser) (*parser.Cursor, bool) {
+	return p.Check(op.And{'[', op.Optional(nested), ']'})
+}
+
+func ExampleWithMaxDepth() {
+	p, _ := parser.New([]byte("[[[[]]]]"), parser.WithMaxDepth(10))
+	fmt.Println(p.Expect(nested))
+	// Output:
+	// <nil> parser [00:003]: maximum depth of 10 exceeded
+}
+
+func TestWithMaxDepth(t *testing.T) {
+	input := []byte(strings.Repeat("[", 100000) + strings.Repeat("]", 100000))
+	p, _ := parser.New(input, parser.WithMaxDepth(1000))
+
+	var depthErr *parser.DepthExceededError
+	if _, err := p.Expect(nested); !errors.As(err, &depthErr) {
+		t.Fatal(err)
+	}
+
+	// The parser can be used again.
+	_ = p.Reset([]byte("[[]]"))
+	if _, err := p.Expect(nested); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestExtension_Stop(t *testing.T) {
+	stop := errors.New("stop")
+	p, _ := parser.New([]byte("ab"))
+	stopping := func(p *parser.Parser) (*parser.Cursor, bool) {
+		_ = p.Extension().Stop(stop)
+		return nil, false
+	}
+	// Alternatives are not tried once the parser is stopped.
+	if _, err := p.Expect(op.Or{stopping, 'a'}); err != stop {
+		t.Errorf("expected the stop error, got %v", err)
+	}
+	// The parser continues at the next outermost expectation.
+	if _, err := p.Expect('a'); err != nil {
+		t.Error(err)
+	}
+}
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 	"github.com/di-wu/parser/op"
 	"reflect"
@@ -18,6 +19,17 @@ func (e *InitError) Error() string {
 	return fmt.Sprintf("parser: %s", e.Message)
 }
 
+// InvalidUTF8Error indicates that the input contains invalid UTF-8. Only
+// returned if the InvalidUTF8 policy of the parser is RejectInvalid.
+type InvalidUTF8Error struct {
+	// The (byte) offset of the first invalid byte.
+	Offset int
+}
+
+func (e *InvalidUTF8Error) Error() string {
+	return fmt.Sprintf("parser: invalid UTF-8 at offset %d", e.Offset)
+}
+
 // ExpectError is an error that occurs on when an invalid/unsupported value is
 // passed to the Parser.Expect function.
 type ExpectError struct {
@@ -35,11 +47,134 @@ func (p *Parser) ExpectedParseError(expected interface{}, start, end *Cursor) *E
 		end = start
 	}
 	defer p.Jump(start)
-	return &ExpectedParseError{
+	err := &ExpectedParseError{
 		Expected: expected,
 		String:   p.Slice(start, end),
 		Conflict: *end,
+		input:    p.buffer,
+	}
+	p.record(err)
+	return err
+}
+
+// farthest is the failure that got the farthest into the input, see record.
+// The expectations of the failures at its position are collected as is, they
+// are only deduplicated once the failure gets reported, see error.
+type farthest struct {
+	// ok indicates whether anything failed.
+	ok  bool
+	err ExpectedParseError
+	// expected contains the expectations of all aggregated failures, including
+	// duplicates. Only used if aggregated is set.
+	expected   []interface{}
+	aggregated bool
+}
+
+// set replaces the farthest failure by the given failure.
+func (f *farthest) set(err *ExpectedParseError) {
+	f.ok = true
+	f.err = *err
+	f.aggregated = false
+}
+
+// add adds the expectations of the given failure to the farthest failure.
+func (f *farthest) add(err *ExpectedParseError) {
+	if !f.aggregated {
+		// Reuse the slice of the previous failures.
+		f.expected = appendExpectations(f.expected[:0], &f.err)
+		f.aggregated = true
+	}
+	f.expected = appendExpectations(f.expected, err)
+}
+
+// error returns (a copy of) the farthest failure, nil if nothing failed.
+func (f *farthest) error() *ExpectedParseError {
+	if !f.ok {
+		return nil
+	}
+	err := f.err
+	if f.aggregated {
+		// The expectations of the failure itself come first, as is.
+		n := len(err.Expectations)
+		if n == 0 {
+			n = 1
+		}
+		err.Expectations = append([]interface{}(nil), f.expected[:n]...)
+		err.Append(f.expected[n:]...)
+	} else {
+		err.Expectations = append([]interface{}(nil), err.Expectations...)
 	}
+	return &err
+}
+
+// appendExpectations appends all the values that were expected by the given
+// error to the given slice.
+func appendExpectations(expected []interface{}, err *ExpectedParseError) []interface{} {
+	if len(err.Expectations) == 0 {
+		return append(expected, err.Expected)
+	}
+	return append(expected, err.Expectations...)
+}
+
+// record keeps track of the farthest failure. Expectations of failures at the
+// same position get aggregated, except those of combinators.
+func (p *Parser) record(err *ExpectedParseError) {
+	if p.skipping || 0 < p.labels {
+		return
+	}
+	f := &p.farthest
+	if !f.ok || f.err.Conflict.position < err.Conflict.position {
+		f.set(err)
+		return
+	}
+	if f.err.Conflict.position == err.Conflict.position {
+		switch {
+		case err.explicit():
+			// Explicit failures take priority.
+			f.set(err)
+			return
+		case f.err.explicit():
+			return
+		}
+		switch err.Expected.(type) {
+		case op.And, op.Or, op.XOr, op.Longest, op.Range:
+		default:
+			f.add(err)
+		}
+	}
+}
+
+// FarthestParseError works the same as ExpectedParseError, but returns (a copy
+// of) the farthest failure instead if it got further into the input than the
+// given end cursor. Combinators use this to report the failure of the deepest
+// alternative instead of their own.
+func (p *Parser) FarthestParseError(expected interface{}, start, end *Cursor) *ExpectedParseError {
+	if end == nil {
+		end = start
+	}
+	f := &p.farthest
+	if f.ok && (end.position < f.err.Conflict.position ||
+		f.err.explicit() && (f.err.Conflict.position == start.position || f.err.Conflict.position == end.position)) {
+		p.Jump(start)
+		return f.error()
+	}
+	var expectations []interface{}
+	if f.ok && (f.err.Conflict.position == start.position || f.err.Conflict.position == end.position) {
+		// Report what was expected at the position where all values failed.
+		expectations = f.error().expectations()
+	}
+	err := p.ExpectedParseError(expected, start, end)
+	if expectations != nil {
+		err.Expectations = expectations
+	}
+	return err
+}
+
+// Farthest returns (a copy of) the failure that got the farthest into the
+// input since the start of the outermost expectation, or nil if nothing
+// failed.
+func (p *Parser) Farthest() *ExpectedParseError {
+	return p.farthest.error()
 }
 
 // ExpectedParseError indicates that the parser Expected a different value than
@@ -51,54 +186,114 @@ type ExpectedParseError struct {
 	String string
 	// The position of the conflicting value.
 	Conflict Cursor
+	// Expectations contains all the values that were expected at the position
+	// of the conflict, e.g. the alternatives of an op.Or. Empty if only the
+	// Expected value was expected.
+	Expectations []interface{}
+	// Unclosed is set if the closing delimiter of an op.Between was expected.
+	Unclosed *Unclosed
+
+	// input is used to render the source excerpt, see Pretty.
+	input []byte
 }
 
-func Stringer(i interface{}) string {
-	i = ConvertAliases(i)
-	if reflect.TypeOf(i).Kind() == reflect.Func {
-		return "func"
+// Append adds the given values to the expectations of the error, values that
+// are already expected are ignored. Combinators can use this to collect the
+// expectations of their alternatives as they unwind.
+func (e *ExpectedParseError) Append(expected ...interface{}) {
+	if len(e.Expectations) == 0 {
+		e.Expectations = append(e.Expectations, e.Expected)
 	}
+	for _, v := range expected {
+		if !e.expects(v) {
+			e.Expectations = append(e.Expectations, v)
+		}
+	}
+}
 
-	switch v := i.(type) {
+// expects checks whether the given value is already expected.
+func (e *ExpectedParseError) expects(v interface{}) bool {
+	for _, expected := range e.Expectations {
+		if equalValues(expected, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalValues checks whether the given values are equal. Values that can not be
+// compared (e.g. functions and slices) are never equal.
+func equalValues(a, b interface{}) bool {
+	// Most expected values are runes and strings, compare them directly.
+	switch a := a.(type) {
 	case rune:
-		return fmt.Sprintf("'%s'", string(v))
+		b, ok := b.(rune)
+		return ok && a == b
 	case string:
-		return fmt.Sprintf("%q", v)
-	case op.Not:
-		return fmt.Sprintf("!%s", Stringer(v.Value))
-	case op.Ensure:
-		return fmt.Sprintf("?%s", Stringer(v.Value))
-	case op.And:
-		and := make([]string, len(v))
-		for i, v := range v {
-			and[i] = Stringer(v)
-		}
-		return fmt.Sprintf("and[%s]", strings.Join(and, " "))
-	case op.Or:
-		or := make([]string, len(v))
-		for i, v := range v {
-			or[i] = Stringer(v)
-		}
-		return fmt.Sprintf("or[%s]", strings.Join(or, " "))
-	case op.XOr:
-		xor := make([]string, len(v))
-		for i, v := range v {
-			xor[i] = Stringer(v)
+		b, ok := b.(string)
+		return ok && a == b
+	}
+	t := reflect.TypeOf(a)
+	if t == nil || t != reflect.TypeOf(b) {
+		return false
+	}
+	return canCompare(reflect.ValueOf(a)) && canCompare(reflect.ValueOf(b)) && a == b
+}
+
+// canCompare checks whether the given value can be compared without panicking.
+// Comparable types (e.g. op.Not) can contain values that are not, e.g. an op.And.
+func canCompare(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Interface:
+		return v.IsNil() || canCompare(v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !canCompare(v.Field(i)) {
+				return false
+			}
 		}
-		return fmt.Sprintf("xor[%s]", strings.Join(xor, " "))
-	case op.Range:
-		if v.Max == -1 {
-			switch v.Min {
-			case 0:
-				return fmt.Sprintf("%s*", Stringer(v.Value))
-			case 1:
-				return fmt.Sprintf("%s+", Stringer(v.Value))
+		return true
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !canCompare(v.Index(i)) {
+				return false
 			}
 		}
-		return fmt.Sprintf("%s{%d:%d}", Stringer(v.Value), v.Min, v.Max)
+		return true
 	default:
-		return fmt.Sprintf("%v", v)
+		return v.Type().Comparable()
+	}
+}
+
+// explicit checks whether the error is an explicit failure, i.e. an op.Fail or
+// an op.Error.
+func (e *ExpectedParseError) explicit() bool {
+	switch e.Expected.(type) {
+	case op.Fail, op.Error:
+		return true
+	}
+	return false
+}
+
+// expectations returns all the values that were expected.
+func (e *ExpectedParseError) expectations() []interface{} {
+	if len(e.Expectations) == 0 {
+		return []interface{}{e.Expected}
 	}
+	return e.Expectations
+}
+
+// copy returns a copy of the error that does not share its expectations.
+func (e *ExpectedParseError) copy() *ExpectedParseError {
+	err := *e
+	err.Expectations = append([]interface{}(nil), e.Expectations...)
+	return &err
+}
+
+// Stringer returns the given value in the notation of op.String, which is also
+// used in error messages.
+func Stringer(i interface{}) string {
+	return op.String(ConvertAliases(i))
 }
 
 func (e *ExpectedParseError) Error() string {
@@ -109,9 +304,179 @@ func (e *ExpectedParseError) Error() string {
 		got = fmt.Sprintf("%q", e.String)
 	}
 
+	switch v := e.Expected.(type) {
+	case op.Fail:
+		return e.message(v.Message)
+	case op.Error:
+		return e.message(v.Message)
+	}
+
+	expected := fmt.Sprintf("%T %s", e.Expected, Stringer(e.Expected))
+	if l, ok := e.Expected.(op.Label); ok {
+		expected = l.Name
+	}
+	if 1 < len(e.Expectations) {
+		// e.g. 'a', 'b' or 'c'
+		last := len(e.Expectations) - 1
+		values := make([]string, last)
+		for i, v := range e.Expectations[:last] {
+			values[i] = Stringer(v)
+		}
+		expected = fmt.Sprintf("%s or %s", strings.Join(values, ", "), Stringer(e.Expectations[last]))
+	}
+
+	var unclosed string
+	if u := e.Unclosed; u != nil {
+		unclosed = fmt.Sprintf(", unclosed %s opened at [%02d:%03d]", Stringer(u.Open), u.Cursor.row, u.Cursor.column)
+		if u.Cursor.filename != "" {
+			unclosed = fmt.Sprintf(", unclosed %s opened at %d:%d", Stringer(u.Open), u.Cursor.row+1, u.Cursor.column+1)
+		}
+	}
+
+	if e.Conflict.filename != "" {
+		// Use the "file:line:column" notation, lines and columns start at 1.
+		return fmt.Sprintf(
+			"%s:%d:%d: expected %s but got %s%s",
+			e.Conflict.filename, e.Conflict.row+1, e.Conflict.column+1, expected, got, unclosed,
+		)
+	}
 	return fmt.Sprintf(
-		"parse conflict [%02d:%03d]: expected %T %s but got %s",
-		e.Conflict.row, e.Conflict.column, e.Expected, Stringer(e.Expected), got,
+		"parse conflict [%02d:%03d]: expected %s but got %s%s",
+		e.Conflict.row, e.Conflict.column, expected, got, unclosed,
+	)
+}
+
+// message returns the given message prefixed by the position of the conflict.
+func (e *ExpectedParseError) message(message string) string {
+	if e.Conflict.filename != "" {
+		return fmt.Sprintf(
+			"%s:%d:%d: %s",
+			e.Conflict.filename, e.Conflict.row+1, e.Conflict.column+1, message,
+		)
+	}
+	return fmt.Sprintf(
+		"parse conflict [%02d:%03d]: %s",
+		e.Conflict.row, e.Conflict.column, message,
+	)
+}
+
+// Unclosed contains the opening delimiter of an op.Between of which the closing
+// delimiter was expected, and its position.
+type Unclosed struct {
+	Open   interface{}
+	Cursor Cursor
+}
+
+// unclosedParseError marks the given failure to match the closing delimiter of
+// an op.Between as unclosed, so that it points at the opening delimiter. This
+// also applies to the farthest failure if it is the same failure, so that the
+// mark is kept by combinators. The error is returned as is.
+func (p *Parser) unclosedParseError(open interface{}, opened *Cursor, err error) error {
+	var conflict *ExpectedParseError
+	if !errors.As(err, &conflict) {
+		return err
+	}
+	unclosed := &Unclosed{
+		Open:   open,
+		Cursor: *opened,
+	}
+	if conflict.Unclosed == nil {
+		conflict.Unclosed = unclosed
+	}
+	if f := &p.farthest; f.ok && f.err.Unclosed == nil && f.err.Conflict.position == conflict.Conflict.position {
+		f.err.Unclosed = unclosed
+	}
+	return err
+}
+
+// CutError indicates that a value failed after passing an op.Cut, or that an
+// op.Error was expected. The nearest enclosing op.Or does not try its other
+// alternatives and fails with the error of the value instead. An op.Error stops
+// the parser, it can only be recovered from by an op.Recover.
+type CutError struct {
+	// The error of the value that failed.
+	Err error
+}
+
+// cutParseError wraps the given error in a CutError, because it occurred after
+// passing an op.Cut. Errors that already passed a cut are returned as is.
+func (p *Parser) cutParseError(err error) error {
+	if _, ok := cutError(err); ok {
+		return err
+	}
+	return &CutError{
+		Err: err,
+	}
+}
+
+// cutError returns the error of the value that failed after passing a cut, if
+// the given error is a CutError.
+func cutError(err error) (error, bool) {
+	switch v := err.(type) {
+	case nil, *ExpectedParseError:
+		// Most errors are failures of alternatives, avoid errors.As.
+		return err, false
+	case *CutError:
+		return v.Err, true
+	}
+	var cut *CutError
+	if errors.As(err, &cut) {
+		return cut.Err, true
+	}
+	return err, false
+}
+
+func (e *CutError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CutError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidMarkError indicates that the parser tried to jump to a mark that is
+// not inside the window of the stream anymore.
+type InvalidMarkError struct {
+	Mark Cursor
+}
+
+func (e *InvalidMarkError) Error() string {
+	return fmt.Sprintf(
+		"stream: mark at position %d is not inside the window anymore",
+		e.Mark.position,
+	)
+}
+
+// DepthExceededError indicates that the maximum depth of nested expectations
+// is exceeded. See WithMaxDepth.
+type DepthExceededError struct {
+	// The maximum depth.
+	Depth int
+	// The position at which the maximum depth got exceeded.
+	Cursor Cursor
+}
+
+func (e *DepthExceededError) Error() string {
+	return fmt.Sprintf(
+		"parser [%02d:%03d]: maximum depth of %d exceeded",
+		e.Cursor.row, e.Cursor.column, e.Depth,
+	)
+}
+
+// NoProgressError indicates that a repetition matched without consuming any
+// input, which would otherwise result in an infinite loop. See
+// WithProgressCheck.
+type NoProgressError struct {
+	// The repetition that did not make any progress.
+	Value interface{}
+	// The position at which the repetition got stuck.
+	Cursor Cursor
+}
+
+func (e *NoProgressError) Error() string {
+	return fmt.Sprintf(
+		"parser [%02d:%03d]: repetition %s matches without consuming any input",
+		e.Cursor.row, e.Cursor.column, Stringer(e.Value),
 	)
 }
 
@@ -123,3 +488,39 @@ type UnsupportedType struct {
 func (e *UnsupportedType) Error() string {
 	return fmt.Sprintf("parse: value of type %T are not supported", e.Value)
 }
+
+// UndefinedRuleError indicates that a referenced rule is not defined.
+type UndefinedRuleError struct {
+	Name string
+}
+
+func (e *UndefinedRuleError) Error() string {
+	return fmt.Sprintf("parse: rule %s is not defined", e.Name)
+}
+
+// RuleError wraps an error that occurred while parsing a rule. Only the rule
+// that is the closest to the error is mentioned.
+type RuleError struct {
+	// The name of the rule.
+	Rule string
+	Err  error
+}
+
+// NewRuleError wraps the given error in a RuleError, unless it already is one.
+func NewRuleError(rule string, err error) error {
+	if _, ok := err.(*RuleError); ok {
+		return err
+	}
+	return &RuleError{
+		Rule: rule,
+		Err:  err,
+	}
+}
+
+func (e *RuleError) Error() string {
+	return fmt.Sprintf("while parsing %s: %s", e.Rule, e.Err)
+}
+
+func (e *RuleError) Unwrap() error {
+	return e.Err
+}
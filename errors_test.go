@@ -0,0 +1,68 @@
+package parser_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+	"testing"
+)
+
+func ExampleParser_FarthestParseError() {
+	p, _ := parser.New([]byte("if x then y"))
+	fmt.Println(p.Expect(op.Or{
+		op.And{"if ", 'x', " else"},
+		"while",
+	}))
+	// Output:
+	// <nil> parse conflict [00:005]: expected string " else" but got " t"
+}
+
+func ExampleExpectedParseError_Append() {
+	p, _ := parser.New([]byte("for"))
+	keyword := op.Or{"if", "while"}
+	_, err := p.Expect(keyword)
+	fmt.Println(err)
+
+	err.(*parser.ExpectedParseError).Append("identifier")
+	fmt.Println(err)
+	// Output:
+	// parse conflict [00:000]: expected "if" or "while" but got 'f'
+	// parse conflict [00:000]: expected "if", "while" or "identifier" but got 'f'
+}
+
+func TestParser_Farthest(t *testing.T) {
+	p, _ := parser.New([]byte("abc"))
+	if _, err := p.Expect(op.Or{"abd", "ax"}); err == nil {
+		t.Fatal("expected an error")
+	} else if err, ok := err.(*parser.ExpectedParseError); !ok || err.Expected != "abd" {
+		t.Error(err)
+	}
+	if f := p.Farthest(); f == nil || f.Conflict.Offset() != 2 {
+		t.Error(f)
+	}
+	// The parser is reset to the start of the failed value.
+	if p.Offset() != 0 {
+		t.Error(p.Offset())
+	}
+
+	// Reset for every outermost expectation.
+	if _, err := p.Expect('a'); err != nil {
+		t.Fatal(err)
+	}
+	if f := p.Farthest(); f != nil {
+		t.Error(f)
+	}
+}
+
+func TestExpectedParseError_Expectations(t *testing.T) {
+	p, _ := parser.New([]byte("x"))
+	notX := op.Not{Value: op.And{'x'}}
+	_, err := p.Expect(op.And{op.Optional('-'), op.Or{notX, '(', '(', notX}})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	// Duplicates are ignored, slices can not be compared.
+	if e := err.(*parser.ExpectedParseError); len(e.Expectations) != 4 {
+		t.Error(e.Expectations)
+	}
+}
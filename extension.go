@@ -0,0 +1,136 @@
+package parser
+
+import "github.com/di-wu/parser/op"
+
+// Extension is the hook for parsers that are built on top of this parser and
+// match values themselves, e.g. the ast package that creates nodes. It is not
+// needed to use the parser itself.
+//
+// Values that are expected by an extension share the bookkeeping of Expect:
+// the maximum depth, the context, the attached states, the values of op.Scan,
+// tracing, profiling and the hooks of the parser.
+type Extension struct {
+	p *Parser
+}
+
+// Extension returns the hook for parsers that are built on top of the parser.
+func (p *Parser) Extension() Extension {
+	return Extension{p: p}
+}
+
+// Run expects the given value with the given match function, instead of
+// matching it itself. The value should already be converted, see
+// ConvertAliases. Run returns the result of the match function, or nil if the
+// value does not match. The attached states are restored on failure.
+//
+// The values that rely on the state of the parser are handled by Run:
+// op.Label, op.Trace, op.Capture, op.Scan, op.Reserved, op.Where and *op.Memo.
+// For these values, the match function only has to expect the value that they
+// wrap. Memoized results are shared, the same result can be returned more than
+// once. An op.Error fails without calling the match function. The values that
+// are scanned within an op.Ensure are not stored.
+func (e Extension) Run(i interface{}, match func(i interface{}) (interface{}, error)) (interface{}, error) {
+	var value interface{}
+	_, err := e.p.run(i, func(p *Parser, i interface{}) (*Cursor, error) {
+		var err error
+		value, err = p.extended(i, match)
+		return nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// Stop stops the parser with the given error, the same as exceeding the
+// maximum depth. Extensions can use it to enforce their own limits. If the
+// parser is already stopped, the error that stopped it is returned instead.
+func (e Extension) Stop(err error) error {
+	return e.p.stop(err)
+}
+
+// NoProgress should be called by repetitions if an iteration matched without
+// consuming any input. It stops the parser and returns a NoProgressError if
+// the parser checks for progress, otherwise it returns nil.
+func (e Extension) NoProgress(repetition interface{}) error {
+	return e.p.noProgress(repetition)
+}
+
+// UnclosedParseError marks the given failure to match the closing delimiter
+// of an op.Between as unclosed, so that it points at the opening delimiter
+// that was matched at the given cursor. The error is returned as is.
+func (e Extension) UnclosedParseError(open interface{}, opened *Cursor, err error) error {
+	return e.p.unclosedParseError(open, opened, err)
+}
+
+// Recover records the given error of an op.Recover and skips everything up to
+// and including the given sync value. Returns a mark to the last skipped rune,
+// if any. Returns false if the error can not be recovered from.
+func (e Extension) Recover(err error, sync interface{}) (*Cursor, bool) {
+	return e.p.recover(err, sync)
+}
+
+// extensionKey distinguishes the memoized results of extensions from the
+// results of the parser itself.
+type extensionKey struct {
+	memo *op.Memo
+}
+
+// extended matches the given value with the match function of an extension,
+// see Extension.Run.
+func (p *Parser) extended(i interface{}, match func(i interface{}) (interface{}, error)) (interface{}, error) {
+	start := p.Mark()
+	switch v := i.(type) {
+	case op.Label:
+		var value interface{}
+		err := p.labeled(v, func() (err error) {
+			value, err = match(v)
+			return err
+		})
+		return value, err
+	case op.Trace:
+		var value interface{}
+		err := p.traced(v, func() (err error) {
+			value, err = match(v)
+			return err
+		})
+		return value, err
+	case *op.Memo:
+		return p.memoize(extensionKey{memo: v}, func() (interface{}, error) {
+			return match(v)
+		})
+	case op.Error:
+		return nil, p.stop(p.cutParseError(p.ExpectedParseError(v, start, start)))
+	case op.Ensure:
+		scans := len(p.scans)
+		value, err := match(v)
+		if err == nil {
+			// Nothing is consumed, so the scanned values are not stored either.
+			p.scans = p.scans[:scans]
+		}
+		return value, err
+	case op.Capture, op.Scan, op.Reserved, op.Where:
+		// Do not include the leading trivia in the text.
+		p.SkipTrivia()
+		begin := p.Mark()
+		value, err := match(v)
+		if err != nil {
+			p.Jump(start)
+			return nil, err
+		}
+		var (
+			last *Cursor
+			text string
+		)
+		if p.Offset() != begin.Offset() {
+			last = p.LookBack()
+			text = p.Slice(begin, last)
+		}
+		if err := p.matchedText(v, begin, last, text); err != nil {
+			p.Jump(start)
+			return nil, err
+		}
+		return value, nil
+	}
+	return match(i)
+}
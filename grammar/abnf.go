@@ -0,0 +1,386 @@
+package grammar
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ascii"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+)
+
+// FromABNF creates a grammar from the given ABNF (RFC 5234 and RFC 7405)
+// source, e.g.
+//
+//	date   = year "-" month "-" day
+//	year   = 4DIGIT
+//	month  = 2DIGIT
+//	day    = 2DIGIT
+//
+// Every rule results in an ast.Capture, of which the type is the index of the
+// rule. Rule names are case insensitive, references use the name of the first
+// definition of the rule. Names that are not defined in the source refer to the
+// core rules (e.g. ALPHA, DIGIT or CRLF), or to rules that can still be defined
+// afterwards. Incremental alternatives ("=/") are added to the alternatives of
+// a rule that is defined earlier on.
+//
+// Alternatives are not ordered in ABNF, so the alternative that consumes the
+// most input is chosen (see op.Longest). Repetitions are greedy and do not
+// backtrack, e.g. *ALPHA ALPHA never matches. Prose values are not supported,
+// unless they are repeated zero times (e.g. 0<pchar>).
+func FromABNF(src []byte) (*Grammar, error) {
+	p, err := parser.New(src, parser.WithTrivia(abnfTrivia))
+	if err != nil {
+		return nil, err
+	}
+	ap, err := ast.NewFromParser(p)
+	if err != nil {
+		return nil, err
+	}
+	root, err := ap.Expect(abnf.Ref("Rulelist"))
+	if err != nil {
+		return nil, err
+	}
+
+	l := abnfLoader{
+		g:     New(),
+		names: make(map[string]string),
+	}
+	rules := root.Children()
+	for _, r := range rules {
+		name, definedAs := r.FirstChild, r.FirstChild.NextSibling
+		key := strings.ToLower(name.Value)
+		if _, ok := l.names[key]; !ok {
+			if definedAs.Value == "=/" {
+				return nil, loadError(name, "%s is not defined", name.Value)
+			}
+			l.names[key] = name.Value
+			l.types = append(l.types, name.Value)
+		} else if definedAs.Value == "=" {
+			return nil, loadError(name, "%s is defined more than once", name.Value)
+		}
+	}
+	alternatives := make(map[string]op.Longest)
+	for _, r := range rules {
+		name, definedAs := r.FirstChild, r.FirstChild.NextSibling
+		value, err := l.expression(definedAs.NextSibling)
+		if err != nil {
+			return nil, err
+		}
+		name.Value = l.names[strings.ToLower(name.Value)]
+		if definedAs.Value == "=/" {
+			if alternatives[name.Value] == nil {
+				previous, _ := l.g.Lookup(name.Value)
+				alternatives[name.Value] = op.Longest{previous.(ast.Capture).Value}
+			}
+			alternatives[name.Value] = append(alternatives[name.Value], value)
+			value = alternatives[name.Value]
+		}
+		l.g.Define(name.Value, ast.Capture{
+			Type:        l.typeOf(name.Value),
+			TypeStrings: l.types,
+			Value:       value,
+		})
+	}
+	return l.g, nil
+}
+
+// abnfLoader converts the rules of an ABNF source to rules.
+type abnfLoader struct {
+	g *Grammar
+	// names maps the lower case names of the rules that are defined in the
+	// source to the name of their first definition.
+	names map[string]string
+	// types contains the names of the rules.
+	types []string
+}
+
+// typeOf returns the type of the rule with the given name.
+func (l *abnfLoader) typeOf(name string) int {
+	for i, t := range l.types {
+		if t == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// expression converts the given node to the value it represents.
+func (l *abnfLoader) expression(n *ast.Node) (interface{}, error) {
+	switch n.Type {
+	case abnfAlternation, abnfConcatenation:
+		var values []interface{}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			value, err := l.expression(c)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		if len(values) == 1 {
+			return values[0], nil
+		}
+		if n.Type == abnfAlternation {
+			return op.Longest(values), nil
+		}
+		return op.And(values), nil
+	case abnfRepetition:
+		if n.FirstChild == n.LastChild {
+			// No repeat.
+			return l.expression(n.LastChild)
+		}
+		min, max, err := bounds(n.FirstChild)
+		if err != nil {
+			return nil, err
+		}
+		if max == 0 {
+			// Zero repetitions (e.g. 0<pchar>) match the empty string.
+			return op.And{}, nil
+		}
+		value, err := l.expression(n.LastChild)
+		if err != nil {
+			return nil, err
+		}
+		switch max {
+		case -1:
+			return op.Min(min, value), nil
+		case min:
+			return op.Repeat(min, value), nil
+		default:
+			return op.MinMax(min, max, value), nil
+		}
+	case abnfOption:
+		value, err := l.expression(n.FirstChild)
+		if err != nil {
+			return nil, err
+		}
+		return op.Optional(value), nil
+	case abnfReference:
+		if name, ok := l.names[strings.ToLower(n.Value)]; ok {
+			return l.g.Ref(name), nil
+		}
+		if value, ok := abnfCore[strings.ToUpper(n.Value)]; ok {
+			return value, nil
+		}
+		return l.g.Ref(n.Value), nil
+	case abnfString:
+		return charVal(n.Value), nil
+	case abnfNumber:
+		return numVal(n)
+	case abnfProse:
+		return nil, loadError(n, "prose value %s is not supported", n.Value)
+	default:
+		return nil, loadError(n, "unexpected %s", n.TypeString())
+	}
+}
+
+// bounds returns the minimum and maximum of the given repeat (e.g. 1* or 2*4).
+// The maximum is -1 if there is none.
+func bounds(n *ast.Node) (int, int, error) {
+	i := strings.IndexRune(n.Value, '*')
+	if i == -1 {
+		c, err := strconv.Atoi(n.Value)
+		if err != nil {
+			return 0, 0, loadError(n, "invalid repeat %s", n.Value)
+		}
+		return c, c, nil
+	}
+	min, max := 0, -1
+	if s := n.Value[:i]; s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, 0, loadError(n, "invalid repeat %s", n.Value)
+		}
+		min = v
+	}
+	if s := n.Value[i+1:]; s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < min {
+			return 0, 0, loadError(n, "invalid repeat %s", n.Value)
+		}
+		max = v
+	}
+	return min, max, nil
+}
+
+// charVal converts a quoted string, e.g. "abc" or %s"abc". Quoted strings are
+// case insensitive, unless prefixed with %s.
+func charVal(s string) interface{} {
+	var sensitive bool
+	if strings.HasPrefix(s, "%") {
+		sensitive = s[1] == 's' || s[1] == 'S'
+		s = s[2:]
+	}
+	var value interface{} = s[1 : len(s)-1]
+	if s := value.(string); utf8.RuneCountInString(s) == 1 {
+		value, _ = utf8.DecodeRuneInString(s)
+	}
+	if sensitive {
+		return value
+	}
+	return op.CaseInsensitive{Value: value}
+}
+
+// numVal converts a numeric value, e.g. %x41, %x41-5A or %d13.10.
+func numVal(n *ast.Node) (interface{}, error) {
+	base := 16
+	switch n.Value[1] {
+	case 'b', 'B':
+		base = 2
+	case 'd', 'D':
+		base = 10
+	}
+	parse := func(s string) (rune, error) {
+		r, err := strconv.ParseUint(s, base, 32)
+		if err != nil || !utf8.ValidRune(rune(r)) {
+			return 0, loadError(n, "invalid numeric value %s", n.Value)
+		}
+		return rune(r), nil
+	}
+
+	s := n.Value[2:]
+	if i := strings.IndexRune(s, '-'); i != -1 {
+		min, err := parse(s[:i])
+		if err != nil {
+			return nil, err
+		}
+		max, err := parse(s[i+1:])
+		if err != nil {
+			return nil, err
+		}
+		if max < min {
+			return nil, loadError(n, "invalid range %s", n.Value)
+		}
+		return parser.CheckRuneRange(min, max), nil
+	}
+	var runes []rune
+	for _, s := range strings.Split(s, ".") {
+		r, err := parse(s)
+		if err != nil {
+			return nil, err
+		}
+		runes = append(runes, r)
+	}
+	if len(runes) == 1 {
+		return runes[0], nil
+	}
+	return string(runes), nil
+}
+
+// abnfCore contains the core rules of ABNF (RFC 5234, appendix B.1).
+var abnfCore = map[string]interface{}{
+	"ALPHA":  ascii.Alpha,
+	"BIT":    op.Or{'0', '1'},
+	"CHAR":   parser.CheckRuneRange(0x01, 0x7F),
+	"CR":     '\r',
+	"CRLF":   "\r\n",
+	"CTL":    ascii.Control,
+	"DIGIT":  ascii.Digit,
+	"DQUOTE": '"',
+	"HEXDIG": ascii.HexDigit,
+	"HTAB":   '\t',
+	"LF":     '\n',
+	"LWSP":   op.MinZero(op.Or{' ', '\t', op.And{"\r\n", op.Or{' ', '\t'}}}),
+	"OCTET":  parser.CheckRuneRange(0x00, 0xFF),
+	"SP":     ' ',
+	"VCHAR":  parser.CheckRuneRange(0x21, 0x7E),
+	"WSP":    op.Or{' ', '\t'},
+}
+
+// Node types of an ABNF source.
+const (
+	abnfRulelist = iota
+	abnfRule
+	abnfName
+	abnfDefinedAs
+	abnfAlternation
+	abnfConcatenation
+	abnfRepetition
+	abnfRepeat
+	abnfOption
+	abnfReference
+	abnfString
+	abnfNumber
+	abnfProse
+)
+
+var abnfTypes = []string{
+	"Rulelist",
+	"Rule",
+	"Name",
+	"DefinedAs",
+	"Alternation",
+	"Concatenation",
+	"Repetition",
+	"Repeat",
+	"Option",
+	"Reference",
+	"String",
+	"Number",
+	"Prose",
+}
+
+// abnfTrivia matches the whitespace and comments between the tokens of an ABNF
+// source.
+var abnfTrivia = op.Or{' ', '\t', '\r', '\n', parser.CheckLineComment(";")}
+
+// abnf is the grammar of an ABNF source.
+var abnf = func() *Grammar {
+	capture := func(t int, value interface{}) ast.Capture {
+		return ast.Capture{Type: t, TypeStrings: abnfTypes, Value: value}
+	}
+	name := parser.CheckIdentifier(
+		func(r rune) bool {
+			return ascii.Alpha.Contains(r)
+		},
+		func(r rune) bool {
+			return r == '-' || ascii.AlphaNum.Contains(r)
+		},
+	)
+	definedAs := op.Or{"=/", '='}
+	digits := op.MinOne(ascii.Digit)
+
+	g := New()
+	g.Define("Rulelist", capture(abnfRulelist, op.And{
+		op.MinZero(g.Ref("Rule")),
+		parser.EOD,
+	}))
+	g.Define("Rule", capture(abnfRule, op.And{
+		capture(abnfName, name),
+		capture(abnfDefinedAs, definedAs),
+		g.Ref("Alternation"),
+	}))
+	g.Define("Alternation", capture(abnfAlternation, op.And{
+		g.Ref("Concatenation"),
+		op.MinZero(op.And{'/', g.Ref("Concatenation")}),
+	}))
+	g.Define("Concatenation", capture(abnfConcatenation, op.MinOne(g.Ref("Repetition"))))
+	g.Define("Repetition", capture(abnfRepetition, op.And{
+		op.Optional(capture(abnfRepeat, op.Lexeme{Value: op.Or{
+			op.And{op.Optional(digits), '*', op.Optional(digits)},
+			digits,
+		}})),
+		g.Ref("Element"),
+	}))
+	g.Define("Element", op.Or{
+		op.And{'(', g.Ref("Alternation"), ')'},
+		capture(abnfOption, op.And{'[', g.Ref("Alternation"), ']'}),
+		capture(abnfReference, op.And{name, op.Not{Value: definedAs}}),
+		capture(abnfString, op.Lexeme{Value: op.And{
+			op.Optional(op.CaseInsensitive{Value: op.Or{"%s", "%i"}}),
+			parser.Quoted{Open: '"'},
+		}}),
+		capture(abnfNumber, op.Lexeme{Value: op.And{
+			'%',
+			op.CaseInsensitive{Value: op.Or{'b', 'd', 'x'}},
+			op.MinOne(ascii.HexDigit),
+			op.MinZero(op.And{op.Or{'.', '-'}, op.MinOne(ascii.HexDigit)}),
+		}}),
+		capture(abnfProse, op.Lexeme{Value: op.And{
+			'<', op.MinZero(parser.CheckNotRune('>')), '>',
+		}}),
+	})
+	return g
+}()
@@ -0,0 +1,154 @@
+package grammar_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/grammar"
+	"github.com/di-wu/parser/op"
+)
+
+func ExampleFromABNF() {
+	g, _ := grammar.FromABNF([]byte(`
+; RFC 3339 (simplified)
+full-date      = date-fullyear "-" date-month "-" date-mday
+date-fullyear  = 4DIGIT
+date-month     = 2DIGIT  ; 01-12
+date-mday      = 2DIGIT  ; 01-28, 01-29, 01-30, 01-31
+`))
+
+	p, _ := ast.New([]byte("2006-01-02"))
+	fmt.Println(p.Expect(g.Ref("full-date")))
+	// Output:
+	// ["full-date",[["date-fullyear","2006"],["date-month","01"],["date-mday","02"]]] <nil>
+}
+
+func TestFromABNF(t *testing.T) {
+	for _, test := range []struct {
+		grammar string
+		input   string
+		ok      bool
+	}{
+		{grammar: `a = "abc"`, input: "ABC", ok: true},
+		{grammar: `a = %s"abc"`, input: "ABC"},
+		{grammar: `a = %s"abc"`, input: "abc", ok: true},
+		{grammar: `a = %i"abc"`, input: "aBc", ok: true},
+		{grammar: `a = %x41 %d66 %b1000011`, input: "ABC", ok: true},
+		{grammar: `a = %x41.42.43`, input: "ABC", ok: true},
+		{grammar: `a = 1*%x30-39`, input: "0123", ok: true},
+		{grammar: `a = 1*%x30-39`, input: ""},
+		{grammar: `a = 2*3ALPHA`, input: "abc", ok: true},
+		{grammar: `a = 2*3ALPHA`, input: "abcd"},
+		{grammar: `a = *2ALPHA DIGIT`, input: "1", ok: true},
+		{grammar: `a = 3ALPHA`, input: "ab"},
+		{grammar: `a = 0*DIGIT "x"`, input: "12x", ok: true},
+		{grammar: `a = 0DIGIT "x"`, input: "x", ok: true},
+		{grammar: `a = 0DIGIT "x"`, input: "1x"},
+		{grammar: `a = [ "+" / "-" ] DIGIT`, input: "-1", ok: true},
+		{grammar: `a = [ "+" / "-" ] DIGIT`, input: "1", ok: true},
+		{grammar: `a = ("x" / "y") CRLF`, input: "y\r\n", ok: true},
+		// Alternatives are not ordered.
+		{grammar: `a = "a" / "ab"`, input: "ab", ok: true},
+		// Rule names are case insensitive.
+		{grammar: "a = B\nb = \"b\"", input: "b", ok: true},
+		// Incremental alternatives.
+		{grammar: "a = \"a\"\nb = \"b\"\na =/ \"c\"\na =/ b", input: "c", ok: true},
+		{grammar: "a = \"a\"\nb = \"b\"\na =/ \"c\"\na =/ b", input: "b", ok: true},
+		{grammar: "a = \"a\"\nb = \"b\"\na =/ \"c\"\na =/ b", input: "d"},
+		// Rules can continue on the next line.
+		{grammar: "a = \"a\"\n    \"b\" ; comment\nb = \"c\"", input: "ab", ok: true},
+	} {
+		g, err := grammar.FromABNF([]byte(test.grammar))
+		if err != nil {
+			t.Fatal(err)
+		}
+		internal, _ := parser.New([]byte(test.input + "\n"))
+		p, _ := ast.NewFromParser(internal)
+		_, err = p.Expect(op.And{g.Ref(g.Names()[0]), '\n', parser.EOD})
+		if ok := err == nil; ok != test.ok {
+			t.Errorf("%q on %q: expected %v, got %v", test.grammar, test.input, test.ok, err)
+		}
+	}
+}
+
+func TestFromABNF_uri(t *testing.T) {
+	// RFC 3986, appendix A (without IPv6 addresses).
+	g, err := grammar.FromABNF([]byte(`
+URI           = scheme ":" hier-part [ "?" query ] [ "#" fragment ]
+hier-part     = "//" authority path-abempty
+              / path-absolute
+              / path-rootless
+              / path-empty
+scheme        = ALPHA *( ALPHA / DIGIT / "+" / "-" / "." )
+authority     = [ userinfo "@" ] host [ ":" port ]
+userinfo      = *( unreserved / pct-encoded / sub-delims / ":" )
+host          = IP-literal / IPv4address / reg-name
+port          = *DIGIT
+IP-literal    = "[" ( IPv6address / IPvFuture  ) "]"
+IPvFuture     = "v" 1*HEXDIG "." 1*( unreserved / sub-delims / ":" )
+IPv6address   =                            6( h16 ":" ) ls32
+              /                       "::" 5( h16 ":" ) ls32
+h16           = 1*4HEXDIG
+ls32          = ( h16 ":" h16 ) / IPv4address
+IPv4address   = dec-octet "." dec-octet "." dec-octet "." dec-octet
+dec-octet     = DIGIT                 ; 0-9
+              / %x31-39 DIGIT         ; 10-99
+              / "1" 2DIGIT            ; 100-199
+              / "2" %x30-34 DIGIT     ; 200-249
+              / "25" %x30-35          ; 250-255
+reg-name      = *( unreserved / pct-encoded / sub-delims )
+path-abempty  = *( "/" segment )
+path-absolute = "/" [ segment-nz *( "/" segment ) ]
+path-rootless = segment-nz *( "/" segment )
+path-empty    = 0<pchar>
+segment       = *pchar
+segment-nz    = 1*pchar
+pchar         = unreserved / pct-encoded / sub-delims / ":" / "@"
+query         = *( pchar / "/" / "?" )
+fragment      = *( pchar / "/" / "?" )
+pct-encoded   = "%" HEXDIG HEXDIG
+unreserved    = ALPHA / DIGIT / "-" / "." / "_" / "~"
+sub-delims    = "!" / "$" / "&" / "'" / "(" / ")"
+              / "*" / "+" / "," / ";" / "="
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, uri := range []string{
+		"http://user@example.com:80/a/b?c=d#e",
+		"http://192.168.0.1/",
+		"mailto:a@b.c",
+		"urn:",
+	} {
+		p, _ := ast.New([]byte(uri))
+		n, err := p.Expect(op.And{g.Ref("URI"), parser.EOD})
+		if err != nil {
+			t.Errorf("%s: %v", uri, err)
+		} else if n.FirstChild.TypeString() != "URI" {
+			t.Errorf("%s: got %s", uri, n)
+		}
+	}
+}
+
+func TestFromABNF_errors(t *testing.T) {
+	for _, test := range []struct {
+		grammar string
+		err     string
+	}{
+		{grammar: "a = \"a\"\nA = \"b\"", err: "grammar [01:000]: A is defined more than once"},
+		{grammar: "a =/ \"a\"", err: "grammar [00:000]: a is not defined"},
+		{grammar: "a = %x5A-41", err: "grammar [00:004]: invalid range %x5A-41"},
+		{grammar: "a = %b12", err: "grammar [00:004]: invalid numeric value %b12"},
+		{grammar: "a = 3*2\"a\"", err: "grammar [00:004]: invalid repeat 3*2"},
+		{grammar: "a = <prose>", err: "grammar [00:004]: prose value <prose> is not supported"},
+		{grammar: "a = (\"a\"", err: "parse conflict"},
+	} {
+		_, err := grammar.FromABNF([]byte(test.grammar))
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%q: expected %q, got %v", test.grammar, test.err, err)
+		}
+	}
+}
@@ -0,0 +1,403 @@
+package grammar
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ascii"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+)
+
+// TypeFunc maps the name of a rule to the type of the ast.Capture that the rule
+// results in. Returns false if the rule should not be captured.
+type TypeFunc func(name string) (int, bool)
+
+// FromEBNF creates a grammar from the given EBNF source. Both the ISO 14977
+// style, e.g.
+//
+//	digit  = "0" | "1" | "2" | "3" | "4" | "5" | "6" | "7" | "8" | "9" ;
+//	number = [ "-" ], digit, { digit } ;
+//
+// and the W3C (XML) style are supported, e.g.
+//
+//	Number ::= '-'? [0-9]+
+//
+// The style is chosen per rule, based on its defining symbol ("=" or "::="), so
+// both styles can be mixed.
+//
+// ISO rules support concatenations (','), alternatives ('|'), exceptions ('-'),
+// options ('[ ]'), repetitions ('{ }' and 'n *') and groups. W3C rules support
+// sequences, alternatives, exceptions, the '?', '*' and '+' quantifiers, groups,
+// hexadecimal runes (#x2B) and character classes ([a-z] or [^#x0A]). Special
+// sequences ('? ... ?') are not supported.
+//
+// The given function decides which rules result in an ast.Capture, and of which
+// type. If it is nil, every rule results in an ast.Capture of which the type is
+// the index of the rule. The names of the rules are used as type strings.
+//
+// Alternatives are not ordered in EBNF, so the alternative that consumes the
+// most input is chosen (see op.Longest). Repetitions are greedy and do not
+// backtrack.
+func FromEBNF(src []byte, types TypeFunc) (*Grammar, error) {
+	p, err := parser.New(src, parser.WithTrivia(ebnfTrivia))
+	if err != nil {
+		return nil, err
+	}
+	ap, err := ast.NewFromParser(p)
+	if err != nil {
+		return nil, err
+	}
+	root, err := ap.Expect(ebnf.Ref("Grammar"))
+	if err != nil {
+		return nil, err
+	}
+
+	l := ebnfLoader{
+		g:     New(),
+		names: make(map[string]bool),
+	}
+	rules := root.Children()
+	for _, r := range rules {
+		name := r.FirstChild
+		if l.names[name.Value] {
+			return nil, loadError(name, "%s is defined more than once", name.Value)
+		}
+		l.names[name.Value] = true
+		if types == nil {
+			l.types = append(l.types, name.Value)
+			continue
+		}
+		if t, ok := types(name.Value); ok && 0 <= t {
+			for len(l.types) <= t {
+				l.types = append(l.types, "UNKNOWN")
+			}
+			l.types[t] = name.Value
+		}
+	}
+	for i, r := range rules {
+		name := r.FirstChild
+		value, err := l.expression(name.NextSibling)
+		if err != nil {
+			return nil, err
+		}
+		t, ok := i, true
+		if types != nil {
+			t, ok = types(name.Value)
+		}
+		if ok {
+			value = ast.Capture{
+				Type:        t,
+				TypeStrings: l.types,
+				Value:       value,
+			}
+		}
+		l.g.Define(name.Value, value)
+	}
+	return l.g, nil
+}
+
+// ebnfLoader converts the rules of an EBNF source to rules.
+type ebnfLoader struct {
+	g *Grammar
+	// names contains the names of all the rules that are defined in the source.
+	names map[string]bool
+	// types contains the names of the captured rules, indexed by their type.
+	types []string
+}
+
+// expression converts the given node to the value it represents.
+func (l *ebnfLoader) expression(n *ast.Node) (interface{}, error) {
+	switch n.Type {
+	case ebnfChoice, ebnfSequence:
+		var values []interface{}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			value, err := l.expression(c)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		if len(values) == 1 {
+			return values[0], nil
+		}
+		if n.Type == ebnfChoice {
+			return op.Longest(values), nil
+		}
+		return op.And(values), nil
+	case ebnfTerm:
+		value, err := l.expression(n.FirstChild)
+		if err != nil {
+			return nil, err
+		}
+		if n.FirstChild == n.LastChild {
+			return value, nil
+		}
+		exception, err := l.expression(n.LastChild)
+		if err != nil {
+			return nil, err
+		}
+		return op.And{op.Not{Value: exception}, value}, nil
+	case ebnfFactor:
+		if n.FirstChild == n.LastChild {
+			return l.expression(n.FirstChild)
+		}
+		if count := n.FirstChild; count.Type == ebnfCount {
+			// ISO: the count precedes the value.
+			value, err := l.expression(n.LastChild)
+			if err != nil {
+				return nil, err
+			}
+			c, err := strconv.Atoi(count.Value)
+			if err != nil {
+				return nil, loadError(count, "invalid count %s", count.Value)
+			}
+			if c == 0 {
+				// Zero repetitions (e.g. 0 * "a") match the empty string.
+				return op.And{}, nil
+			}
+			return op.Repeat(c, value), nil
+		}
+		// W3C: the quantifier follows the value.
+		value, err := l.expression(n.FirstChild)
+		if err != nil {
+			return nil, err
+		}
+		switch n.LastChild.Value {
+		case "?":
+			return op.Optional(value), nil
+		case "*":
+			return op.MinZero(value), nil
+		default:
+			return op.MinOne(value), nil
+		}
+	case ebnfOptional:
+		value, err := l.expression(n.FirstChild)
+		if err != nil {
+			return nil, err
+		}
+		return op.Optional(value), nil
+	case ebnfRepeated:
+		value, err := l.expression(n.FirstChild)
+		if err != nil {
+			return nil, err
+		}
+		return op.MinZero(value), nil
+	case ebnfReference:
+		return l.g.Ref(n.Value), nil
+	case ebnfString:
+		s := n.Value[1 : len(n.Value)-1]
+		if utf8.RuneCountInString(s) == 1 {
+			r, _ := utf8.DecodeRuneInString(s)
+			return r, nil
+		}
+		return s, nil
+	case ebnfHex:
+		return ebnfRune(n, n.Value)
+	case ebnfClass:
+		return ebnfCharClass(n)
+	case ebnfSpecial:
+		return nil, loadError(n, "special sequence %s is not supported", n.Value)
+	default:
+		return nil, loadError(n, "unexpected %s", n.TypeString())
+	}
+}
+
+// ebnfRune returns the rune of the given hexadecimal value, e.g. #x2B.
+func ebnfRune(n *ast.Node, s string) (rune, error) {
+	r, err := strconv.ParseUint(s[2:], 16, 32)
+	if err != nil || !utf8.ValidRune(rune(r)) {
+		return 0, loadError(n, "invalid rune %s", s)
+	}
+	return rune(r), nil
+}
+
+// ebnfCharClass converts a character class, e.g. [a-zA-Z] or [^#x0A].
+func ebnfCharClass(n *ast.Node) (interface{}, error) {
+	type runeRange struct{ min, max rune }
+	var ranges []runeRange
+
+	class := n.Value[1 : len(n.Value)-1]
+	negated := strings.HasPrefix(class, "^")
+	if negated {
+		class = class[1:]
+	}
+	next := func() (rune, error) {
+		if strings.HasPrefix(class, "#x") {
+			end := 2
+			for end < len(class) && ascii.HexDigit.Contains(rune(class[end])) {
+				end++
+			}
+			r, err := ebnfRune(n, class[:end])
+			class = class[end:]
+			return r, err
+		}
+		r, size := utf8.DecodeRuneInString(class)
+		class = class[size:]
+		return r, nil
+	}
+	for class != "" {
+		min, err := next()
+		if err != nil {
+			return nil, err
+		}
+		max := min
+		if 1 < len(class) && class[0] == '-' {
+			class = class[1:]
+			if max, err = next(); err != nil {
+				return nil, err
+			}
+			if max < min {
+				return nil, loadError(n, "invalid range %q-%q", min, max)
+			}
+		}
+		ranges = append(ranges, runeRange{min: min, max: max})
+	}
+	return parser.CheckRuneFunc(func(r rune) bool {
+		if r == parser.EOD {
+			return false
+		}
+		for _, rr := range ranges {
+			if rr.min <= r && r <= rr.max {
+				return !negated
+			}
+		}
+		return negated
+	}), nil
+}
+
+// Node types of an EBNF source.
+const (
+	ebnfGrammar = iota
+	ebnfRule
+	ebnfName
+	ebnfChoice
+	ebnfSequence
+	ebnfTerm
+	ebnfFactor
+	ebnfCount
+	ebnfQuantifier
+	ebnfOptional
+	ebnfRepeated
+	ebnfReference
+	ebnfString
+	ebnfHex
+	ebnfClass
+	ebnfSpecial
+)
+
+var ebnfTypes = []string{
+	"Grammar",
+	"Rule",
+	"Name",
+	"Choice",
+	"Sequence",
+	"Term",
+	"Factor",
+	"Count",
+	"Quantifier",
+	"Optional",
+	"Repeated",
+	"Reference",
+	"String",
+	"Hex",
+	"Class",
+	"Special",
+}
+
+// ebnfTrivia matches the whitespace and comments between the tokens of an EBNF
+// source.
+var ebnfTrivia = op.Or{
+	' ', '\t', '\r', '\n',
+	parser.CheckBlockComment("(*", "*)", false),
+	parser.CheckBlockComment("/*", "*/", false),
+}
+
+// ebnf is the grammar of an EBNF source.
+var ebnf = func() *Grammar {
+	capture := func(t int, value interface{}) ast.Capture {
+		return ast.Capture{Type: t, TypeStrings: ebnfTypes, Value: value}
+	}
+	name := parser.CheckIdentifier(
+		func(r rune) bool {
+			return r == '_' || ascii.Alpha.Contains(r)
+		},
+		func(r rune) bool {
+			return r == '_' || ascii.AlphaNum.Contains(r)
+		},
+	)
+	str := capture(ebnfString, op.Or{
+		parser.Quoted{Open: '"'},
+		parser.Quoted{Open: '\''},
+	})
+
+	g := New()
+	g.Define("Grammar", capture(ebnfGrammar, op.And{
+		op.MinZero(op.Or{g.Ref("W3CRule"), g.Ref("ISORule")}),
+		parser.EOD,
+	}))
+
+	// ISO 14977
+	g.Define("ISORule", capture(ebnfRule, op.And{
+		capture(ebnfName, name),
+		'=',
+		g.Ref("ISOChoice"),
+		op.Or{';', '.'},
+	}))
+	g.Define("ISOChoice", capture(ebnfChoice, op.And{
+		g.Ref("ISOSequence"),
+		op.MinZero(op.And{op.Or{'|', '/', '!'}, g.Ref("ISOSequence")}),
+	}))
+	g.Define("ISOSequence", capture(ebnfSequence, op.And{
+		g.Ref("ISOTerm"),
+		op.MinZero(op.And{',', g.Ref("ISOTerm")}),
+	}))
+	g.Define("ISOTerm", capture(ebnfTerm, op.And{
+		g.Ref("ISOFactor"),
+		op.Optional(op.And{'-', g.Ref("ISOFactor")}),
+	}))
+	g.Define("ISOFactor", capture(ebnfFactor, op.And{
+		op.Optional(op.And{capture(ebnfCount, op.Lexeme{Value: op.MinOne(ascii.Digit)}), '*'}),
+		op.Or{
+			op.And{'(', g.Ref("ISOChoice"), ')'},
+			capture(ebnfOptional, op.And{'[', g.Ref("ISOChoice"), ']'}),
+			capture(ebnfRepeated, op.And{'{', g.Ref("ISOChoice"), '}'}),
+			capture(ebnfSpecial, op.Lexeme{Value: op.And{
+				'?', op.MinZero(parser.CheckNotRune('?')), '?',
+			}}),
+			str,
+			capture(ebnfReference, name),
+		},
+	}))
+
+	// W3C
+	g.Define("W3CRule", capture(ebnfRule, op.And{
+		capture(ebnfName, name),
+		"::=",
+		g.Ref("W3CChoice"),
+	}))
+	g.Define("W3CChoice", capture(ebnfChoice, op.And{
+		g.Ref("W3CSequence"),
+		op.MinZero(op.And{'|', g.Ref("W3CSequence")}),
+	}))
+	g.Define("W3CSequence", capture(ebnfSequence, op.MinOne(g.Ref("W3CTerm"))))
+	g.Define("W3CTerm", capture(ebnfTerm, op.And{
+		g.Ref("W3CFactor"),
+		op.Optional(op.And{'-', g.Ref("W3CFactor")}),
+	}))
+	g.Define("W3CFactor", capture(ebnfFactor, op.And{
+		op.Or{
+			op.And{'(', g.Ref("W3CChoice"), ')'},
+			str,
+			capture(ebnfHex, op.Lexeme{Value: op.And{"#x", op.MinOne(ascii.HexDigit)}}),
+			capture(ebnfClass, op.Lexeme{Value: op.And{
+				'[', op.MinOne(parser.CheckNotRune(']')), ']',
+			}}),
+			capture(ebnfReference, op.And{name, op.Not{Value: op.Or{"::=", '='}}}),
+		},
+		op.Optional(capture(ebnfQuantifier, op.Or{'?', '*', '+'})),
+	}))
+	return g
+}()
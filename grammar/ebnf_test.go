@@ -0,0 +1,121 @@
+package grammar_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/grammar"
+	"github.com/di-wu/parser/op"
+)
+
+func ExampleFromEBNF() {
+	g, _ := grammar.FromEBNF([]byte(`
+(* ISO 14977 *)
+number = [ "-" ], digit, { digit } ;
+digit  = "0" | "1" | "2" | "3" | "4" | "5" | "6" | "7" | "8" | "9" ;
+`), func(name string) (int, bool) {
+		// Only capture numbers.
+		return 1, name == "number"
+	})
+
+	p, _ := ast.New([]byte("-42"))
+	fmt.Println(p.Expect(g.Ref("number")))
+	// Output:
+	// ["number","-42"] <nil>
+}
+
+func ExampleFromEBNF_w3c() {
+	g, _ := grammar.FromEBNF([]byte(`
+/* W3C */
+List   ::= '[' (Number (',' Number)*)? ']'
+Number ::= '-'? [0-9]+
+`), nil)
+
+	p, _ := ast.New([]byte("[1,-2]"))
+	fmt.Println(p.Expect(g.Ref("List")))
+	// Output:
+	// ["List",[["Number","1"],["Number","-2"]]] <nil>
+}
+
+func TestFromEBNF(t *testing.T) {
+	for _, test := range []struct {
+		grammar string
+		input   string
+		ok      bool
+	}{
+		{grammar: `a = "a", 'b' ;`, input: "ab", ok: true},
+		{grammar: `a = "a", 'b' ;`, input: "a"},
+		{grammar: `a = 3 * "a" .`, input: "aaa", ok: true},
+		{grammar: `a = 3 * "a" .`, input: "aa"},
+		{grammar: `a = "x", 0 * "a" ;`, input: "x", ok: true},
+		{grammar: `a = "x", 0 * "a" ;`, input: "xa"},
+		{grammar: `a = { "a" | "b" }, [ "c" ] ;`, input: "abbac", ok: true},
+		{grammar: `a = { "a" | "b" }, [ "c" ] ;`, input: "abcc"},
+		{grammar: `a = letter - "x" ; letter = "x" | "y" ;`, input: "y", ok: true},
+		{grammar: `a = letter - "x" ; letter = "x" | "y" ;`, input: "x"},
+		{grammar: `a = ( "a" | "b" ), "c" ;`, input: "bc", ok: true},
+		// Alternatives are not ordered.
+		{grammar: `a = "a" | "ab" ;`, input: "ab", ok: true},
+		{grammar: "a ::= 'a' b+\nb ::= #x62", input: "abb", ok: true},
+		{grammar: "a ::= 'a' b+\nb ::= #x62", input: "a"},
+		{grammar: "a ::= [a-cx#x30-#x39]*", input: "abx09", ok: true},
+		{grammar: "a ::= [a-cx#x30-#x39]*", input: "d"},
+		{grammar: "a ::= [^#x0A]+", input: "any", ok: true},
+		{grammar: "a ::= [^#x0A]+", input: "\n"},
+		{grammar: "a ::= [a-z]+ - 'no'", input: "yes", ok: true},
+		{grammar: "a ::= [a-z]+ - 'no'", input: "no"},
+		// Both styles can be mixed.
+		{grammar: "a ::= b c\nb = 'b' ;\nc ::= 'c'?", input: "b", ok: true},
+	} {
+		g, err := grammar.FromEBNF([]byte(test.grammar), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, _ := ast.New([]byte(test.input + "\n"))
+		_, err = p.Expect(op.And{g.Ref(g.Names()[0]), '\n', parser.EOD})
+		if ok := err == nil; ok != test.ok {
+			t.Errorf("%q on %q: expected %v, got %v", test.grammar, test.input, test.ok, err)
+		}
+	}
+}
+
+func TestFromEBNF_types(t *testing.T) {
+	g, err := grammar.FromEBNF([]byte("a ::= b c\nb ::= 'b'\nc ::= 'c'"), func(name string) (int, bool) {
+		switch name {
+		case "a":
+			return 1, true
+		case "c":
+			return 3, true
+		default:
+			return 0, false
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, _ := ast.New([]byte("bc"))
+	if n, err := p.Expect(g.Ref("a")); err != nil || n.String() != `["a",[["c","c"]]]` {
+		t.Errorf("unexpected result: %v %v", n, err)
+	}
+}
+
+func TestFromEBNF_errors(t *testing.T) {
+	for _, test := range []struct {
+		grammar string
+		err     string
+	}{
+		{grammar: "a = 'a' ;\na = 'b' ;", err: "grammar [01:000]: a is defined more than once"},
+		{grammar: "a ::= [z-a]", err: "grammar [00:006]: invalid range 'z'-'a'"},
+		{grammar: "a ::= #xFFFFFFFFF", err: "grammar [00:006]: invalid rune #xFFFFFFFFF"},
+		{grammar: "a = ? special ? ;", err: "grammar [00:004]: special sequence ? special ? is not supported"},
+		{grammar: "a = 'a'", err: "parse conflict"},
+	} {
+		_, err := grammar.FromEBNF([]byte(test.grammar), nil)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%q: expected %q, got %v", test.grammar, test.err, err)
+		}
+	}
+}
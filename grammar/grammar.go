@@ -0,0 +1,82 @@
+// Package grammar provides a registry of named rules. Rules can reference each
+// other (and themselves) by name, regardless of the order in which they are
+// defined.
+package grammar
+
+import (
+	"strings"
+
+	"github.com/di-wu/parser/op"
+)
+
+// Grammar is a set of named rules.
+type Grammar struct {
+	rules map[string]interface{}
+	// names contains the names of the rules in the order they got defined.
+	names []string
+	// imports contains the imported grammars by their namespace.
+	imports map[string]*Grammar
+}
+
+// New creates a new empty Grammar.
+func New() *Grammar {
+	return &Grammar{
+		rules: make(map[string]interface{}),
+	}
+}
+
+// Define defines a rule with the given name. Redefining a rule replaces its
+// value, references to the rule resolve to the new value.
+func (g *Grammar) Define(name string, value interface{}) op.Rule {
+	if _, ok := g.rules[name]; !ok {
+		g.names = append(g.names, name)
+	}
+	g.rules[name] = value
+	return g.Ref(name)
+}
+
+// Ref returns a reference to the rule with the given name. The rule does not
+// need to be defined yet, it gets resolved when it is matched.
+func (g *Grammar) Ref(name string) op.Rule {
+	return op.Rule{
+		Name:  name,
+		Rules: g,
+	}
+}
+
+// Import makes the rules of the given grammar available under the given
+// namespace, e.g. after g.Import("json", j), g.Ref("json.String") refers to the
+// String rule of j. The namespace should not contain a '.'. Importing another
+// grammar under the same namespace replaces the previous import.
+//
+// Imported rules are resolved when they are matched, so rules that are defined
+// in the imported grammar afterwards are available as well. The rules of the
+// imported grammar keep referring to the rules of that grammar. Rules that are
+// defined with the full name (e.g. "json.String") take priority over imported
+// rules.
+func (g *Grammar) Import(namespace string, other *Grammar) {
+	if g.imports == nil {
+		g.imports = make(map[string]*Grammar)
+	}
+	g.imports[namespace] = other
+}
+
+// Lookup returns the value of the rule with the given name, which can also be
+// the name of an imported rule (e.g. "json.String").
+func (g *Grammar) Lookup(name string) (interface{}, bool) {
+	if value, ok := g.rules[name]; ok {
+		return value, true
+	}
+	if i := strings.IndexByte(name, '.'); i != -1 {
+		if other, ok := g.imports[name[:i]]; ok {
+			return other.Lookup(name[i+1:])
+		}
+	}
+	return nil, false
+}
+
+// Names returns the names of all the defined rules, in the order they got
+// defined. Imported rules are not included.
+func (g *Grammar) Names() []string {
+	return append([]string(nil), g.names...)
+}
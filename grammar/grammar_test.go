@@ -0,0 +1,151 @@
+package grammar_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/grammar"
+	"github.com/di-wu/parser/op"
+	"testing"
+)
+
+func ExampleGrammar() {
+	g := grammar.New()
+	// Expr refers to Term, which is only defined later on.
+	g.Define("Expr", op.And{g.Ref("Term"), op.MinZero(op.And{'+', g.Ref("Term")})})
+	g.Define("Term", op.Or{
+		op.And{'(', g.Ref("Expr"), ')'},
+		parser.CheckRuneRange('0', '9'),
+	})
+
+	p, _ := parser.New([]byte("(1+(2+3))+4"))
+	fmt.Println(p.Expect(g.Ref("Expr")))
+
+	p, _ = parser.New([]byte("(1+2"))
+	fmt.Println(p.Expect(g.Ref("Expr")))
+	// Output:
+	// U+0034: 4 <nil>
+	// <nil> while parsing Expr: parse conflict [00:004]: expected '(' Expr ')', '+' or ')' but got '2'
+}
+
+func ExampleGrammar_ast() {
+	g := grammar.New()
+	g.Define("List", ast.Capture{
+		Type: 1,
+		Value: op.And{
+			'[',
+			op.Optional(op.And{
+				g.Ref("Value"),
+				op.MinZero(op.And{',', g.Ref("Value")}),
+			}),
+			']',
+		},
+	})
+	g.Define("Value", op.Or{
+		g.Ref("List"),
+		ast.Capture{Type: 2, Value: parser.CheckRuneRange('a', 'z')},
+	})
+
+	p, _ := ast.New([]byte("[a,[b],[]]"))
+	fmt.Println(p.Expect(g.Ref("List")))
+	// Output:
+	// ["UNKNOWN",[["UNKNOWN","a"],["UNKNOWN",[["UNKNOWN","b"]]],["UNKNOWN","[]"]]] <nil>
+}
+
+func TestGrammar_Define(t *testing.T) {
+	g := grammar.New()
+	g.Define("a", 'a')
+	g.Define("b", 'b')
+	g.Define("a", 'c')
+
+	if names := g.Names(); len(names) != 2 || names[0] != "a" || names[1] != "b" {
+		t.Error(names)
+	}
+	p, _ := parser.New([]byte("c"))
+	if _, err := p.Expect(g.Ref("a")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGrammar_undefined(t *testing.T) {
+	g := grammar.New()
+	g.Define("a", op.Or{g.Ref("b"), 'a'})
+
+	p, _ := parser.New([]byte("a"))
+	if _, err := p.Expect(g.Ref("a")); err != nil {
+		t.Error(err)
+	}
+	p, _ = parser.New([]byte("a"))
+	_, err := p.Expect(g.Ref("b"))
+	if _, ok := err.(*parser.UndefinedRuleError); !ok {
+		t.Error(err)
+	}
+}
+
+func TestGrammar_unclosed(t *testing.T) {
+	g := grammar.New()
+	g.Define("close", ')')
+	g.Define("group", op.Between{Open: '(', Body: op.MinZero('a'), Close: g.Ref("close")})
+
+	p, _ := parser.New([]byte("(aa"))
+	_, err := p.Expect(g.Ref("group"))
+	var conflict *parser.ExpectedParseError
+	if !errors.As(err, &conflict) || conflict.Unclosed == nil {
+		t.Error(err)
+	}
+}
+
+func ExampleGrammar_Import() {
+	common := grammar.New()
+	common.Define("Integer", op.MinOne(parser.CheckRuneRange('0', '9')))
+	common.Define("Space", op.MinZero(' '))
+
+	g := grammar.New()
+	g.Import("common", common)
+	g.Define("Sum", op.And{
+		g.Ref("common.Integer"),
+		op.MinZero(op.And{g.Ref("common.Space"), '+', g.Ref("common.Space"), g.Ref("common.Integer")}),
+	})
+
+	p, _ := parser.New([]byte("1 + 23"))
+	fmt.Println(p.Expect(g.Ref("Sum")))
+	// Output:
+	// U+0033: 3 <nil>
+}
+
+func TestGrammar_Import(t *testing.T) {
+	inner := grammar.New()
+	inner.Define("a", 'a')
+	outer := grammar.New()
+	outer.Import("inner", inner)
+	g := grammar.New()
+	g.Import("outer", outer)
+	g.Define("start", op.And{g.Ref("outer.inner.a"), g.Ref("outer.inner.b")})
+
+	// Rules that are defined afterwards are available as well.
+	inner.Define("b", 'b')
+	p, _ := parser.New([]byte("ab"))
+	if _, err := p.Expect(g.Ref("start")); err != nil {
+		t.Error(err)
+	}
+	if diagnostics := g.Validate(); len(diagnostics) != 0 {
+		t.Error(diagnostics)
+	}
+	if names := g.Names(); len(names) != 1 {
+		t.Error(names)
+	}
+
+	// Defined rules take priority over imported rules.
+	g.Define("outer.inner.b", 'c')
+	p, _ = parser.New([]byte("ac"))
+	if _, err := p.Expect(g.Ref("start")); err != nil {
+		t.Error(err)
+	}
+
+	for _, name := range []string{"outer.c", "other.a", "outer"} {
+		if _, ok := g.Lookup(name); ok {
+			t.Errorf("expected %s to be undefined", name)
+		}
+	}
+}
@@ -0,0 +1,379 @@
+package grammar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ascii"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+)
+
+// LoadError indicates a problem in the definition of a grammar that is loaded
+// from its source, e.g. a rule that is defined more than once.
+type LoadError struct {
+	// The position of the problem in the source.
+	Cursor parser.Cursor
+	// Message describes the problem.
+	Message string
+}
+
+func (e *LoadError) Error() string {
+	row, column := e.Cursor.Position()
+	return fmt.Sprintf("grammar [%02d:%03d]: %s", row, column, e.Message)
+}
+
+// loadError returns a LoadError at the start of the given node.
+func loadError(n *ast.Node, format string, a ...interface{}) error {
+	var cursor parser.Cursor
+	if n.Start != nil {
+		cursor = *n.Start
+	}
+	return &LoadError{
+		Cursor:  cursor,
+		Message: fmt.Sprintf(format, a...),
+	}
+}
+
+// FromPEGN creates a grammar from the given PEGN source, e.g.
+//
+//	Sum     <-- Integer (SP* '+' SP* Integer)*
+//	Integer <-- [1-9] [0-9]* / '0'
+//
+// Rules defined with "<--" result in an ast.Capture, of which the type is the
+// index of the rule among the captured rules. Other rules ("<-" and "<=") only
+// match their expression. Names that are not defined in the source refer to the
+// reserved PEGN classes and tokens (e.g. alpha, digit, SP or CRLF), or to rules
+// that can still be defined afterwards.
+//
+// Supported expressions are sequences, choices ('/'), groups, the '!' and '&'
+// lookaheads, the '?', '*', '+' and '{n,m}' quantifiers, literals ('abc'),
+// hexadecimal runes (x2B) and rune ranges ([a-z] or [x00-x7F]).
+func FromPEGN(src []byte) (*Grammar, error) {
+	p, err := parser.New(src, parser.WithTrivia(pegnTrivia))
+	if err != nil {
+		return nil, err
+	}
+	ap, err := ast.NewFromParser(p)
+	if err != nil {
+		return nil, err
+	}
+	root, err := ap.Expect(pegn.Ref("Grammar"))
+	if err != nil {
+		return nil, err
+	}
+
+	l := pegnLoader{
+		g:     New(),
+		names: make(map[string]bool),
+	}
+	definitions := root.Children()
+	for _, d := range definitions {
+		name := d.FirstChild
+		if l.names[name.Value] {
+			return nil, loadError(name, "%s is defined more than once", name.Value)
+		}
+		l.names[name.Value] = true
+		if name.NextSibling.Value == "<--" {
+			l.types = append(l.types, name.Value)
+		}
+	}
+	for _, d := range definitions {
+		name, arrow := d.FirstChild, d.FirstChild.NextSibling
+		value, err := l.expression(arrow.NextSibling)
+		if err != nil {
+			return nil, err
+		}
+		if arrow.Value == "<--" {
+			value = ast.Capture{
+				Type:        l.typeOf(name.Value),
+				TypeStrings: l.types,
+				Value:       value,
+			}
+		}
+		l.g.Define(name.Value, value)
+	}
+	return l.g, nil
+}
+
+// pegnLoader converts the definitions of a PEGN source to rules.
+type pegnLoader struct {
+	g *Grammar
+	// names contains the names of all the rules that are defined in the source.
+	names map[string]bool
+	// types contains the names of the captured rules.
+	types []string
+}
+
+// typeOf returns the type of the captured rule with the given name.
+func (l *pegnLoader) typeOf(name string) int {
+	for i, t := range l.types {
+		if t == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// expression converts the given node to the value it represents.
+func (l *pegnLoader) expression(n *ast.Node) (interface{}, error) {
+	switch n.Type {
+	case pegnChoice, pegnSequence:
+		var values []interface{}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			value, err := l.expression(c)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		if len(values) == 1 {
+			return values[0], nil
+		}
+		if n.Type == pegnChoice {
+			return op.Or(values), nil
+		}
+		return op.And(values), nil
+	case pegnRule:
+		return l.rule(n)
+	case pegnReference:
+		if !l.names[n.Value] {
+			if value, ok := pegnReserved[n.Value]; ok {
+				return value, nil
+			}
+		}
+		return l.g.Ref(n.Value), nil
+	case pegnString:
+		s := n.Value[1 : len(n.Value)-1]
+		if utf8.RuneCountInString(s) == 1 {
+			r, _ := utf8.DecodeRuneInString(s)
+			return r, nil
+		}
+		return s, nil
+	case pegnHex:
+		return hexRune(n)
+	case pegnRange:
+		min, err := l.bound(n.FirstChild)
+		if err != nil {
+			return nil, err
+		}
+		max, err := l.bound(n.LastChild)
+		if err != nil {
+			return nil, err
+		}
+		if max < min {
+			return nil, loadError(n, "invalid range %q-%q", min, max)
+		}
+		return parser.CheckRuneRange(min, max), nil
+	default:
+		return nil, loadError(n, "unexpected %s", n.TypeString())
+	}
+}
+
+// rule converts a rule, its optional prefix and its optional quantifier.
+func (l *pegnLoader) rule(n *ast.Node) (interface{}, error) {
+	var prefix, quantifier *ast.Node
+	primary := n.FirstChild
+	if primary.Type == pegnPrefix {
+		prefix, primary = primary, primary.NextSibling
+	}
+	if primary.NextSibling != nil {
+		quantifier = primary.NextSibling
+	}
+
+	value, err := l.expression(primary)
+	if err != nil {
+		return nil, err
+	}
+	if quantifier != nil {
+		if value, err = quantify(quantifier, value); err != nil {
+			return nil, err
+		}
+	}
+	if prefix != nil {
+		if prefix.Value == "!" {
+			return op.Not{Value: value}, nil
+		}
+		return op.Ensure{Value: value}, nil
+	}
+	return value, nil
+}
+
+// bound returns the rune of the given bound of a range.
+func (l *pegnLoader) bound(n *ast.Node) (rune, error) {
+	if n.Type == pegnHex {
+		return hexRune(n)
+	}
+	r, _ := utf8.DecodeRuneInString(n.Value)
+	return r, nil
+}
+
+// hexRune returns the rune of the given hexadecimal node, e.g. x2B.
+func hexRune(n *ast.Node) (rune, error) {
+	r, err := strconv.ParseUint(n.Value[1:], 16, 32)
+	if err != nil || !utf8.ValidRune(rune(r)) {
+		return 0, loadError(n, "invalid rune %s", n.Value)
+	}
+	return rune(r), nil
+}
+
+// quantify applies the given quantifier (e.g. '*' or {2,4}) to the value.
+func quantify(n *ast.Node, value interface{}) (interface{}, error) {
+	switch n.Value {
+	case "?":
+		return op.Optional(value), nil
+	case "*":
+		return op.MinZero(value), nil
+	case "+":
+		return op.MinOne(value), nil
+	}
+	bounds := strings.Split(n.Value[1:len(n.Value)-1], ",")
+	min, max := 0, -1
+	for i, bound := range bounds {
+		bound = strings.TrimSpace(bound)
+		if bound == "" {
+			continue
+		}
+		v, err := strconv.Atoi(bound)
+		if err != nil {
+			return nil, loadError(n, "invalid quantifier %s", n.Value)
+		}
+		if i == 0 {
+			min = v
+		} else {
+			max = v
+		}
+	}
+	switch {
+	case len(bounds) == 1 && min == 0, max == 0:
+		// Zero repetitions (e.g. 'a'{0}) match the empty string.
+		return op.And{}, nil
+	case len(bounds) == 1:
+		return op.Repeat(min, value), nil
+	case max == -1:
+		return op.Min(min, value), nil
+	case max < min:
+		return nil, loadError(n, "invalid quantifier %s", n.Value)
+	default:
+		return op.MinMax(min, max, value), nil
+	}
+}
+
+// pegnReserved contains the reserved PEGN classes and tokens.
+var pegnReserved = map[string]interface{}{
+	"alpha":    ascii.Alpha,
+	"alphanum": ascii.AlphaNum,
+	"digit":    ascii.Digit,
+	"hexdig":   ascii.HexDigit,
+	"lower":    ascii.Lower,
+	"upper":    ascii.Upper,
+	"ws":       op.Or{' ', '\t', '\r', '\n'},
+
+	"TAB":  '\t',
+	"LF":   '\n',
+	"CR":   '\r',
+	"CRLF": "\r\n",
+	"SP":   ' ',
+}
+
+// Node types of a PEGN source.
+const (
+	pegnGrammar = iota
+	pegnDefinition
+	pegnName
+	pegnArrow
+	pegnChoice
+	pegnSequence
+	pegnRule
+	pegnPrefix
+	pegnQuantifier
+	pegnReference
+	pegnString
+	pegnHex
+	pegnRange
+	pegnRune
+)
+
+var pegnTypes = []string{
+	"Grammar",
+	"Definition",
+	"Name",
+	"Arrow",
+	"Choice",
+	"Sequence",
+	"Rule",
+	"Prefix",
+	"Quantifier",
+	"Reference",
+	"String",
+	"Hex",
+	"Range",
+	"Rune",
+}
+
+// pegnTrivia matches the whitespace and comments between the tokens of a PEGN
+// source.
+var pegnTrivia = op.Or{' ', '\t', '\r', '\n', parser.CheckLineComment("#")}
+
+// pegn is the grammar of a PEGN source.
+var pegn = func() *Grammar {
+	capture := func(t int, value interface{}) ast.Capture {
+		return ast.Capture{Type: t, TypeStrings: pegnTypes, Value: value}
+	}
+	name := parser.CheckIdentifier(
+		func(r rune) bool {
+			return ascii.Alpha.Contains(r)
+		},
+		func(r rune) bool {
+			return r == '_' || ascii.AlphaNum.Contains(r)
+		},
+	)
+	arrow := op.Or{"<--", "<-", "<="}
+	hex := capture(pegnHex, op.Lexeme{Value: op.And{
+		'x', op.MinOne(ascii.HexDigit), op.Not{Value: ascii.AlphaNum},
+	}})
+	digits := op.MinOne(ascii.Digit)
+
+	g := New()
+	g.Define("Grammar", capture(pegnGrammar, op.And{
+		op.MinZero(g.Ref("Definition")),
+		parser.EOD,
+	}))
+	g.Define("Definition", capture(pegnDefinition, op.And{
+		capture(pegnName, name),
+		capture(pegnArrow, arrow),
+		g.Ref("Choice"),
+	}))
+	g.Define("Choice", capture(pegnChoice, op.And{
+		g.Ref("Sequence"),
+		op.MinZero(op.And{'/', g.Ref("Sequence")}),
+	}))
+	g.Define("Sequence", capture(pegnSequence, op.MinOne(g.Ref("Rule"))))
+	g.Define("Rule", capture(pegnRule, op.And{
+		op.Optional(capture(pegnPrefix, op.Or{'!', '&'})),
+		g.Ref("Primary"),
+		op.Optional(capture(pegnQuantifier, op.Or{
+			'?', '*', '+',
+			op.Lexeme{Value: op.And{
+				'{', op.Optional(digits), op.Optional(op.And{',', op.Optional(digits)}), '}',
+			}},
+		})),
+	}))
+	g.Define("Primary", op.Or{
+		op.And{'(', g.Ref("Choice"), ')'},
+		hex,
+		capture(pegnReference, op.And{name, op.Not{Value: arrow}}),
+		capture(pegnString, parser.Quoted{Open: '\''}),
+		capture(pegnRange, op.Lexeme{Value: op.And{
+			'[',
+			op.Or{hex, capture(pegnRune, parser.CheckNotRune(']'))},
+			'-',
+			op.Or{hex, capture(pegnRune, parser.CheckNotRune(']'))},
+			']',
+		}}),
+	})
+	return g
+}()
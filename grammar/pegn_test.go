@@ -0,0 +1,110 @@
+package grammar_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/grammar"
+	"github.com/di-wu/parser/op"
+)
+
+func ExampleFromPEGN() {
+	g, _ := grammar.FromPEGN([]byte(`
+# CALC (v0.1.1) github.com/di-wu/parser/examples/calculator
+
+AddSubExpr <-- MulDivExpr (AddSub MulDivExpr)*
+MulDivExpr <-- Factor (MulDiv Factor)*
+Factor      <- Integer / '(' AddSubExpr ')'
+AddSub     <-- '+' / '-'
+MulDiv     <-- '*' / '/'
+Integer    <-- [0-9]+
+`))
+
+	p, _ := ast.New([]byte("(1+2)*3"))
+	fmt.Println(p.Expect(g.Ref("AddSubExpr")))
+	// Output:
+	// ["AddSubExpr",[["MulDivExpr",[["AddSubExpr",[["MulDivExpr",[["Integer","1"]]],["AddSub","+"],["MulDivExpr",[["Integer","2"]]]]],["MulDiv","*"],["Integer","3"]]]]] <nil>
+}
+
+func TestFromPEGN(t *testing.T) {
+	for _, test := range []struct {
+		grammar string
+		input   string
+		ok      bool
+	}{
+		{grammar: "A <- 'abc'", input: "abc", ok: true},
+		{grammar: "A <- 'abc'", input: "abd"},
+		{grammar: "A <- x41 x42{2}", input: "ABB", ok: true},
+		{grammar: "A <- x41 x42{2}", input: "AB"},
+		{grammar: "A <- [a-c]{1,2} [x30-x39]", input: "ab1", ok: true},
+		{grammar: "A <- [a-c]{1,2} [x30-x39]", input: "abc1"},
+		{grammar: "A <- !'a' alpha+ SP", input: "bc ", ok: true},
+		{grammar: "A <- !'a' alpha+ SP", input: "abc "},
+		{grammar: "A <- &'a' B\nB <- ('a' / 'b')? 'c'", input: "ac", ok: true},
+		{grammar: "A <- &'a' B\nB <- ('a' / 'b')? 'c'", input: "bc"},
+		{grammar: "A <- 'a'{2,} 'b'{,1} # comment", input: "aaab", ok: true},
+		{grammar: "A <- 'a'{2,} 'b'{,1} # comment", input: "ab"},
+		{grammar: "A <- 'x' 'a'{0}", input: "x", ok: true},
+		{grammar: "A <- 'x' 'a'{0}", input: "xa"},
+		{grammar: "A <- 'x' 'a'{0,0}", input: "x", ok: true},
+		{grammar: "A <- 'x' 'a'{0,0}", input: "xaaa"},
+		// Names that are defined take priority over reserved names.
+		{grammar: "A <- SP\nSP <- '_'", input: "_", ok: true},
+		{grammar: "A <- SP\nSP <- '_'", input: " "},
+	} {
+		g, err := grammar.FromPEGN([]byte(test.grammar))
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, _ := ast.New([]byte(test.input))
+		_, err = p.Expect(op.And{g.Ref(g.Names()[0]), parser.EOD})
+		if ok := err == nil; ok != test.ok {
+			t.Errorf("%q on %q: expected %v, got %v", test.grammar, test.input, test.ok, err)
+		}
+	}
+}
+
+func TestFromPEGN_errors(t *testing.T) {
+	for _, test := range []struct {
+		grammar string
+		err     string
+	}{
+		{grammar: "A <- 'a'\nA <- 'b'", err: "grammar [01:000]: A is defined more than once"},
+		{grammar: "A <- [z-a]", err: "grammar [00:005]: invalid range 'z'-'a'"},
+		{grammar: "A <- 'a'{3,2}", err: "grammar [00:008]: invalid quantifier {3,2}"},
+		{grammar: "A <- xFFFFFFFFF", err: "grammar [00:005]: invalid rune xFFFFFFFFF"},
+		{grammar: "A <- 'a' )", err: "[00:009]"},
+	} {
+		_, err := grammar.FromPEGN([]byte(test.grammar))
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%q: expected %q, got %v", test.grammar, test.err, err)
+		}
+	}
+}
+
+func TestFromPEGN_examples(t *testing.T) {
+	for _, file := range []string{
+		"../ast/grammar.pegn",
+		"../examples/calculator/grammar.pegn",
+		"../examples/circular/circular.pegn",
+		"../examples/elf/grammar.pegn",
+		"../examples/precedence/grammar.pegn",
+	} {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g, err := grammar.FromPEGN(src)
+		if err != nil {
+			t.Errorf("%s: %v", file, err)
+			continue
+		}
+		if diagnostics := g.Validate(); len(diagnostics) != 0 {
+			t.Errorf("%s: %v", file, diagnostics)
+		}
+	}
+}
@@ -0,0 +1,434 @@
+package grammar
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+	"strings"
+)
+
+// Kind indicates the kind of problem a Diagnostic reports.
+type Kind int
+
+const (
+	// UndefinedRule indicates a reference to a rule that is not defined.
+	UndefinedRule Kind = iota
+	// UnreachableRule indicates a rule that can not be reached from the first
+	// defined rule.
+	UnreachableRule
+	// LeftRecursion indicates a rule that (indirectly) references itself
+	// without consuming any input.
+	LeftRecursion
+	// NullableRepetition indicates an unbounded repetition of a value that can
+	// match without consuming any input.
+	NullableRepetition
+)
+
+func (k Kind) String() string {
+	switch k {
+	case UndefinedRule:
+		return "undefined rule"
+	case UnreachableRule:
+		return "unreachable rule"
+	case LeftRecursion:
+		return "left recursion"
+	case NullableRepetition:
+		return "nullable repetition"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// Diagnostic describes a problem in a grammar.
+type Diagnostic struct {
+	Kind Kind
+	// Rule is the name of the rule in which the problem was found.
+	Rule string
+	// Message describes the problem.
+	Message string
+}
+
+func (d Diagnostic) Error() string {
+	return fmt.Sprintf("grammar: %s: %s: %s", d.Rule, d.Kind, d.Message)
+}
+
+// Validate checks the grammar for problems, without parsing any input. The
+// first defined rule is considered to be the start rule. Left recursion that
+// passes through an op.Memo is supported by the parser, so it is not reported.
+//
+// Functions (e.g. classes) and lazy values can not be inspected. These are
+// assumed to consume input and to not reference any rules. The same goes for
+// imported rules, validate the imported grammar itself instead.
+func (g *Grammar) Validate() []Diagnostic {
+	v := validator{
+		g:        g,
+		nullable: make(map[string]bool),
+	}
+	v.computeNullable()
+
+	var diagnostics []Diagnostic
+	for _, name := range g.names {
+		var undefined []string
+		for _, ref := range v.refs(g.rules[name], false) {
+			if _, ok := g.Lookup(ref); !ok && !contains(undefined, ref) {
+				undefined = append(undefined, ref)
+				diagnostics = append(diagnostics, Diagnostic{
+					Kind:    UndefinedRule,
+					Rule:    name,
+					Message: fmt.Sprintf("%s is not defined", ref),
+				})
+			}
+		}
+	}
+	for _, name := range v.unreachable() {
+		diagnostics = append(diagnostics, Diagnostic{
+			Kind:    UnreachableRule,
+			Rule:    name,
+			Message: fmt.Sprintf("%s is not referenced by %s", name, g.names[0]),
+		})
+	}
+	var recursive []string
+	for _, name := range g.names {
+		if contains(recursive, name) {
+			continue
+		}
+		if cycle := v.cycle(name); cycle != nil {
+			recursive = append(recursive, cycle...)
+			diagnostics = append(diagnostics, Diagnostic{
+				Kind:    LeftRecursion,
+				Rule:    name,
+				Message: strings.Join(append(cycle, name), " -> "),
+			})
+		}
+	}
+	for _, name := range g.names {
+		v.walk(g.rules[name], func(i interface{}) {
+			var nullable bool
+			switch r := i.(type) {
+			case op.Range:
+				nullable = r.Max == -1 && v.isNullable(r.Value)
+			case op.SeparatedBy:
+				nullable = v.isNullable(r.Element) && v.isNullable(r.Separator)
+			}
+			if nullable {
+				diagnostics = append(diagnostics, Diagnostic{
+					Kind:    NullableRepetition,
+					Rule:    name,
+					Message: fmt.Sprintf("%s can match without consuming input", parser.Stringer(i)),
+				})
+			}
+		})
+	}
+	return diagnostics
+}
+
+// validator contains the state of a validation pass.
+type validator struct {
+	g *Grammar
+	// nullable indicates whether a rule can match without consuming input.
+	nullable map[string]bool
+}
+
+// rule returns the name of the rule if the given value references a rule of
+// the grammar that is being validated.
+func (v *validator) rule(i interface{}) (string, bool) {
+	r, ok := i.(op.Rule)
+	if !ok || r.Rules != v.g {
+		return "", false
+	}
+	return r.Name, true
+}
+
+// computeNullable computes which rules are nullable, until nothing changes.
+func (v *validator) computeNullable() {
+	for changed := true; changed; {
+		changed = false
+		for _, name := range v.g.names {
+			if !v.nullable[name] && v.isNullable(v.g.rules[name]) {
+				v.nullable[name] = true
+				changed = true
+			}
+		}
+	}
+}
+
+// isNullable checks whether the given value can match without consuming any
+// input.
+func (v *validator) isNullable(i interface{}) bool {
+	if name, ok := v.rule(i); ok {
+		return v.nullable[name]
+	}
+	switch i := i.(type) {
+	case op.Not, op.Ensure, op.Cut, op.Anchor:
+		return true
+	case op.And:
+		for _, i := range i {
+			if !v.isNullable(i) {
+				return false
+			}
+		}
+		return true
+	case op.Or:
+		for _, i := range i {
+			if v.isNullable(i) {
+				return true
+			}
+		}
+		return false
+	case op.XOr:
+		for _, i := range i {
+			if v.isNullable(i) {
+				return true
+			}
+		}
+		return false
+	case op.Longest:
+		for _, i := range i {
+			if v.isNullable(i) {
+				return true
+			}
+		}
+		return false
+	case op.Range:
+		return i.Min <= 0 || v.isNullable(i.Value)
+	case op.SeparatedBy:
+		return v.isNullable(i.Element)
+	case op.Between:
+		return v.isNullable(op.And{i.Open, i.Body, i.Close})
+	case ast.Expression:
+		return v.isNullable(expression(i))
+	case ast.ChainLeft:
+		return v.isNullable(chain(i.Operand, i.Operator))
+	case ast.ChainRight:
+		return v.isNullable(chain(i.Operand, i.Operator))
+	}
+	if value, ok := inner(i); ok {
+		return v.isNullable(value)
+	}
+	return false
+}
+
+// refs returns the names of the rules that are referenced by the given value.
+// If first is true, only the rules that can be matched before any input is
+// consumed are returned. Memoized values are skipped in that case.
+func (v *validator) refs(i interface{}, first bool) []string {
+	if name, ok := v.rule(i); ok {
+		return []string{name}
+	}
+	var refs []string
+	switch i := i.(type) {
+	case op.And:
+		for _, i := range i {
+			refs = append(refs, v.refs(i, first)...)
+			if first && !v.isNullable(i) {
+				break
+			}
+		}
+		return refs
+	case op.Or:
+		for _, i := range i {
+			refs = append(refs, v.refs(i, first)...)
+		}
+		return refs
+	case op.XOr:
+		for _, i := range i {
+			refs = append(refs, v.refs(i, first)...)
+		}
+		return refs
+	case op.Longest:
+		for _, i := range i {
+			refs = append(refs, v.refs(i, first)...)
+		}
+		return refs
+	case op.SeparatedBy:
+		return v.refs(op.And{i.Element, i.Separator}, first)
+	case op.Between:
+		return v.refs(op.And{i.Open, i.Body, i.Close}, first)
+	case ast.Expression:
+		return v.refs(expression(i), first)
+	case ast.ChainLeft:
+		return v.refs(chain(i.Operand, i.Operator), first)
+	case ast.ChainRight:
+		return v.refs(chain(i.Operand, i.Operator), first)
+	case op.Recover:
+		if !first {
+			refs = v.refs(i.Sync, first)
+		}
+		return append(refs, v.refs(i.Value, first)...)
+	case *op.Memo:
+		if first {
+			return nil
+		}
+	}
+	if value, ok := inner(i); ok {
+		return v.refs(value, first)
+	}
+	return nil
+}
+
+// unreachable returns the rules that can not be reached from the first rule.
+func (v *validator) unreachable() []string {
+	if len(v.g.names) == 0 {
+		return nil
+	}
+	reached := map[string]bool{v.g.names[0]: true}
+	for todo := []string{v.g.names[0]}; len(todo) != 0; {
+		name := todo[len(todo)-1]
+		todo = todo[:len(todo)-1]
+		for _, ref := range v.refs(v.g.rules[name], false) {
+			if !reached[ref] {
+				reached[ref] = true
+				todo = append(todo, ref)
+			}
+		}
+	}
+	var unreachable []string
+	for _, name := range v.g.names {
+		if !reached[name] {
+			unreachable = append(unreachable, name)
+		}
+	}
+	return unreachable
+}
+
+// cycle returns the path of rules through which the given rule references
+// itself without consuming any input, or nil if it does not.
+func (v *validator) cycle(name string) []string {
+	visited := make(map[string]bool)
+	var find func(path []string) []string
+	find = func(path []string) []string {
+		for _, ref := range v.refs(v.g.rules[path[len(path)-1]], true) {
+			if ref == name {
+				return path
+			}
+			if visited[ref] {
+				continue
+			}
+			visited[ref] = true
+			if cycle := find(append(path, ref)); cycle != nil {
+				return cycle
+			}
+		}
+		return nil
+	}
+	return find([]string{name})
+}
+
+// walk calls the given function for the given value and all the values it
+// contains, without following references to rules.
+func (v *validator) walk(i interface{}, f func(i interface{})) {
+	f(i)
+	switch i := i.(type) {
+	case op.And:
+		for _, i := range i {
+			v.walk(i, f)
+		}
+	case op.Or:
+		for _, i := range i {
+			v.walk(i, f)
+		}
+	case op.XOr:
+		for _, i := range i {
+			v.walk(i, f)
+		}
+	case op.Longest:
+		for _, i := range i {
+			v.walk(i, f)
+		}
+	case op.Recover:
+		v.walk(i.Value, f)
+		v.walk(i.Sync, f)
+	case op.SeparatedBy:
+		v.walk(i.Element, f)
+		v.walk(i.Separator, f)
+	case op.Between:
+		v.walk(i.Open, f)
+		v.walk(i.Body, f)
+		v.walk(i.Close, f)
+	case ast.Expression:
+		v.walk(expression(i), f)
+	case ast.ChainLeft:
+		v.walk(chain(i.Operand, i.Operator), f)
+	case ast.ChainRight:
+		v.walk(chain(i.Operand, i.Operator), f)
+	default:
+		if value, ok := inner(i); ok {
+			v.walk(value, f)
+		}
+	}
+}
+
+// expression returns a value that matches the same as the given expression,
+// ignoring the precedence of its operators.
+func expression(e ast.Expression) interface{} {
+	operators := func(operators []ast.Operator) op.Or {
+		values := make(op.Or, len(operators))
+		for i, o := range operators {
+			values[i] = o.Value
+		}
+		return values
+	}
+	operand := op.And{op.MinZero(operators(e.Prefix)), e.Operand, op.MinZero(operators(e.Postfix))}
+	return chain(operand, operators(e.Infix))
+}
+
+// chain returns a value that matches the same as a chain of the given operands
+// and operators.
+func chain(operand, operator interface{}) interface{} {
+	return op.And{operand, op.MinZero(op.And{operator, operand})}
+}
+
+// inner returns the value that is wrapped by the given value, if any.
+func inner(i interface{}) (interface{}, bool) {
+	switch i := i.(type) {
+	case op.Not:
+		return i.Value, true
+	case op.Ensure:
+		return i.Value, true
+	case op.Range:
+		return i.Value, true
+	case op.Lexeme:
+		return i.Value, true
+	case op.NoSkip:
+		return i.Value, true
+	case op.CaseInsensitive:
+		return i.Value, true
+	case *op.Memo:
+		return i.Value, true
+	case op.Recover:
+		return i.Value, true
+	case op.Indented:
+		return i.Value, true
+	case op.Aligned:
+		return i.Value, true
+	case op.Label:
+		return i.Value, true
+	case op.Trace:
+		return i.Value, true
+	case op.Where:
+		return i.Value, true
+	case op.Scan:
+		return i.Value, true
+	case op.Reserved:
+		return i.Value, true
+	case op.Capture:
+		return i.Value, true
+	case ast.Capture:
+		return i.Value, true
+	case ast.Stream:
+		return i.Value, true
+	case ast.Capturer:
+		return i.Capture().Value, true
+	}
+	return nil, false
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
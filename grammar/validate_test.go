@@ -0,0 +1,97 @@
+package grammar_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser/grammar"
+	"github.com/di-wu/parser/op"
+	"testing"
+)
+
+func ExampleGrammar_Validate() {
+	g := grammar.New()
+	g.Define("Expr", op.Or{op.And{g.Ref("Expr"), '-', g.Ref("Term")}, g.Ref("Term")})
+	g.Define("Term", op.And{g.Ref("Digit"), op.MinZero(op.Optional(' '))})
+	g.Define("Comment", op.And{'#', g.Ref("Text")})
+
+	for _, d := range g.Validate() {
+		fmt.Println(d)
+	}
+	// Output:
+	// grammar: Term: undefined rule: Digit is not defined
+	// grammar: Comment: undefined rule: Text is not defined
+	// grammar: Comment: unreachable rule: Comment is not referenced by Expr
+	// grammar: Expr: left recursion: Expr -> Expr
+	// grammar: Term: nullable repetition: ' '?* can match without consuming input
+}
+
+func TestGrammar_Validate(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		rules func(g *grammar.Grammar)
+		kinds []grammar.Kind
+	}{
+		{
+			name: "valid",
+			rules: func(g *grammar.Grammar) {
+				g.Define("a", op.And{'(', op.MinZero(g.Ref("a")), ')'})
+			},
+		},
+		{
+			name: "indirect",
+			rules: func(g *grammar.Grammar) {
+				g.Define("a", op.Or{g.Ref("b"), 'a'})
+				g.Define("b", op.And{op.Optional('-'), g.Ref("a")})
+			},
+			kinds: []grammar.Kind{grammar.LeftRecursion},
+		},
+		{
+			name: "memoized",
+			rules: func(g *grammar.Grammar) {
+				g.Define("a", &op.Memo{Value: op.Or{op.And{g.Ref("a"), 'a'}, 'a'}})
+			},
+		},
+		{
+			name: "lookahead",
+			rules: func(g *grammar.Grammar) {
+				g.Define("a", op.And{op.Not{Value: g.Ref("a")}, 'a'})
+			},
+			kinds: []grammar.Kind{grammar.LeftRecursion},
+		},
+		{
+			name: "nullable rule",
+			rules: func(g *grammar.Grammar) {
+				g.Define("a", op.MinOne(g.Ref("b")))
+				g.Define("b", op.Or{'b', g.Ref("c")})
+				g.Define("c", op.Ensure{Value: 'c'})
+			},
+			kinds: []grammar.Kind{grammar.NullableRepetition},
+		},
+		{
+			name: "separated",
+			rules: func(g *grammar.Grammar) {
+				g.Define("a", op.SeparatedBy{Element: op.Optional('a'), Separator: op.MinZero(',')})
+			},
+			kinds: []grammar.Kind{grammar.NullableRepetition},
+		},
+		{
+			name: "bounded",
+			rules: func(g *grammar.Grammar) {
+				g.Define("a", op.MinMax(0, 3, op.Optional('a')))
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			g := grammar.New()
+			test.rules(g)
+			diagnostics := g.Validate()
+			if len(diagnostics) != len(test.kinds) {
+				t.Fatal(diagnostics)
+			}
+			for i, d := range diagnostics {
+				if d.Kind != test.kinds[i] {
+					t.Error(d)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package parser
+
+import "strings"
+
+// Heredoc is a Class that matches a here document: a terminator identifier,
+// followed by lines up to and including a line that equals the terminator. e.g.
+//
+//	<<EOT
+//	Hello,
+//	World!
+//	EOT
+//
+// Its value is the text of the lines in between, each followed by a newline.
+type Heredoc struct {
+	// Start introduces the here document, e.g. "<<". The terminator follows
+	// directly after it and consists of letters, digits and '_'.
+	Start string
+	// Strip allows the terminating line to be indented. Its indentation is
+	// stripped from all the lines of the document.
+	Strip bool
+}
+
+func (h Heredoc) Check(p *Parser) (*Cursor, bool) {
+	_, last, ok := h.Value(p)
+	return last, ok
+}
+
+// Value works the same as Check, but also returns the text of the document. It
+// can be used as a TypedClass.
+func (h Heredoc) Value(p *Parser) (string, *Cursor, bool) {
+	last, ok := CheckString(h.Start)(p)
+	if !ok {
+		return "", last, false
+	}
+
+	var terminator strings.Builder
+	for !p.Done() && isWord(p.Current()) {
+		terminator.WriteRune(p.Current())
+		last = p.Mark()
+		p.Next()
+	}
+	if terminator.Len() == 0 {
+		return "", last, false
+	}
+	rest, _, end := line(p)
+	if rest != "" || end == nil {
+		// The terminator should end the line.
+		return "", last, false
+	}
+	last = end
+
+	var lines []string
+	for !p.Done() {
+		text, content, end := line(p)
+		trimmed := strings.TrimLeft(text, " \t")
+		if trimmed == terminator.String() && (h.Strip || trimmed == text) {
+			// The line ending of the terminator is not part of the document.
+			return h.text(lines, text[:len(text)-len(trimmed)]), content, true
+		}
+		lines = append(lines, text)
+		last = end
+	}
+	return "", last, false
+}
+
+// line consumes the rest of the line, including its line ending. Returns the
+// text of the line, a mark to its last rune before the line ending and a mark
+// to the last rune of the line ending.
+func line(p *Parser) (string, *Cursor, *Cursor) {
+	var (
+		text          strings.Builder
+		content, last *Cursor
+	)
+	for !p.Done() {
+		r := p.Current()
+		last = p.Mark()
+		p.Next()
+		if r == '\n' {
+			break
+		}
+		if r != '\r' {
+			text.WriteRune(r)
+			content = last
+		}
+	}
+	return text.String(), content, last
+}
+
+// text joins the given lines, without the given indentation. Every line ends
+// with a newline.
+func (h Heredoc) text(lines []string, indentation string) string {
+	var text strings.Builder
+	for _, line := range lines {
+		text.WriteString(strings.TrimPrefix(line, indentation))
+		text.WriteByte('\n')
+	}
+	return text.String()
+}
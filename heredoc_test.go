@@ -0,0 +1,60 @@
+//go:build go1.18
+// +build go1.18
+
+package parser_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"testing"
+)
+
+func ExampleHeredoc() {
+	heredoc := parser.Heredoc{Start: "<<~", Strip: true}
+
+	p, _ := parser.New([]byte("<<~SQL\n    SELECT *\n      FROM users\n    SQL\n"))
+	text, _, err := parser.ExpectT(p, heredoc.Value)
+	fmt.Printf("%q %v\n", text, err)
+	// Output:
+	// "SELECT *\n  FROM users\n" <nil>
+}
+
+func ExampleHeredoc_terminator() {
+	heredoc := parser.Heredoc{Start: "<<"}
+
+	// Only a line that equals the terminator ends the document. Line endings
+	// are normalized to newlines.
+	for _, input := range []string{"<<EOT\n  EOT\nEOT", "<<EOT\r\na\r\nEOT\r\n", "<<EOT\nEOT\n"} {
+		p, _ := parser.New([]byte(input))
+		text, _, err := parser.ExpectT(p, heredoc.Value)
+		fmt.Printf("%q %v\n", text, err)
+	}
+	// Output:
+	// "  EOT\n" <nil>
+	// "a\n" <nil>
+	// "" <nil>
+}
+
+func TestHeredoc_invalid(t *testing.T) {
+	heredoc := parser.Heredoc{Start: "<<"}
+	for _, input := range []string{
+		"<<EOT\na\nEOTX\n", // Not terminated.
+		"<<EOT x\na\nEOT",  // Text after the terminator.
+		"<<\na\n",          // No terminator.
+		"<<EOT",            // No lines.
+	} {
+		p, _ := parser.New([]byte(input))
+		if text, _, err := parser.ExpectT(p, heredoc.Value); err == nil {
+			t.Errorf("%q: expected an error, got %q", input, text)
+		}
+	}
+}
+
+func TestHeredoc_strip(t *testing.T) {
+	// Only the indentation of the terminator is stripped, the rest is kept.
+	heredoc := parser.Heredoc{Start: "<<", Strip: true}
+	p, _ := parser.New([]byte("<<X\n\t a\n\t \tb\n\t X"))
+	if text, _, err := parser.ExpectT(p, heredoc.Value); err != nil || text != "a\n\tb\n" {
+		t.Errorf("%q %v", text, err)
+	}
+}
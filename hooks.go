@@ -0,0 +1,54 @@
+package parser
+
+// hooks contains the functions that are called while expecting values.
+type hooks struct {
+	start  []func(i interface{}, c Cursor)
+	end    []func(i interface{}, c Cursor, err error)
+	errors []func(i interface{}, err error)
+}
+
+// OnExpectStart registers a function that is called whenever the parser starts
+// expecting a value, with the position of the parser. Hooks are called in the
+// order they were registered.
+func (p *Parser) OnExpectStart(f func(i interface{}, c Cursor)) {
+	if p.hooks == nil {
+		p.hooks = new(hooks)
+	}
+	p.hooks.start = append(p.hooks.start, f)
+}
+
+// OnExpectEnd registers a function that is called whenever the parser is done
+// expecting a value, with the position of the parser and the error if the value
+// did not match.
+func (p *Parser) OnExpectEnd(f func(i interface{}, c Cursor, err error)) {
+	if p.hooks == nil {
+		p.hooks = new(hooks)
+	}
+	p.hooks.end = append(p.hooks.end, f)
+}
+
+// OnError registers a function that is called whenever an expected value did
+// not match.
+func (p *Parser) OnError(f func(i interface{}, err error)) {
+	if p.hooks == nil {
+		p.hooks = new(hooks)
+	}
+	p.hooks.errors = append(p.hooks.errors, f)
+}
+
+func (h *hooks) enter(p *Parser, i interface{}) {
+	for _, f := range h.start {
+		f(i, *p.cursor)
+	}
+}
+
+func (h *hooks) leave(p *Parser, i interface{}, err error) {
+	for _, f := range h.end {
+		f(i, *p.cursor, err)
+	}
+	if err != nil {
+		for _, f := range h.errors {
+			f(i, err)
+		}
+	}
+}
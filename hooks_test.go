@@ -0,0 +1,41 @@
+package parser_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+	"testing"
+)
+
+func ExampleParser_OnError() {
+	p, _ := parser.New([]byte("ab"))
+	p.OnError(func(i interface{}, err error) {
+		fmt.Println(parser.Stringer(i), "failed")
+	})
+	_, _ = p.Expect(op.Or{'b', 'a'})
+	// Output:
+	// 'b' failed
+}
+
+func TestParser_OnExpectStart(t *testing.T) {
+	p, _ := parser.New([]byte("ab"))
+	var depth, max int
+	p.OnExpectStart(func(i interface{}, c parser.Cursor) {
+		if depth++; max < depth {
+			max = depth
+		}
+	})
+	p.OnExpectEnd(func(i interface{}, c parser.Cursor, err error) {
+		depth--
+		if i == 'b' && c.Offset() != 2 {
+			t.Error(c.Offset())
+		}
+	})
+
+	if _, err := p.Expect(op.And{'a', 'b'}); err != nil {
+		t.Fatal(err)
+	}
+	if depth != 0 || max != 2 {
+		t.Error(depth, max)
+	}
+}
@@ -0,0 +1,53 @@
+package parser
+
+import "unicode"
+
+// Predefined identifier classes.
+var (
+	// Identifier matches an identifier as defined by Unicode (UAX #31): a rune
+	// with the ID_Start property, followed by runes with the ID_Continue
+	// property.
+	Identifier = CheckIdentifier(IsIDStart, IsIDContinue)
+	// GoIdentifier matches an identifier as defined by the Go specification: a
+	// letter or '_', followed by letters, '_' and digits.
+	GoIdentifier = CheckIdentifier(
+		func(r rune) bool {
+			return r == '_' || unicode.IsLetter(r)
+		},
+		func(r rune) bool {
+			return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+		},
+	)
+)
+
+// CheckIdentifier returns an AnonymousClass that matches a rune for which start
+// returns true, followed by any runes for which next returns true.
+func CheckIdentifier(start, next func(r rune) bool) AnonymousClass {
+	return func(p *Parser) (*Cursor, bool) {
+		if p.Done() || !start(p.Current()) {
+			return nil, false
+		}
+		last := p.Mark()
+		for p.Next(); !p.Done() && next(p.Current()); p.Next() {
+			last = p.Mark()
+		}
+		return last, true
+	}
+}
+
+// IsIDStart checks whether the given rune has the Unicode ID_Start property.
+func IsIDStart(r rune) bool {
+	if unicode.In(r, unicode.Pattern_Syntax, unicode.Pattern_White_Space) {
+		return false
+	}
+	return unicode.In(r, unicode.Letter, unicode.Nl, unicode.Other_ID_Start)
+}
+
+// IsIDContinue checks whether the given rune has the Unicode ID_Continue
+// property.
+func IsIDContinue(r rune) bool {
+	if unicode.In(r, unicode.Pattern_Syntax, unicode.Pattern_White_Space) {
+		return false
+	}
+	return IsIDStart(r) || unicode.In(r, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc, unicode.Other_ID_Continue)
+}
@@ -0,0 +1,51 @@
+package parser_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"testing"
+)
+
+func ExampleCheckIdentifier() {
+	p, _ := parser.New([]byte("héllo_wörld2 ¹"))
+	start := p.Mark()
+	last, _ := p.Expect(parser.Identifier)
+	fmt.Println(p.Slice(start, last))
+	// Output:
+	// héllo_wörld2
+}
+
+func TestIdentifier(t *testing.T) {
+	for _, test := range []struct {
+		input      string
+		identifier string
+		goIdent    string
+	}{
+		{input: "abc", identifier: "abc", goIdent: "abc"},
+		{input: "_a1", identifier: "", goIdent: "_a1"},
+		{input: "a_1", identifier: "a_1", goIdent: "a_1"},
+		{input: "1a", identifier: "", goIdent: ""},
+		{input: "Ωmega", identifier: "Ωmega", goIdent: "Ωmega"},
+		{input: "e\u0301t", identifier: "e\u0301t", goIdent: "e"},
+		{input: "℘x", identifier: "℘x", goIdent: ""},
+		{input: "a-b", identifier: "a", goIdent: "a"},
+	} {
+		for _, c := range []struct {
+			class    parser.AnonymousClass
+			expected string
+		}{
+			{class: parser.Identifier, expected: test.identifier},
+			{class: parser.GoIdentifier, expected: test.goIdent},
+		} {
+			p, _ := parser.New([]byte(test.input))
+			start := p.Mark()
+			var matched string
+			if last, ok := p.Check(c.class); ok {
+				matched = p.Slice(start, last)
+			}
+			if matched != c.expected {
+				t.Errorf("%q: expected %q, got %q", test.input, c.expected, matched)
+			}
+		}
+	}
+}
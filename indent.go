@@ -0,0 +1,80 @@
+package parser
+
+// indentLevel is an immutable stack of indentation levels. Like the user state,
+// it is part of the position of the parser.
+type indentLevel struct {
+	column int
+	parent *indentLevel
+}
+
+// IndentLevel returns the current indentation level, the visual column in
+// which the lines of the current block start. Defaults to 0.
+func (p *Parser) IndentLevel() int {
+	if p.cursor.indent == nil {
+		return 0
+	}
+	return p.cursor.indent.column
+}
+
+// PushIndent sets the indentation level to the given (visual) column, until it
+// gets popped. Jumping back to a mark restores the level of that mark.
+func (p *Parser) PushIndent(column int) {
+	p.cursor.indent = &indentLevel{
+		column: column,
+		parent: p.cursor.indent,
+	}
+}
+
+// PopIndent restores the previous indentation level.
+func (p *Parser) PopIndent() {
+	if p.cursor.indent != nil {
+		p.cursor.indent = p.cursor.indent.parent
+	}
+}
+
+// SkipIndentation skips all spaces and tabs and returns the visual column of
+// the parser after skipping them. It should be used at the start of a line.
+func (p *Parser) SkipIndentation() int {
+	p.skipIndentation()
+	return p.cursor.visual
+}
+
+// skipIndentation skips all spaces and tabs and returns a mark to the last
+// skipped rune, if any.
+func (p *Parser) skipIndentation() *Cursor {
+	var last *Cursor
+	for p.cursor.Rune == ' ' || p.cursor.Rune == '\t' {
+		last = p.Mark()
+		p.Next()
+	}
+	return last
+}
+
+// Indent is a virtual token that matches the indentation at the start of a line
+// if it is deeper than the current indentation level. The indentation becomes
+// the new level, until it is popped by a Dedent.
+var Indent AnonymousClass = func(p *Parser) (*Cursor, bool) {
+	start := p.Mark()
+	last := p.skipIndentation()
+	if p.cursor.visual <= p.IndentLevel() {
+		p.Jump(start)
+		return nil, false
+	}
+	p.PushIndent(p.cursor.visual)
+	return last, true
+}
+
+// Dedent is a virtual token that matches if the indentation at the start of a
+// line is less deep than the current indentation level. It pops a single level
+// and does not consume anything, so a line can match multiple dedents.
+var Dedent AnonymousClass = func(p *Parser) (*Cursor, bool) {
+	start := p.Mark()
+	p.skipIndentation()
+	column := p.cursor.visual
+	p.Jump(start)
+	if p.IndentLevel() <= column {
+		return nil, false
+	}
+	p.PopIndent()
+	return nil, true
+}
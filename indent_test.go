@@ -0,0 +1,58 @@
+package parser_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+	"testing"
+)
+
+// block matches python-like statements, e.g.
+//
+//	if x:
+//	  y
+//	z
+func block(p *parser.Parser) (*parser.Cursor, bool) {
+	name := op.MinOne(parser.CheckRuneRange('a', 'z'))
+	statement := op.Or{
+		op.And{"if ", name, ":\n", op.Indented{Value: block}},
+		op.And{name, '\n'},
+	}
+	return p.Check(op.MinOne(op.Aligned{Value: statement}))
+}
+
+func ExampleIndented() {
+	p, _ := parser.New([]byte("if a:\n  if b:\n    c\n  d\ne\n"))
+	_, ok := p.Check(block)
+	fmt.Println(ok, p.Done())
+
+	// Inconsistent indentation, the block ends before "c".
+	p, _ = parser.New([]byte("if a:\n  b\n   c\n"))
+	_, ok = p.Check(block, parser.EOD)
+	fmt.Println(ok)
+	// Output:
+	// true true
+	// false
+}
+
+func TestIndent(t *testing.T) {
+	p, _ := parser.New([]byte("a\n  b\n    c\nd"))
+	line := op.And{parser.CheckRuneRange('a', 'z'), op.Optional('\n')}
+
+	if _, err := p.Expect(line, parser.Indent, line, parser.Indent, line); err != nil {
+		t.Fatal(err)
+	}
+	if level := p.IndentLevel(); level != 4 {
+		t.Error(level)
+	}
+	// Both levels end on the same line.
+	if _, err := p.Expect(parser.Dedent, parser.Dedent, line); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := p.Check(parser.Dedent); ok || p.IndentLevel() != 0 {
+		t.Error(p.IndentLevel())
+	}
+	if !p.Done() {
+		t.Error(p.Current())
+	}
+}
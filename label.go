@@ -0,0 +1,23 @@
+package parser
+
+import "github.com/di-wu/parser/op"
+
+// labeled evaluates the given function, which should expect the value of the
+// given label. Failures within the label are not reported, if the function
+// fails the label itself is reported as expected instead. Fatal errors and
+// failures after a cut are returned as is.
+func (p *Parser) labeled(label op.Label, f func() error) error {
+	start := p.MarkV()
+	p.labels++
+	err := f()
+	p.labels--
+	if _, cut := cutError(err); err == nil || cut || p.fatal != nil {
+		return err
+	}
+	// Point at the start of the value, not at the trivia in front of it.
+	p.Jump(&start)
+	p.SkipTrivia()
+	err = p.ExpectedParseError(label, p.Mark(), nil)
+	p.Jump(&start)
+	return err
+}
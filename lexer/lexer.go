@@ -0,0 +1,104 @@
+// Package lexer splits input into tokens, so that these can be parsed by a
+// TokenParser instead of parsing the input rune by rune.
+package lexer
+
+import (
+	"errors"
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+)
+
+// Kind identifies the kind of a token, e.g. "IDENT" or "NUMBER".
+type Kind string
+
+// Rule defines a kind of token.
+type Rule struct {
+	Kind Kind
+	// Value is the expression that matches the token. It accepts everything
+	// that is supported by parser.Expect, e.g. classes and strings.
+	Value interface{}
+	// Skip indicates that the matched tokens are discarded, e.g. whitespace
+	// and comments.
+	Skip bool
+}
+
+// Token is a piece of the input that got matched by a rule.
+type Token struct {
+	Kind Kind
+	// Value is the matched text.
+	Value string
+	// Cursor is the position of the first rune of the token.
+	Cursor parser.Cursor
+}
+
+func (t Token) String() string {
+	return fmt.Sprintf("%s %q", t.Kind, t.Value)
+}
+
+// Lexer splits input into tokens.
+type Lexer struct {
+	rules []Rule
+}
+
+// New creates a new Lexer with the given rules. Rules are tried in the given
+// order, the first rule that matches determines the token. So rules that match
+// prefixes of other rules (e.g. "<" and "<=") should come after those.
+func New(rules ...Rule) *Lexer {
+	return &Lexer{
+		rules: rules,
+	}
+}
+
+// Tokenize splits the given input into tokens. The options are passed to the
+// underlying parser. If some part of the input does not match any of the rules,
+// a parse error is returned that expects one of the kinds of the rules.
+func (l *Lexer) Tokenize(input []byte, opts ...parser.Option) ([]Token, error) {
+	p, err := parser.New(input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var tokens []Token
+	for !p.Done() {
+		token, err := l.next(p)
+		if err != nil {
+			return tokens, err
+		}
+		if token != nil {
+			tokens = append(tokens, *token)
+		}
+	}
+	return tokens, nil
+}
+
+// next matches the next token. Returns nil if the token is skipped.
+func (l *Lexer) next(p *parser.Parser) (*Token, error) {
+	start := p.Mark()
+	for _, rule := range l.rules {
+		if _, err := p.Expect(rule.Value); err != nil {
+			var conflict *parser.ExpectedParseError
+			if !errors.As(err, &conflict) {
+				return nil, err
+			}
+			continue
+		}
+		if p.Offset() == start.Offset() {
+			// Lookaheads and empty repetitions do not consume anything.
+			p.Jump(start)
+			continue
+		}
+		if rule.Skip {
+			return nil, nil
+		}
+		return &Token{
+			Kind:   rule.Kind,
+			Value:  p.Slice(start, p.LookBack()),
+			Cursor: *start,
+		}, nil
+	}
+	kinds := make(op.Or, len(l.rules))
+	for i, rule := range l.rules {
+		kinds[i] = rule.Kind
+	}
+	return nil, p.ExpectedParseError(kinds, start, start)
+}
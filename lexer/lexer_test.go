@@ -0,0 +1,93 @@
+package lexer_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/lexer"
+	"github.com/di-wu/parser/op"
+	"testing"
+)
+
+var (
+	ident  = op.And{parser.CheckRuneRange('a', 'z'), op.MinZero(parser.CheckRuneRange('a', 'z'))}
+	number = op.MinOne(parser.CheckRuneRange('0', '9'))
+	lex    = lexer.New(
+		lexer.Rule{Kind: "SPACE", Value: op.MinOne(op.Or{' ', '\n'}), Skip: true},
+		lexer.Rule{Kind: "IDENT", Value: ident},
+		lexer.Rule{Kind: "NUMBER", Value: number},
+		lexer.Rule{Kind: "OP", Value: op.AnyString("==", "=", ";")},
+	)
+)
+
+func ExampleLexer_Tokenize() {
+	tokens, err := lex.Tokenize([]byte("let x = 42;\nx == 42;"))
+	for _, token := range tokens {
+		fmt.Println(token)
+	}
+	fmt.Println(err)
+
+	_, err = lex.Tokenize([]byte("let x = 4.2;"))
+	fmt.Println(err)
+	// Output:
+	// IDENT "let"
+	// IDENT "x"
+	// OP "="
+	// NUMBER "42"
+	// OP ";"
+	// IDENT "x"
+	// OP "=="
+	// NUMBER "42"
+	// OP ";"
+	// <nil>
+	// parse conflict [00:009]: expected op.Or SPACE / IDENT / NUMBER / OP but got '.'
+}
+
+func ExampleTokenParser() {
+	tokens, _ := lex.Tokenize([]byte("let x = 42;\nlet y = x;"))
+	p := lexer.NewTokenParser(tokens)
+
+	statement := op.And{"let", lexer.Kind("IDENT"), "=", op.Or{lexer.Kind("NUMBER"), lexer.Kind("IDENT")}, ";"}
+	fmt.Println(p.Expect(op.MinOne(statement)))
+	fmt.Println(p.Done())
+
+	p = lexer.NewTokenParser(tokens)
+	fmt.Println(p.Expect(statement, "let", lexer.Kind("NUMBER")))
+	// Output:
+	// OP ";" <nil>
+	// true
+	// <nil> parse conflict [01:004]: expected NUMBER but got IDENT "y"
+}
+
+func TestTokenParser_Expect(t *testing.T) {
+	tokens, err := lex.Tokenize([]byte("a = 1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		value interface{}
+		ok    bool
+		done  bool
+	}{
+		{value: lexer.Token{Kind: "IDENT", Value: "a"}, ok: true},
+		{value: lexer.Token{Kind: "IDENT", Value: "b"}},
+		{value: op.Ensure{Value: "a"}, ok: true},
+		{value: op.Not{Value: "a"}},
+		{value: op.XOr{lexer.Kind("IDENT"), "a"}},
+		{value: op.XOr{lexer.Kind("NUMBER"), "a"}, ok: true},
+		{value: op.MinMax(2, 2, op.Or{lexer.Kind("IDENT"), lexer.Kind("OP")}), ok: true},
+		{value: op.MinZero(op.Or{lexer.Kind("IDENT"), lexer.Kind("OP"), lexer.Kind("NUMBER")}), ok: true, done: true},
+		{value: op.And{"a", "=", "1", "2"}},
+	} {
+		p := lexer.NewTokenParser(tokens)
+		_, err := p.Expect(test.value)
+		if (err == nil) != test.ok {
+			t.Errorf("%v: %v", test.value, err)
+		}
+		if err != nil && p.Mark() != 0 {
+			t.Errorf("%v: did not reset", test.value)
+		}
+		if p.Done() != test.done {
+			t.Errorf("%v: done %v", test.value, p.Done())
+		}
+	}
+}
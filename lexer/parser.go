@@ -0,0 +1,210 @@
+package lexer
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+)
+
+// TokenParser parses a sequence of tokens. It works the same as a parser, but
+// the values it expects are matched against whole tokens instead of runes.
+type TokenParser struct {
+	tokens []Token
+	// position is the index of the current token.
+	position int
+}
+
+// NewTokenParser creates a new TokenParser for the given tokens.
+func NewTokenParser(tokens []Token) *TokenParser {
+	return &TokenParser{
+		tokens: tokens,
+	}
+}
+
+// Current returns the current token, or nil if all tokens are consumed.
+func (p *TokenParser) Current() *Token {
+	if p.Done() {
+		return nil
+	}
+	return &p.tokens[p.position]
+}
+
+// Next advances the parser to the next token.
+func (p *TokenParser) Next() *TokenParser {
+	if !p.Done() {
+		p.position++
+	}
+	return p
+}
+
+// Done checks whether all tokens are consumed.
+func (p *TokenParser) Done() bool {
+	return len(p.tokens) <= p.position
+}
+
+// Mark returns the index of the current token.
+func (p *TokenParser) Mark() int {
+	return p.position
+}
+
+// Jump goes to the token at the given index, e.g. one returned by Mark.
+func (p *TokenParser) Jump(mark int) *TokenParser {
+	p.position = mark
+	return p
+}
+
+// Expect checks whether the following tokens match the given values. If they
+// match, the tokens get consumed and the last matched token is returned. If
+// not, the parser is reset to the first token that was checked.
+//
+// Supported values:
+//   - Kind: matches a token of that kind.
+//   - string: matches a token with that value.
+//   - Token: matches a token with that kind and value. Empty fields match
+//     anything.
+//   - func(p *TokenParser) (*Token, error)
+//   - op.Not, op.Ensure, op.And, op.Or, op.XOr, op.Range and op.Lazy.
+func (p *TokenParser) Expect(i interface{}, is ...interface{}) (*Token, error) {
+	if len(is) != 0 {
+		return p.Expect(append(op.And{i}, is...))
+	}
+
+	start := p.position
+	switch v := i.(type) {
+	case Kind:
+		return p.expectToken(v, Token{Kind: v})
+	case string:
+		return p.expectToken(v, Token{Value: v})
+	case Token:
+		return p.expectToken(v, v)
+
+	case func(p *TokenParser) (*Token, error):
+		last, err := v(p)
+		if err != nil {
+			p.Jump(start)
+			return nil, err
+		}
+		return last, nil
+
+	case op.Lazy:
+		return p.Expect(v())
+
+	case op.Not:
+		defer p.Jump(start)
+		if _, err := p.Expect(v.Value); err == nil {
+			return nil, p.expectedTokenError(v, start)
+		}
+		return nil, nil
+	case op.Ensure:
+		if _, err := p.Expect(v.Value); err != nil {
+			return nil, err
+		}
+		p.Jump(start)
+		return nil, nil
+	case op.And:
+		var last *Token
+		for _, i := range v {
+			token, err := p.Expect(i)
+			if err != nil {
+				p.Jump(start)
+				return nil, err
+			}
+			if token != nil {
+				last = token
+			}
+		}
+		return last, nil
+	case op.Or:
+		for _, i := range v {
+			if token, err := p.Expect(i); err == nil {
+				return token, nil
+			}
+		}
+		return nil, p.expectedTokenError(v, start)
+	case op.XOr:
+		var last *Token
+		var matched bool
+		var end int
+		for _, i := range v {
+			token, err := p.Expect(i)
+			if err != nil {
+				continue
+			}
+			if matched {
+				// Multiple values matched.
+				return nil, p.expectedTokenError(v, start)
+			}
+			last, matched, end = token, true, p.position
+			p.Jump(start)
+		}
+		if !matched {
+			return nil, p.expectedTokenError(v, start)
+		}
+		p.Jump(end)
+		return last, nil
+	case op.Range:
+		var last *Token
+		var count int
+		for v.Max == -1 || count < v.Max {
+			token, err := p.Expect(v.Value)
+			if err != nil || token == nil {
+				break
+			}
+			last = token
+			count++
+		}
+		if count < v.Min {
+			p.Jump(start)
+			return nil, p.expectedTokenError(v, start)
+		}
+		return last, nil
+
+	default:
+		return nil, &parser.UnsupportedType{
+			Value: i,
+		}
+	}
+}
+
+// expectToken consumes the current token if it matches the given token.
+func (p *TokenParser) expectToken(expected interface{}, t Token) (*Token, error) {
+	token := p.Current()
+	if token == nil ||
+		(t.Kind != "" && t.Kind != token.Kind) ||
+		(t.Value != "" && t.Value != token.Value) {
+		return nil, p.expectedTokenError(expected, p.position)
+	}
+	p.Next()
+	return token, nil
+}
+
+// expectedTokenError creates an ExpectedTokenError for the token at the given
+// index and resets the parser to it.
+func (p *TokenParser) expectedTokenError(expected interface{}, position int) *ExpectedTokenError {
+	p.Jump(position)
+	return &ExpectedTokenError{
+		Expected: expected,
+		Token:    p.Current(),
+	}
+}
+
+// ExpectedTokenError indicates that the parser expected a different value than
+// the token that was present.
+type ExpectedTokenError struct {
+	// The value that was expected.
+	Expected interface{}
+	// The conflicting token, nil if all the tokens were consumed.
+	Token *Token
+}
+
+func (e *ExpectedTokenError) Error() string {
+	expected := parser.Stringer(e.Expected)
+	if e.Token == nil {
+		return fmt.Sprintf("parse conflict: expected %s but got the end of the input", expected)
+	}
+	row, column := e.Token.Cursor.Position()
+	return fmt.Sprintf(
+		"parse conflict [%02d:%03d]: expected %s but got %s",
+		row, column, expected, e.Token,
+	)
+}
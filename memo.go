@@ -0,0 +1,129 @@
+package parser
+
+import "github.com/di-wu/parser/op"
+
+// WithMemoization limits the size of the table that contains the memoized
+// results of op.Memo values (packrat parsing). The table is cleared once it
+// contains more than the given number of entries. Values less than 1 do not
+// limit the size of the table, which is the default.
+//
+// Memoized results only depend on the position, make sure that the memoized
+// values do not depend on other state (e.g. trivia, attached states or case
+// insensitivity).
+func WithMemoization(budget int) Option {
+	return func(p *Parser) {
+		p.memo = &memo{
+			budget: budget,
+		}
+	}
+}
+
+// memo is a table of memoized results.
+type memo struct {
+	budget  int
+	entries map[memoKey]*memoEntry
+	// recursive is the number of left recursive entries that are growing.
+	recursive int
+}
+
+type memoKey struct {
+	key    interface{}
+	offset int
+}
+
+type memoEntry struct {
+	value interface{}
+	err   error
+	// end is the position of the parser after the result, even on failure.
+	end Cursor
+	// growing indicates that the result is still being evaluated.
+	growing bool
+	// recursive indicates that the evaluation requires its own result.
+	recursive bool
+}
+
+// memoize returns the memoized result of the given key at the current
+// position. If there is none, the result gets evaluated by the given function
+// and memoized. The parser is positioned where the result ended.
+//
+// Memoized results support left recursion: if the evaluation of a key requires
+// its own result at the same position, the recursive evaluation fails first.
+// This result is then used as a seed that grows by evaluating the key again,
+// until it does not consume more input. This allows left recursive rules like
+// `expr <- expr '-' term / term` to be written directly.
+func (p *Parser) memoize(key interface{}, f func() (interface{}, error)) (interface{}, error) {
+	if p.memo == nil {
+		p.memo = new(memo)
+	}
+	k := memoKey{key: key, offset: p.cursor.position}
+	if entry, ok := p.memo.entries[k]; ok {
+		if entry.growing && !entry.recursive {
+			entry.recursive = true
+			p.memo.recursive++
+		}
+		*p.cursor = entry.end
+		return entry.value, entry.err
+	}
+
+	start := *p.cursor
+	entry := &memoEntry{
+		// Left recursive evaluations fail, this is the seed.
+		err: &ExpectError{
+			Message: "left recursion without a seed",
+		},
+		end:     start,
+		growing: true,
+	}
+	p.memo.store(k, entry)
+	value, err := f()
+	entry.value, entry.err, entry.end = value, err, *p.cursor
+	for entry.recursive && err == nil && p.fatal == nil {
+		// Grow the seed until it does not consume more input.
+		*p.cursor = start
+		value, err = f()
+		if err != nil || p.cursor.position <= entry.end.position {
+			break
+		}
+		entry.value, entry.end = value, *p.cursor
+	}
+	entry.growing = false
+	if entry.recursive {
+		p.memo.recursive--
+	}
+
+	if p.fatal != nil {
+		// Errors that stop the parser are never memoized.
+		delete(p.memo.entries, k)
+		return nil, p.fatal
+	}
+	if 0 < p.memo.recursive {
+		// The result might depend on a seed that is still growing.
+		delete(p.memo.entries, k)
+	}
+	*p.cursor = entry.end
+	return entry.value, entry.err
+}
+
+// store stores the given entry, the table is cleared if the budget is
+// exceeded. Entries that are still being evaluated are kept.
+func (m *memo) store(key memoKey, entry *memoEntry) {
+	if m.entries == nil || 0 < m.budget && m.budget <= len(m.entries) {
+		entries := make(map[memoKey]*memoEntry)
+		for k, e := range m.entries {
+			if e.growing {
+				entries[k] = e
+			}
+		}
+		m.entries = entries
+	}
+	m.entries[key] = entry
+}
+
+// memoized matches the given memo, or returns the memoized result.
+func (p *Parser) memoized(m *op.Memo) (*Cursor, error) {
+	value, err := p.memoize(m, func() (interface{}, error) {
+		return p.match(m)
+	})
+	mark, _ := value.(*Cursor)
+	return mark, err
+}
@@ -0,0 +1,141 @@
+package parser_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+	"testing"
+)
+
+func ExampleWithMemoization() {
+	var calls int
+	digits := &op.Memo{Value: func(p *parser.Parser) (*parser.Cursor, bool) {
+		calls++
+		return p.Check(op.MinOne(parser.CheckRuneRange('0', '9')))
+	}}
+
+	p, _ := parser.New([]byte("123-"), parser.WithMemoization(1024))
+	// Both alternatives start with digits.
+	fmt.Println(p.Expect(op.Or{
+		op.And{digits, '+'},
+		op.And{digits, '-'},
+	}))
+	fmt.Println(calls)
+	// Output:
+	// U+002D: - <nil>
+	// 1
+}
+
+func TestMemo(t *testing.T) {
+	var calls int
+	memo := &op.Memo{Value: parser.CheckRuneFunc(func(r rune) bool {
+		calls++
+		return r == 'a'
+	})}
+
+	p, _ := parser.New([]byte("ab"))
+	for i := 0; i < 3; i++ {
+		if _, err := p.Expect(op.And{memo, 'c'}); err == nil {
+			t.Fatal("expected an error")
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+	if _, err := p.Expect(op.And{memo, 'b'}); err != nil {
+		t.Error(err)
+	}
+	if !p.Done() {
+		t.Error(p.Current())
+	}
+
+	// Failures are memoized too.
+	_ = p.Reset([]byte("b"))
+	for i := 0; i < 3; i++ {
+		if _, err := p.Expect(memo); err == nil {
+			t.Fatal("expected an error")
+		}
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+}
+
+func TestWithMemoization_functions(t *testing.T) {
+	// Only memos are memoized, not every function.
+	var calls int
+	letter := func(p *parser.Parser) (*parser.Cursor, bool) {
+		calls++
+		return p.Check(parser.CheckRuneRange('a', 'z'))
+	}
+	p, _ := parser.New([]byte("a"), parser.WithMemoization(0))
+	start := p.Mark()
+	for i := 0; i < 2; i++ {
+		if _, err := p.Expect(letter); err != nil {
+			t.Fatal(err)
+		}
+		p.Jump(start)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+}
+
+func TestWithMemoization_budget(t *testing.T) {
+	var calls int
+	letter := &op.Memo{Value: func(p *parser.Parser) (*parser.Cursor, bool) {
+		calls++
+		return p.Check(parser.CheckRuneRange('a', 'z'))
+	}}
+
+	p, _ := parser.New([]byte("abc"), parser.WithMemoization(2))
+	start := p.Mark()
+	if _, err := p.Expect(op.MinOne(letter)); err != nil {
+		t.Fatal(err)
+	}
+	// The table was cleared after two entries, only the last entries remain.
+	p.Jump(start)
+	if _, err := p.Expect(op.MinOne(letter)); err != nil {
+		t.Fatal(err)
+	}
+	if calls <= 4 {
+		t.Errorf("expected the table to be cleared, got %d calls", calls)
+	}
+}
+
+func ExampleWithMemoization_leftRecursion() {
+	// expr <- expr '-' digit / digit
+	digit := parser.CheckRuneRange('0', '9')
+	expr := new(op.Memo)
+	expr.Value = op.Or{op.And{expr, '-', digit}, digit}
+
+	p, _ := parser.New([]byte("1-2-3"))
+	fmt.Println(p.Expect(expr))
+	fmt.Println(p.Done())
+	// Output:
+	// U+0033: 3 <nil>
+	// true
+}
+
+func TestMemo_leftRecursion(t *testing.T) {
+	// list <- list ',' item / item
+	// item <- 'a' / 'b'
+	list := new(op.Memo)
+	list.Value = op.Or{op.And{list, ',', op.Or{'a', 'b'}}, op.Or{'a', 'b'}}
+
+	p, _ := parser.New([]byte("a,b,a,"))
+	if _, err := p.Expect(list); err != nil {
+		t.Fatal(err)
+	}
+	if p.Current() != ',' || p.Offset() != 5 {
+		t.Error(p.Current(), p.Offset())
+	}
+
+	// Left recursion without a seed.
+	loop := new(op.Memo)
+	loop.Value = op.And{loop, 'a'}
+	_ = p.Reset([]byte("aaa"))
+	if _, err := p.Expect(loop); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,209 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Integer is a Class that matches an integer literal. Its value is computed
+// while matching, it does not match if the value does not fit in an int64.
+type Integer struct {
+	// Signed allows a leading '-' or '+'.
+	Signed bool
+	// Prefixes allows hexadecimal (0x), octal (0o) and binary (0b) literals.
+	Prefixes bool
+	// Underscores allows underscores between digits, e.g. 1_000_000.
+	Underscores bool
+}
+
+func (i Integer) Check(p *Parser) (*Cursor, bool) {
+	_, last, ok := i.Value(p)
+	return last, ok
+}
+
+// Value works the same as Check, but also returns the value of the literal. It
+// can be used as a TypedClass.
+func (i Integer) Value(p *Parser) (int64, *Cursor, bool) {
+	var last *Cursor
+	negative := false
+	if r := p.Current(); i.Signed && (r == '-' || r == '+') {
+		negative = r == '-'
+		last = p.Mark()
+		p.Next()
+	}
+
+	base := uint64(10)
+	if i.Prefixes && p.Current() == '0' {
+		switch p.PeekRune(1) {
+		case 'x', 'X':
+			base = 16
+		case 'o', 'O':
+			base = 8
+		case 'b', 'B':
+			base = 2
+		}
+		if base != 10 {
+			p.Next()
+			last = p.Mark()
+			p.Next()
+		}
+	}
+
+	var (
+		value  uint64
+		digits int
+	)
+	for {
+		r := p.Current()
+		if r == '_' && i.Underscores && digits != 0 && digit(p.PeekRune(1), base) != -1 {
+			last = p.Mark()
+			p.Next()
+			continue
+		}
+		d := digit(r, base)
+		if d == -1 {
+			break
+		}
+		if (math.MaxUint64-uint64(d))/base < value {
+			// Overflow.
+			return 0, last, false
+		}
+		value = value*base + uint64(d)
+		digits++
+		last = p.Mark()
+		p.Next()
+	}
+	if digits == 0 {
+		return 0, last, false
+	}
+	if negative {
+		if 1<<63 < value {
+			return 0, last, false
+		}
+		return -int64(value), last, true
+	}
+	if math.MaxInt64 < value {
+		return 0, last, false
+	}
+	return int64(value), last, true
+}
+
+// Convert decodes the given literal, which should be matched by the class as a
+// whole. The value is an int64, so it can be used as the Convert function of an
+// ast.Capture.
+func (i Integer) Convert(s string) (interface{}, error) {
+	p, err := NewString(s)
+	if err != nil {
+		return nil, err
+	}
+	value, last, ok := i.Value(p)
+	if ok {
+		p.Jump(last).Next()
+	}
+	if !ok || !p.Done() {
+		return nil, fmt.Errorf("invalid integer %q", s)
+	}
+	return value, nil
+}
+
+// Float is a Class that matches a decimal floating-point literal, e.g. 3.14.
+// The fraction is optional, a literal without fraction is also a float.
+type Float struct {
+	// Signed allows a leading '-' or '+'.
+	Signed bool
+	// Exponent allows scientific notation, e.g. 6.022e23.
+	Exponent bool
+}
+
+func (f Float) Check(p *Parser) (*Cursor, bool) {
+	_, last, ok := f.Value(p)
+	return last, ok
+}
+
+// Value works the same as Check, but also returns the value of the literal. It
+// can be used as a TypedClass.
+func (f Float) Value(p *Parser) (float64, *Cursor, bool) {
+	start := p.Mark()
+	var last *Cursor
+	if r := p.Current(); f.Signed && (r == '-' || r == '+') {
+		last = p.Mark()
+		p.Next()
+	}
+	if digit(p.Current(), 10) == -1 {
+		return 0, last, false
+	}
+	last = p.skipDigits()
+	if p.Current() == '.' && digit(p.PeekRune(1), 10) != -1 {
+		// A fraction needs at least one digit, e.g. "1." is not a float.
+		p.Next()
+		last = p.skipDigits()
+	}
+	if r := p.Current(); f.Exponent && (r == 'e' || r == 'E') {
+		n := 1
+		if r := p.PeekRune(1); r == '-' || r == '+' {
+			n++
+		}
+		if digit(p.PeekRune(n), 10) != -1 {
+			for i := 0; i < n; i++ {
+				p.Next()
+			}
+			last = p.skipDigits()
+		}
+	}
+
+	value, err := strconv.ParseFloat(p.Slice(start, last), 64)
+	if err != nil {
+		// Out of range.
+		return 0, last, false
+	}
+	return value, last, true
+}
+
+// Convert decodes the given literal, which should be matched by the class as a
+// whole. The value is a float64, so it can be used as the Convert function of
+// an ast.Capture.
+func (f Float) Convert(s string) (interface{}, error) {
+	p, err := NewString(s)
+	if err != nil {
+		return nil, err
+	}
+	value, last, ok := f.Value(p)
+	if ok {
+		p.Jump(last).Next()
+	}
+	if !ok || !p.Done() {
+		return nil, fmt.Errorf("invalid float %q", s)
+	}
+	return value, nil
+}
+
+// skipDigits skips all decimal digits and returns a mark to the last one.
+func (p *Parser) skipDigits() *Cursor {
+	var last *Cursor
+	for digit(p.Current(), 10) != -1 {
+		last = p.Mark()
+		p.Next()
+	}
+	return last
+}
+
+// digit returns the value of the given digit in the given base, or -1 if it is
+// not a digit.
+func digit(r rune, base uint64) int {
+	var d int
+	switch {
+	case '0' <= r && r <= '9':
+		d = int(r - '0')
+	case 'a' <= r && r <= 'z':
+		d = int(r-'a') + 10
+	case 'A' <= r && r <= 'Z':
+		d = int(r-'A') + 10
+	default:
+		return -1
+	}
+	if uint64(d) < base {
+		return d
+	}
+	return -1
+}
@@ -0,0 +1,99 @@
+//go:build go1.18
+// +build go1.18
+
+package parser_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/ast"
+	"github.com/di-wu/parser/op"
+	"testing"
+)
+
+func ExampleInteger() {
+	integer := parser.Integer{Signed: true, Prefixes: true, Underscores: true}
+
+	p, _ := parser.New([]byte("-1_024 0xFF"))
+	for !p.Done() {
+		value, _, err := parser.ExpectT(p, integer.Value)
+		fmt.Println(value, err)
+		p.Expect(op.Optional(' '))
+	}
+	// Output:
+	// -1024 <nil>
+	// 255 <nil>
+}
+
+func ExampleFloat() {
+	float := parser.Float{Exponent: true}
+
+	p, _ := parser.New([]byte("6.022e23"))
+	fmt.Println(parser.ExpectT(p, float.Value))
+	// Output:
+	// 6.022e+23 U+0033: 3 <nil>
+}
+
+func ExampleInteger_Convert() {
+	integer := parser.Integer{Prefixes: true}
+	fmt.Println(integer.Convert("0b101"))
+	fmt.Println(integer.Convert("0b2"))
+
+	// The value of the node is converted by the capture.
+	p, _ := ast.New([]byte("0o17"))
+	node, _ := p.Expect(ast.Capture{Value: integer, Convert: integer.Convert})
+	fmt.Printf("%T %v\n", node.Data, node.Data)
+	// Output:
+	// 5 <nil>
+	// <nil> invalid integer "0b2"
+	// int64 15
+}
+
+func ExampleFloat_Convert() {
+	float := parser.Float{Signed: true, Exponent: true}
+	fmt.Println(float.Convert("-1e-3"))
+	fmt.Println(float.Convert("1e400"))
+	fmt.Println(float.Convert("1."))
+	// Output:
+	// -0.001 <nil>
+	// <nil> invalid float "1e400"
+	// <nil> invalid float "1."
+}
+
+func TestInteger_overflow(t *testing.T) {
+	integer := parser.Integer{Signed: true}
+	for _, s := range []string{"9223372036854775807", "-9223372036854775808"} {
+		if _, err := integer.Convert(s); err != nil {
+			t.Error(err)
+		}
+	}
+	for _, s := range []string{"9223372036854775808", "-9223372036854775809", "99999999999999999999"} {
+		if v, err := integer.Convert(s); err == nil {
+			t.Errorf("%s: expected an error, got %v", s, v)
+		}
+	}
+}
+
+func TestInteger_Value_trailing(t *testing.T) {
+	// Underscores that are not followed by a digit are not part of the
+	// literal.
+	integer := parser.Integer{Underscores: true}
+	for _, s := range []string{"1__0", "1_"} {
+		p, _ := parser.New([]byte(s))
+		if v, _, err := parser.ExpectT(p, integer.Value); err != nil || v != int64(s[0]-'0') || p.Offset() != 1 {
+			t.Errorf("%s: got %d at %d, %v", s, v, p.Offset(), err)
+		}
+	}
+}
+
+func TestFloat_Value_trailing(t *testing.T) {
+	// A dot or an exponent that is not followed by a digit is not part of the
+	// literal.
+	float := parser.Float{Exponent: true}
+	for _, s := range []string{"1.", "1e", "1e+"} {
+		p, _ := parser.New([]byte(s))
+		if v, _, err := parser.ExpectT(p, float.Value); err != nil || v != 1 || p.Offset() != 1 {
+			t.Errorf("%s: got %v at %d, %v", s, v, p.Offset(), err)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package op
+
+// Anchor represents an assertion about the position of the parser. Anchors do
+// not consume any input.
+type Anchor int
+
+const (
+	// SOI matches at the start of the input.
+	SOI Anchor = iota
+	// EOI matches at the end of the input, trailing trivia is skipped. Use it
+	// to make sure that the whole input is consumed.
+	EOI
+	// BOL matches at the beginning of a line.
+	BOL
+	// EOL matches at the end of a line (before "\n" or "\r\n"), or at the end
+	// of the input.
+	EOL
+	// WordBoundary matches in between a word rune (a letter, digit or '_') and a
+	// non-word rune, or the start or end of the input.
+	WordBoundary
+)
+
+func (a Anchor) String() string {
+	switch a {
+	case SOI:
+		return "SOI"
+	case EOI:
+		return "EOI"
+	case BOL:
+		return "BOL"
+	case EOL:
+		return "EOL"
+	case WordBoundary:
+		return "WordBoundary"
+	default:
+		return "Anchor(?)"
+	}
+}
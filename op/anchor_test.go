@@ -0,0 +1,60 @@
+package op_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+	"testing"
+)
+
+func ExampleAnchor() {
+	digits := op.MinOne(parser.CheckRuneRange('0', '9'))
+
+	p, _ := parser.New([]byte("42x"))
+	fmt.Println(p.Expect(op.And{op.SOI, digits, op.EOI}))
+
+	p, _ = parser.New([]byte("42 "), parser.WithTrivia(' '))
+	fmt.Println(p.Expect(op.And{op.SOI, digits, op.EOI}))
+	// Output:
+	// <nil> parse conflict [00:003]: expected parser.AnonymousClass func but got 'x'
+	// U+0032: 2 <nil>
+}
+
+func TestAnchor(t *testing.T) {
+	for _, test := range []struct {
+		input  string
+		offset int
+		anchor op.Anchor
+		ok     bool
+	}{
+		{"ab", 0, op.SOI, true},
+		{"ab", 1, op.SOI, false},
+		{"ab", 1, op.EOI, false},
+		{"ab", 2, op.EOI, true},
+		{"a\nb", 0, op.BOL, true},
+		{"a\nb", 1, op.BOL, false},
+		{"a\nb", 2, op.BOL, true},
+		{"a\r\nb", 2, op.BOL, false},
+		{"a\r\nb", 3, op.BOL, true},
+		{"a\r\nb", 0, op.EOL, false},
+		{"a\r\nb", 1, op.EOL, true},
+		{"a\nb", 3, op.EOL, true},
+		{"ab cd", 0, op.WordBoundary, true},
+		{"ab cd", 1, op.WordBoundary, false},
+		{"ab cd", 2, op.WordBoundary, true},
+		{"ab cd", 3, op.WordBoundary, true},
+		{"ab cd", 5, op.WordBoundary, true},
+		{"a_1", 2, op.WordBoundary, false},
+	} {
+		p, _ := parser.New([]byte(test.input))
+		for i := 0; i < test.offset; i++ {
+			p.Next()
+		}
+		if _, err := p.Expect(test.anchor); (err == nil) != test.ok {
+			t.Errorf("%q at %d: %s %v", test.input, test.offset, test.anchor, err)
+		}
+		if p.Offset() != test.offset {
+			t.Errorf("%q at %d: %s consumed input", test.input, test.offset, test.anchor)
+		}
+	}
+}
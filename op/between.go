@@ -0,0 +1,12 @@
+package op
+
+// Between represents a value that is enclosed by an opening and a closing
+// delimiter, e.g. Between{'(', expr, ')'}. If the closing delimiter is missing,
+// the error points at the opening delimiter.
+//
+// In the ast package only the nodes of the body are captured.
+type Between struct {
+	Open  interface{}
+	Body  interface{}
+	Close interface{}
+}
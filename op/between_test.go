@@ -0,0 +1,20 @@
+package op_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+)
+
+func ExampleBetween() {
+	call := op.And{'f', op.Between{Open: '(', Body: op.Optional("args"), Close: ')'}, ';'}
+
+	p, _ := parser.New([]byte("f(args);"))
+	fmt.Println(p.Expect(call))
+
+	p, _ = parser.New([]byte("f(args;"))
+	fmt.Println(p.Expect(call))
+	// Output:
+	// U+003B: ; <nil>
+	// <nil> parse conflict [00:006]: expected int32 ')' but got ';', unclosed '(' opened at [00:001]
+}
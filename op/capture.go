@@ -0,0 +1,16 @@
+package op
+
+// Capture represents a value of which the matched text is stored under the
+// given name, so that it can be referenced by a Backref. Not to be confused
+// with ast.Capture, which creates a node.
+type Capture struct {
+	Name  string
+	Value interface{}
+}
+
+// Backref represents the text that was most recently captured under the given
+// name, e.g. the terminator of a heredoc or the name of a closing tag. It fails
+// if nothing was captured.
+type Backref struct {
+	Name string
+}
@@ -0,0 +1,42 @@
+package op_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+)
+
+func ExampleBackref() {
+	name := op.MinOne(parser.CheckRuneRange('a', 'z'))
+	element := op.And{
+		'<', op.Capture{Name: "tag", Value: name}, '>',
+		op.MinZero(parser.CheckRuneRange('a', 'z')),
+		"</", op.Backref{Name: "tag"}, '>',
+	}
+
+	p, _ := parser.New([]byte("<a>text</a>"))
+	fmt.Println(p.Expect(element))
+	p, _ = parser.New([]byte("<b>text</c>"))
+	fmt.Println(p.Expect(element))
+	// Output:
+	// U+003E: > <nil>
+	// <nil> parse conflict [00:009]: expected op.And '<' tag:func+ '>' func* "</" $tag '>' but got "<b>text</c"
+}
+
+func ExampleCapture() {
+	// Lua long brackets, e.g. [==[ ... ]==].
+	level := op.Capture{Name: "level", Value: op.MinZero('=')}
+	close := op.And{']', op.Backref{Name: "level"}, ']'}
+	str := op.And{
+		'[', level, '[',
+		op.MinZero(op.And{op.Not{Value: close}, parser.CheckRuneFunc(func(r rune) bool { return r != parser.EOD })}),
+		close,
+	}
+
+	p, _ := parser.New([]byte("[==[a]]b]=]c]==]"))
+	fmt.Println(p.Expect(str))
+	fmt.Println(p.Done())
+	// Output:
+	// U+005D: ] <nil>
+	// true
+}
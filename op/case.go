@@ -0,0 +1,8 @@
+package op
+
+// CaseInsensitive represents a value that should be matched using Unicode case
+// folding. e.g. CaseInsensitive{"select"} also matches "SELECT" and "Select".
+// All runes and strings within the value are matched case insensitive.
+type CaseInsensitive struct {
+	Value interface{}
+}
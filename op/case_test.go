@@ -0,0 +1,19 @@
+package op_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+)
+
+func ExampleCaseInsensitive() {
+	p, _ := parser.New([]byte("SELECT * From"))
+
+	fmt.Println(p.Expect(op.CaseInsensitive{Value: "select"}))
+	fmt.Println(p.Expect(" * "))
+	fmt.Println(p.Expect(op.CaseInsensitive{Value: op.And{'f', "ROM"}}))
+	// Output:
+	// U+0054: T <nil>
+	// U+0020:   <nil>
+	// U+006D: m <nil>
+}
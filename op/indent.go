@@ -0,0 +1,15 @@
+package op
+
+// Indented represents a block that is indented more than the current
+// indentation level of the parser. The indentation (spaces and tabs) before the
+// value gets skipped and its (visual) column becomes the indentation level
+// within the value. Use Aligned for the following lines of the block.
+type Indented struct {
+	Value interface{}
+}
+
+// Aligned represents a value that starts at the current indentation level of
+// the parser. The indentation (spaces and tabs) before the value gets skipped.
+type Aligned struct {
+	Value interface{}
+}
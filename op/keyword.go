@@ -0,0 +1,14 @@
+package op
+
+// Keyword represents a string that only matches if it is not followed by a
+// word rune (a letter, digit or '_'), e.g. Keyword("for") does not match the
+// start of "forEach".
+type Keyword string
+
+// Reserved represents a value (e.g. an identifier) that does not match if the
+// matched text is one of the reserved words, e.g. "for" or "if". If the text is
+// reserved, nothing is consumed.
+type Reserved struct {
+	Value interface{}
+	Words []string
+}
@@ -0,0 +1,32 @@
+package op_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+)
+
+func ExampleKeyword() {
+	p, _ := parser.New([]byte("forEach for"))
+	fmt.Println(p.Expect(op.Keyword("for")))
+	p.Expect(op.MinOne(parser.CheckRuneRange('A', 'z')), ' ')
+	fmt.Println(p.Expect(op.Keyword("for")))
+	// Output:
+	// <nil> parse conflict [00:003]: expected op.Keyword keyword("for") but got "forE"
+	// U+0072: r <nil>
+}
+
+func ExampleReserved() {
+	identifier := op.Reserved{
+		Value: op.MinOne(parser.CheckRuneRange('a', 'z')),
+		Words: []string{"if", "else", "for"},
+	}
+
+	p, _ := parser.New([]byte("for"))
+	fmt.Println(p.Expect(identifier))
+	p, _ = parser.New([]byte("format"))
+	fmt.Println(p.Expect(identifier))
+	// Output:
+	// <nil> parse conflict [00:002]: expected op.Reserved reserved(func+) but got "for"
+	// U+0074: t <nil>
+}
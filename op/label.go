@@ -0,0 +1,9 @@
+package op
+
+// Label represents a value that is reported by its name if it fails, e.g.
+// "expected string literal" instead of the rune that failed deep within the
+// value. The name is also used by the trace and the profiler of the parser.
+type Label struct {
+	Name  string
+	Value interface{}
+}
@@ -0,0 +1,29 @@
+package op_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+	"os"
+)
+
+func ExampleLabel() {
+	str := op.Label{
+		Name:  "string literal",
+		Value: op.And{'"', op.MinZero(parser.CheckRuneRange('a', 'z')), '"'},
+	}
+
+	p, _ := parser.New([]byte(`"abc`))
+	fmt.Println(p.Expect(str))
+	number := op.Label{Name: "number", Value: op.MinOne(parser.CheckRuneRange('0', '9'))}
+	fmt.Println(p.Expect(op.Or{str, number}))
+
+	p, _ = parser.New([]byte(`"a"`), parser.WithTrace(os.Stdout))
+	fmt.Println(p.Expect(str))
+	// Output:
+	// <nil> parse conflict [00:000]: expected string literal but got '"'
+	// <nil> parse conflict [00:000]: expected string literal or number but got '"'
+	// > string literal [00:000]
+	// < string literal [00:003] ok
+	// U+0022: " <nil>
+}
@@ -0,0 +1,21 @@
+package op
+
+// Lazy represents a value that gets resolved when it is matched. This allows
+// for (mutually) recursive values, which can not be referenced directly. e.g.
+//
+//	var list op.And
+//	list = op.And{'[', op.MinZero(op.Or{'a', op.Lazy(func() interface{} {
+//		return list
+//	})}), ']'}
+//
+// Package level variables can not refer to themselves, not even lazily, so
+// these need to be assigned in an init function. See Ref.
+type Lazy func() interface{}
+
+// Ref returns a Lazy value that resolves to the value the given pointer points
+// to at the moment it is matched.
+func Ref(v *interface{}) Lazy {
+	return func() interface{} {
+		return *v
+	}
+}
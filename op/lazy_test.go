@@ -0,0 +1,33 @@
+package op_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+)
+
+func ExampleLazy() {
+	var list op.And
+	list = op.And{'[', op.MinZero(op.Or{'a', op.Lazy(func() interface{} {
+		return list
+	})}), ']'}
+
+	p, _ := parser.New([]byte("[a[[a]a]]"))
+	fmt.Println(p.Expect(list))
+	// Output:
+	// U+005D: ] <nil>
+}
+
+var value, pair interface{}
+
+func init() {
+	pair = op.And{'(', op.Ref(&value), ',', op.Ref(&value), ')'}
+	value = op.Or{'x', pair}
+}
+
+func ExampleRef() {
+	p, _ := parser.New([]byte("(x,(x,x))"))
+	fmt.Println(p.Expect(value))
+	// Output:
+	// U+0029: ) <nil>
+}
@@ -0,0 +1,14 @@
+package op
+
+// Lexeme represents a value that should be matched as a single token. Trivia
+// (e.g. whitespace) is skipped before the value, but not within the value.
+// Only relevant if the parser skips trivia automatically.
+type Lexeme struct {
+	Value interface{}
+}
+
+// NoSkip represents a value within which no trivia is skipped, not even before
+// the value. Only relevant if the parser skips trivia automatically.
+type NoSkip struct {
+	Value interface{}
+}
@@ -7,7 +7,8 @@ type Not struct {
 }
 
 // Ensure (&) represents a positive lookup of the Value. This should not consume
-// data. e.g. Ensure{"abc"} should check if the strings is present.
+// data. e.g. Ensure{"abc"} should check if the string is present. Nodes that
+// get captured by the Value are discarded.
 type Ensure struct {
 	Value interface{}
 }
@@ -15,6 +16,26 @@ type Ensure struct {
 // And (&&) represents a sequence of values.
 type And []interface{}
 
+// Fail represents a value that always fails with the given message, e.g. as the
+// last alternative of an Or to explain why the input is not supported. The
+// message takes priority over other expectations at the same position.
+type Fail struct {
+	Message string
+}
+
+// Error represents a value that always fails with the given message and stops
+// the parser with a CutError. Only a Recover can recover from it.
+type Error struct {
+	Message string
+}
+
+// Cut represents a commitment within an And, e.g. And{"if", Cut{}, cond, body}.
+// If a value after the cut fails, the nearest enclosing Or does not try its
+// other alternatives, so the error points at the actual conflict. Lookaheads
+// and repetitions (e.g. Not or Optional) treat it as an ordinary failure.
+// Outside of an And it matches without consuming anything.
+type Cut struct{}
+
 // Or (||) represents a sequence of alternative values. This is an ordered list,
 // if a valid match is found it wil not try the remaining values.
 type Or []interface{}
@@ -22,3 +43,9 @@ type Or []interface{}
 // XOr represents a sequence of exclusive alternative values. Only one of the
 // values van be valid. It can contain only one valid match.
 type XOr []interface{}
+
+// Longest represents a sequence of alternative values of which the one that
+// consumes the most input is chosen. If multiple values consume the same amount
+// of input, the first one is chosen. All values are tried, so side effects of
+// values that are not chosen (e.g. of attached states or Scan) are not undone.
+type Longest []interface{}
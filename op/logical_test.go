@@ -2,6 +2,8 @@ package op_test
 
 import (
 	"fmt"
+	"testing"
+
 	"github.com/di-wu/parser"
 	"github.com/di-wu/parser/op"
 )
@@ -38,7 +40,7 @@ func ExampleAnd() {
 	_, err = p.Expect(op.And{"foo", ' ', "bar", ' ', "baz"})
 	fmt.Println(err)
 	// Output:
-	// parse conflict [00:007]: expected op.And and["foo" ' ' "bar" '_'] but got "foo bar "
+	// parse conflict [00:007]: expected op.And "foo" ' ' "bar" '_' but got "foo bar "
 	// <nil>
 }
 
@@ -53,7 +55,7 @@ func ExampleOr() {
 	// Output:
 	// U+0064: d
 	// U+0074: t
-	// <nil> parse conflict [00:003]: expected op.Or or['d' 't' !'a'] but got 'a'
+	// <nil> parse conflict [00:003]: expected 'd', 't' or !'a' but got 'a'
 }
 
 func ExampleXOr() {
@@ -64,6 +66,90 @@ func ExampleXOr() {
 	_, err = p.Expect(op.XOr{'a', 't'})
 	fmt.Println(err)
 	// Output:
-	// parse conflict [00:001]: expected op.XOr xor['d' "da" "data"] but got "da"
-	// parse conflict [00:000]: expected op.XOr xor['a' 't'] but got 'd'
+	// parse conflict [00:001]: expected op.XOr 'd' ^ "da" ^ "data" but got "da"
+	// parse conflict [00:000]: expected op.XOr 'a' ^ 't' but got 'd'
+}
+
+func ExampleLongest() {
+	operator := op.Longest{'<', "<=", "<<", "<<="}
+
+	p, _ := parser.New([]byte("<<= <="))
+	fmt.Println(p.Expect(operator, ' ')) // "<<="
+	fmt.Println(p.Expect(operator))      // "<="
+	fmt.Println(p.Expect(operator))
+	// Output:
+	// U+0020:   <nil>
+	// U+003D: = <nil>
+	// <nil> parse conflict [00:006]: expected '<', "<=", "<<" or "<<=" but got ""
+}
+
+func ExampleCut() {
+	digit := parser.CheckRuneRange('0', '9')
+	letter := parser.CheckRuneRange('a', 'z')
+	statement := op.Or{
+		op.And{"if", ' ', op.Cut{}, digit, ';'},
+		op.And{op.MinOne(letter), ';'},
+	}
+
+	p, _ := parser.New([]byte("iff;"))
+	fmt.Println(p.Expect(statement))
+	p, _ = parser.New([]byte("if x;"))
+	fmt.Println(p.Expect(statement)) // Does not try the second alternative.
+	// Output:
+	// U+003B: ; <nil>
+	// <nil> parse conflict [00:004]: expected parser.AnonymousClass func but got "x;"
+}
+
+func TestCut_scope(t *testing.T) {
+	for _, test := range []struct {
+		input string
+		value interface{}
+	}{
+		{"fx", op.Not{Value: op.And{'f', op.Cut{}, '(', ')'}}},
+		{"a1", op.Ensure{Value: op.Not{Value: op.And{'a', op.Cut{}, 'b'}}}},
+		{"a1", op.Optional(op.And{'a', op.Cut{}, 'b'})},
+		{"a1", op.MinZero(op.And{'a', op.Cut{}, 'b'})},
+		{"a1", op.Or{op.Or{op.And{'a', op.Cut{}, 'b'}, 'a'}, "a1"}},
+	} {
+		for _, compiled := range []bool{false, true} {
+			value := test.value
+			if compiled {
+				value = parser.Compile(value)
+			}
+			p, _ := parser.New([]byte(test.input))
+			if _, err := p.Expect(value); err != nil {
+				t.Errorf("%q %v: %v", test.input, test.value, err)
+			}
+		}
+	}
+}
+
+func ExampleFail() {
+	digit := parser.CheckRuneRange('0', '9')
+	number := op.Or{
+		op.And{"0o", op.MinOne(digit)},
+		op.And{'0', op.Not{Value: digit}},
+		op.And{parser.CheckRuneRange('1', '9'), op.MinZero(digit)},
+		op.And{'0', op.Fail{Message: "octal literals need the 0o prefix"}},
+	}
+
+	p, _ := parser.New([]byte("0o17"))
+	fmt.Println(p.Expect(number))
+	p, _ = parser.New([]byte("017"))
+	fmt.Println(p.Expect(number))
+	// Output:
+	// U+0037: 7 <nil>
+	// <nil> parse conflict [00:001]: octal literals need the 0o prefix
+}
+
+func ExampleError() {
+	statement := op.Or{
+		op.And{"goto", op.Error{Message: "goto is not supported"}},
+		op.MinOne(parser.CheckRuneRange('a', 'z')),
+	}
+
+	p, _ := parser.New([]byte("goto"))
+	fmt.Println(p.Expect(statement))
+	// Output:
+	// <nil> parse conflict [00:004]: goto is not supported
 }
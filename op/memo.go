@@ -0,0 +1,12 @@
+package op
+
+// Memo represents a value of which the results get memoized by position, so it
+// is only evaluated once per position (packrat parsing). This guarantees linear
+// time for grammars that backtrack a lot, at the cost of memory. Memos can also
+// be left recursive, e.g. expr <- expr '-' term / term.
+//
+// Memos are identified by their address, so always use a pointer (e.g.
+// &op.Memo{Value: ...}) that is created once and reused.
+type Memo struct {
+	Value interface{}
+}
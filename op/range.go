@@ -7,7 +7,7 @@ type Range struct {
 	// Min indicates the lower bound of the range. Values less than 0 will get
 	// interpreted as 0.
 	Min int
-	// Max indicates the lower bound of the range. Values less than Min will be
+	// Max indicates the upper bound of the range. Values less than Min will be
 	// set equal to Min. On exception: -1 indicates that there is no upper bound.
 	Max int
 	// Value to check.
@@ -17,7 +17,7 @@ func ExampleMin() {
 	fmt.Println(p.Expect(op.Min(4, 'a'))) // err
 	// Output:
 	// U+0061: a <nil>
-	// <nil> parse conflict [00:002]: expected op.Range 'a'{4:-1} but got "aaa"
+	// <nil> parse conflict [00:002]: expected op.Range 'a'{4,} but got "aaa"
 }
 
 func ExampleMinZero() {
@@ -79,5 +79,21 @@ func ExampleRepeat() {
 	// Output:
 	// U+0061: a <nil>
 	// U+0062: b <nil>
-	// <nil> parse conflict [00:003]: expected op.Range 'c'{1:1} but got 'b'
+	// <nil> parse conflict [00:003]: expected op.Range 'c'{1} but got 'b'
+}
+
+func ExampleRepeat_escape() {
+	hex := op.Label{Name: "hex digit", Value: op.Or{
+		parser.CheckRuneRange('0', '9'),
+		parser.CheckRuneRange('a', 'f'),
+		parser.CheckRuneRange('A', 'F'),
+	}}
+	escape := op.And{'\\', 'u', op.Repeat(4, hex)}
+
+	p, _ := parser.New([]byte(`\u00e9\u12`))
+	fmt.Println(p.Expect(escape))
+	fmt.Println(p.Expect(escape))
+	// Output:
+	// U+0039: 9 <nil>
+	// <nil> parse conflict [00:010]: expected hex digit but got ""
 }
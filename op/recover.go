@@ -0,0 +1,12 @@
+package op
+
+// Recover represents a value that recovers from errors if the parser has error
+// recovery enabled. If the value fails, the error gets recorded and the parser
+// skips everything up to and including the Sync value (e.g. ';' or '\n'). Use
+// an Ensure as Sync value to not consume it.
+//
+// Without error recovery, Recover behaves the same as its value.
+type Recover struct {
+	Value interface{}
+	Sync  interface{}
+}
@@ -0,0 +1,16 @@
+package op
+
+// Rule represents a reference to a named value that gets resolved when it is
+// matched, which allows for (mutually) recursive values. Errors that occur
+// while matching the value mention the name of the rule. See the grammar
+// package for a registry of rules.
+type Rule struct {
+	Name  string
+	Rules Rules
+}
+
+// Rules resolves named values.
+type Rules interface {
+	// Lookup returns the value that is defined under the given name.
+	Lookup(name string) (interface{}, bool)
+}
@@ -0,0 +1,20 @@
+package op
+
+// Scan represents a value of which the matched text is converted and stored
+// into the given target, for parsers that want values instead of a tree. The
+// target is one of:
+//
+//   - *string or *[]byte: the matched text.
+//   - *[]string: appends the matched text, e.g. for repetitions.
+//   - a pointer to a boolean or number: the text is parsed by strconv.
+//   - encoding.TextUnmarshaler: calls UnmarshalText.
+//   - func(string) error: calls the function.
+//
+// If the conversion fails, the value does not match. The target is only written
+// once the outermost expected value matches, so values that are backtracked
+// over (e.g. a failing alternative of an Or) are not stored. Functions are
+// called at that moment too, their error is returned by the parser.
+type Scan struct {
+	Value interface{}
+	Into  interface{}
+}
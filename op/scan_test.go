@@ -0,0 +1,132 @@
+package op_test
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+)
+
+func ExampleScan() {
+	var (
+		key   string
+		value int
+	)
+	identifier := op.MinOne(parser.CheckRuneRange('a', 'z'))
+	number := op.MinOne(parser.CheckRuneRange('0', '9'))
+	pair := op.And{op.Scan{Value: identifier, Into: &key}, '=', op.Scan{Value: number, Into: &value}}
+
+	p, _ := parser.New([]byte("answer=42"))
+	_, err := p.Expect(pair)
+	fmt.Println(key, value, err)
+
+	var small int8
+	p, _ = parser.New([]byte("300"))
+	fmt.Println(p.Expect(op.Scan{Value: number, Into: &small}))
+	// Output:
+	// answer 42 <nil>
+	// <nil> parse conflict [00:000]: strconv.ParseInt: parsing "300": value out of range
+}
+
+func TestScan(t *testing.T) {
+	var (
+		s       string
+		b       []byte
+		ss      []string
+		ok      bool
+		u       uint16
+		f       float32
+		ip      net.IP
+		visited string
+	)
+	word := op.MinOne(parser.CheckNotRune(' '))
+	for _, test := range []struct {
+		input string
+		into  interface{}
+		check func() bool
+	}{
+		{"abc", &s, func() bool { return s == "abc" }},
+		{"abc", &b, func() bool { return string(b) == "abc" }},
+		{"a b c", &ss, func() bool { return strings.Join(ss, ",") == "a,b,c" }},
+		{"true", &ok, func() bool { return ok }},
+		{"0x1F", &u, func() bool { return u == 31 }},
+		{"1.5e3", &f, func() bool { return f == 1500 }},
+		{"127.0.0.1", &ip, func() bool { return ip.String() == "127.0.0.1" }},
+		{"visited", func(s string) error { visited = s; return nil }, func() bool { return visited == "visited" }},
+	} {
+		p, _ := parser.New([]byte(test.input))
+		scan := op.Scan{Value: word, Into: test.into}
+		if _, err := p.Expect(op.And{scan, op.MinZero(op.And{' ', scan})}); err != nil {
+			t.Errorf("%q: %v", test.input, err)
+			continue
+		}
+		if !test.check() {
+			t.Errorf("%q: unexpected value of %T", test.input, test.into)
+		}
+	}
+
+	for _, test := range []struct {
+		input string
+		into  interface{}
+	}{
+		{"-1", &u},
+		{"yes", &ok},
+		{"1.2.3", &ip},
+		{"1", &[]int{}},
+		{"1", u},
+	} {
+		p, _ := parser.New([]byte(test.input))
+		if _, err := p.Expect(op.Scan{Value: word, Into: test.into}); err == nil {
+			t.Errorf("%q: expected an error for %T", test.input, test.into)
+		}
+		if p.Offset() != 0 {
+			t.Errorf("%q: expected nothing to be consumed", test.input)
+		}
+	}
+}
+
+func TestScan_backtrack(t *testing.T) {
+	var names []string
+	name := op.Scan{Value: op.MinOne(parser.CheckRuneRange('a', 'z')), Into: &names}
+	p, _ := parser.New([]byte("foo;"))
+	if _, err := p.Expect(op.Or{op.And{name, '('}, op.And{name, ';'}}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(names, ",") != "foo" {
+		t.Error(names)
+	}
+
+	// Nothing is stored if the outermost value fails.
+	names = nil
+	p, _ = parser.New([]byte("foo;"))
+	if _, err := p.Expect(name, '('); err == nil || len(names) != 0 {
+		t.Error(names, err)
+	}
+
+	// Functions are called once the outermost value matches.
+	p, _ = parser.New([]byte("foo"))
+	fail := op.Scan{Value: op.MinOne(parser.CheckRuneRange('a', 'z')), Into: func(string) error {
+		return fmt.Errorf("fail")
+	}}
+	if _, err := p.Expect(fail); err == nil || err.Error() != "fail" {
+		t.Error(err)
+	}
+}
+
+func TestScan_lookahead(t *testing.T) {
+	var n string
+	value := op.And{op.Ensure{Value: op.Scan{Value: "ab", Into: &n}}, "a"}
+	for _, i := range []interface{}{value, parser.Compile(value)} {
+		n = ""
+		p, _ := parser.New([]byte("ab"))
+		if _, err := p.Expect(i); err != nil {
+			t.Fatal(err)
+		}
+		if n != "" {
+			t.Errorf("%T: stored %q within a lookahead", i, n)
+		}
+	}
+}
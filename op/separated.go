@@ -0,0 +1,13 @@
+package op
+
+// SeparatedBy represents one or more elements that are separated by a
+// separator, e.g. "a, b, c". If Trailing is true, the last element can be
+// followed by a separator, e.g. "a, b, c,". Wrap it in an Optional to also
+// allow an empty list.
+//
+// In the ast package only the nodes of the elements are captured.
+type SeparatedBy struct {
+	Element   interface{}
+	Separator interface{}
+	Trailing  bool
+}
@@ -0,0 +1,26 @@
+package op_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+)
+
+func ExampleSeparatedBy() {
+	digit := parser.CheckRuneRange('0', '9')
+	list := op.SeparatedBy{Element: digit, Separator: ','}
+
+	p, _ := parser.New([]byte("1,2,3,"))
+	fmt.Println(p.Expect(list))
+	fmt.Println(p.Expect(','))
+
+	list.Trailing = true
+	p, _ = parser.New([]byte("1,2,3,"))
+	fmt.Println(p.Expect(list))
+	fmt.Println(p.Done())
+	// Output:
+	// U+0033: 3 <nil>
+	// U+002C: , <nil>
+	// U+002C: , <nil>
+	// true
+}
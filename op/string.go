@@ -0,0 +1,237 @@
+package op
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Precedence levels of the notation used by String, from low to high.
+const (
+	choice = iota
+	sequence
+	prefix
+	suffix
+)
+
+// String returns the given value in a PEG-like notation, e.g.
+//
+//	'a' ("b" / 'c')+ !'d'
+//
+// Sequences are separated by spaces, ordered alternatives by '/', exclusive
+// alternatives by '^' and longest alternatives by '|'. Functions are shown by
+// their name, anonymous functions as "func". Values that have no notation are
+// shown as function calls, e.g. "lexeme('a')".
+func String(i interface{}) string {
+	return format(i, choice)
+}
+
+// format formats the given value, it gets parenthesized if its precedence is
+// lower than the given one.
+func format(i interface{}, outer int) string {
+	s, inner := notation(i)
+	if inner < outer {
+		return fmt.Sprintf("(%s)", s)
+	}
+	return s
+}
+
+// notation returns the notation of the given value and its precedence.
+func notation(i interface{}) (string, int) {
+	switch v := i.(type) {
+	case nil:
+		return "nil", suffix
+	case rune:
+		return strconv.QuoteRune(v), suffix
+	case int:
+		return strconv.QuoteRune(rune(v)), suffix
+	case string:
+		return strconv.Quote(v), suffix
+	case *Trie:
+		if v == nil {
+			return "nil", suffix
+		}
+		return join(stringValues(v.Strings()), " / ", sequence), choice
+
+	case []interface{}:
+		return notation(And(v))
+	case And:
+		if len(v) == 1 {
+			return notation(v[0])
+		}
+		return join(v, " ", prefix), sequence
+	case Or:
+		return join(v, " / ", sequence), choice
+	case XOr:
+		return join(v, " ^ ", sequence), choice
+	case Longest:
+		return join(v, " | ", sequence), choice
+
+	case Not:
+		return "!" + format(v.Value, suffix), prefix
+	case Ensure:
+		return "&" + format(v.Value, suffix), prefix
+	case Range:
+		return format(v.Value, suffix) + quantifier(v), suffix
+
+	case Anchor:
+		return v.String(), suffix
+	case Cut:
+		return "cut", suffix
+	case Rule:
+		return v.Name, suffix
+	case Label:
+		return v.Name, suffix
+	case Trace:
+		return notation(v.Value)
+	case Backref:
+		return "$" + v.Name, suffix
+	case Capture:
+		return v.Name + ":" + format(v.Value, suffix), prefix
+	case Fail:
+		return fmt.Sprintf("fail(%q)", v.Message), suffix
+	case Error:
+		return fmt.Sprintf("error(%q)", v.Message), suffix
+
+	case Lexeme:
+		return call("lexeme", v.Value), suffix
+	case NoSkip:
+		return call("noskip", v.Value), suffix
+	case CaseInsensitive:
+		return format(v.Value, suffix) + "i", suffix
+	case *Memo:
+		if v == nil {
+			return "nil", suffix
+		}
+		return call("memo", v.Value), suffix
+	case Recover:
+		return call("recover", v.Value, v.Sync), suffix
+	case Indented:
+		return call("indented", v.Value), suffix
+	case Aligned:
+		return call("aligned", v.Value), suffix
+	case SeparatedBy:
+		if v.Trailing {
+			return call("sep", v.Element, v.Separator, "trailing"), suffix
+		}
+		return call("sep", v.Element, v.Separator), suffix
+	case Between:
+		return join([]interface{}{v.Open, v.Body, v.Close}, " ", prefix), sequence
+	case Where:
+		return call("where", v.Value), suffix
+	case Scan:
+		return call("scan", v.Value), suffix
+	case Keyword:
+		return fmt.Sprintf("keyword(%q)", string(v)), suffix
+	case Reserved:
+		return call("reserved", v.Value), suffix
+	case Lazy:
+		return "lazy", suffix
+	}
+
+	if t := reflect.TypeOf(i); t.Kind() == reflect.Func {
+		return funcName(i), suffix
+	}
+	return fmt.Sprintf("%v", i), suffix
+}
+
+// join formats and joins the given values with the given separator.
+func join(values []interface{}, sep string, outer int) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = format(v, outer)
+	}
+	return strings.Join(s, sep)
+}
+
+// call formats the given values as arguments of a function call.
+func call(name string, args ...interface{}) string {
+	return fmt.Sprintf("%s(%s)", name, join(args, ", ", choice))
+}
+
+// quantifier returns the quantifier of the given range, e.g. '*' or '{2,4}'.
+func quantifier(r Range) string {
+	switch {
+	case r.Min <= 0 && r.Max == -1:
+		return "*"
+	case r.Min == 1 && r.Max == -1:
+		return "+"
+	case r.Min <= 0 && r.Max == 1:
+		return "?"
+	case r.Max == -1:
+		return fmt.Sprintf("{%d,}", r.Min)
+	case r.Max <= r.Min:
+		return fmt.Sprintf("{%d}", r.Min)
+	default:
+		return fmt.Sprintf("{%d,%d}", r.Min, r.Max)
+	}
+}
+
+// stringValues converts the given strings to values.
+func stringValues(strings []string) []interface{} {
+	values := make([]interface{}, len(strings))
+	for i, s := range strings {
+		values[i] = s
+	}
+	return values
+}
+
+// funcName returns the name of the given function without the path of its
+// package (e.g. "main.expr"), or "func" if it is anonymous.
+func funcName(i interface{}) string {
+	v := reflect.ValueOf(i)
+	if v.IsNil() {
+		return "nil"
+	}
+	f := runtime.FuncForPC(v.Pointer())
+	if f == nil {
+		return "func"
+	}
+	name := f.Name()
+	if i := strings.LastIndex(name, "/"); i != -1 {
+		name = name[i+1:]
+	}
+	// Method values are named "T.Method-fm".
+	name = strings.TrimSuffix(name, "-fm")
+	for _, part := range strings.Split(name, ".") {
+		// Anonymous functions are named "func1", "func2", etc.
+		if strings.HasPrefix(part, "func") {
+			if _, err := strconv.Atoi(part[len("func"):]); err == nil {
+				return "func"
+			}
+		}
+	}
+	return name
+}
+
+func (t *Trie) String() string           { return String(t) }
+func (a And) String() string             { return String(a) }
+func (o Or) String() string              { return String(o) }
+func (x XOr) String() string             { return String(x) }
+func (l Longest) String() string         { return String(l) }
+func (n Not) String() string             { return String(n) }
+func (e Ensure) String() string          { return String(e) }
+func (r Range) String() string           { return String(r) }
+func (c Cut) String() string             { return String(c) }
+func (r Rule) String() string            { return String(r) }
+func (l Label) String() string           { return String(l) }
+func (t Trace) String() string           { return String(t) }
+func (b Backref) String() string         { return String(b) }
+func (c Capture) String() string         { return String(c) }
+func (f Fail) String() string            { return String(f) }
+func (e Error) String() string           { return String(e) }
+func (l Lexeme) String() string          { return String(l) }
+func (n NoSkip) String() string          { return String(n) }
+func (c CaseInsensitive) String() string { return String(c) }
+func (m *Memo) String() string           { return String(m) }
+func (r Recover) String() string         { return String(r) }
+func (i Indented) String() string        { return String(i) }
+func (a Aligned) String() string         { return String(a) }
+func (s SeparatedBy) String() string     { return String(s) }
+func (b Between) String() string         { return String(b) }
+func (w Where) String() string           { return String(w) }
+func (k Keyword) String() string         { return String(k) }
+func (r Reserved) String() string        { return String(r) }
+func (l Lazy) String() string            { return String(l) }
@@ -0,0 +1,61 @@
+package op_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+	"testing"
+)
+
+func ExampleString() {
+	digit := parser.CheckRuneRange('0', '9')
+	number := op.And{op.Optional('-'), op.Or{'0', op.And{op.Not{Value: '0'}, op.MinOne(digit)}}}
+
+	fmt.Println(number)
+	fmt.Println(op.Or{op.And{'a', 'b'}, op.MinMax(2, 4, op.Or{"c", 'd'})})
+	// Output:
+	// '-'? ('0' / !'0' func+)
+	// 'a' 'b' / ("c" / 'd'){2,4}
+}
+
+func digit(p *parser.Parser) (*parser.Cursor, bool) {
+	return p.Mark(), '0' <= p.Current() && p.Current() <= '9'
+}
+
+func TestString(t *testing.T) {
+	for _, test := range []struct {
+		value interface{}
+		want  string
+	}{
+		{'a', `'a'`},
+		{"a\n", `"a\n"`},
+		{op.And{'a'}, `'a'`},
+		{op.And{'a', op.And{'b', 'c'}}, `'a' ('b' 'c')`},
+		{op.Or{'a', op.Or{'b', 'c'}}, `'a' / ('b' / 'c')`},
+		{op.XOr{'a', op.Longest{'b', "bc"}}, `'a' ^ ('b' | "bc")`},
+		{op.Not{Value: op.And{'a', 'b'}}, `!('a' 'b')`},
+		{op.Ensure{Value: op.MinZero('a')}, `&'a'*`},
+		{op.MinZero(op.Not{Value: 'a'}), `(!'a')*`},
+		{op.Repeat(3, 'a'), `'a'{3}`},
+		{op.Min(2, 'a'), `'a'{2,}`},
+		{op.AnyString("a", "b"), `"a" / "b"`},
+		{op.And{'a', op.AnyString("a", "b")}, `'a' ("a" / "b")`},
+		{op.Capture{Name: "x", Value: 'a'}, `x:'a'`},
+		{op.Backref{Name: "x"}, `$x`},
+		{op.Label{Name: "number", Value: 'a'}, `number`},
+		{op.Trace{Name: "number", Value: op.MinOne('a')}, `'a'+`},
+		{op.Keyword("for"), `keyword("for")`},
+		{op.And{op.SOI, op.Cut{}, op.EOI}, `SOI cut EOI`},
+		{op.Lexeme{Value: op.And{'a', 'b'}}, `lexeme('a' 'b')`},
+		{op.CaseInsensitive{Value: "ab"}, `"ab"i`},
+		{op.SeparatedBy{Element: 'a', Separator: ','}, `sep('a', ',')`},
+		{op.Between{Open: '(', Body: 'a', Close: ')'}, `'(' 'a' ')'`},
+		{op.Fail{Message: "no"}, `fail("no")`},
+		{digit, `op_test.digit`},
+		{op.MinOne(digit), `op_test.digit+`},
+	} {
+		if s := op.String(test.value); s != test.want {
+			t.Errorf("got %s, want %s", s, test.want)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package op
+
+import "io"
+
+// Trace represents a value of which all attempts are logged, including the
+// position of the parser and the result. Unlike the trace of the parser, only
+// this value is logged and not the values within it. e.g.
+//
+//	> number [00:004]
+//	< number [00:006] ok
+type Trace struct {
+	Name  string
+	Value interface{}
+	// Writer to log to, defaults to os.Stderr.
+	Writer io.Writer
+}
@@ -0,0 +1,26 @@
+package op_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+	"os"
+)
+
+func ExampleTrace() {
+	digit := parser.CheckRuneRange('0', '9')
+	number := op.Trace{Name: "number", Value: op.MinOne(digit), Writer: os.Stdout}
+	list := op.And{'[', number, op.MinZero(op.And{',', number}), ']'}
+
+	p, _ := parser.New([]byte("[1,23,x]"))
+	_, err := p.Expect(list)
+	fmt.Println(err)
+	// Output:
+	// > number [00:001]
+	// < number [00:002] ok
+	// > number [00:003]
+	// < number [00:005] ok
+	// > number [00:006]
+	// < number [00:006] parse conflict [00:006]: expected op.Range func+ but got 'x'
+	// parse conflict [00:007]: expected parser.AnonymousClass func but got "x]"
+}
@@ -0,0 +1,54 @@
+package op
+
+// Trie represents a set of strings stored in a prefix tree. The parser matches
+// the longest string of the set, without retrying every string from scratch.
+// Use AnyString to create a Trie.
+type Trie struct {
+	children map[rune]*Trie
+	// end indicates whether a string ends at this node.
+	end bool
+	// strings contains all the strings of the trie, only set for the root.
+	strings []string
+}
+
+// AnyString returns a Trie that matches the longest of the given strings. e.g.
+// AnyString("in", "interface") matches "interface" completely, unlike an Or of
+// both strings. Empty strings are ignored.
+func AnyString(strings ...string) *Trie {
+	root := &Trie{
+		strings: strings,
+	}
+	for _, s := range strings {
+		node := root
+		for _, r := range s {
+			child, ok := node.children[r]
+			if !ok {
+				if node.children == nil {
+					node.children = make(map[rune]*Trie)
+				}
+				child = new(Trie)
+				node.children[r] = child
+			}
+			node = child
+		}
+		if node != root {
+			node.end = true
+		}
+	}
+	return root
+}
+
+// Child returns the node that follows the given rune, or nil if there is none.
+func (t *Trie) Child(r rune) *Trie {
+	return t.children[r]
+}
+
+// End returns whether one of the strings ends at this node.
+func (t *Trie) End() bool {
+	return t.end
+}
+
+// Strings returns the strings the trie was created from.
+func (t *Trie) Strings() []string {
+	return t.strings
+}
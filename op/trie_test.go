@@ -0,0 +1,45 @@
+package op_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+	"testing"
+)
+
+func ExampleAnyString() {
+	keyword := op.AnyString("if", "import", "in", "interface")
+
+	p, _ := parser.New([]byte("interface import"))
+	fmt.Println(p.Expect(keyword))
+	fmt.Println(p.Expect(' ', keyword))
+	fmt.Println(p.Expect(keyword))
+	// Output:
+	// U+0065: e <nil>
+	// U+0074: t <nil>
+	// <nil> parse conflict [00:016]: expected *op.Trie "if" / "import" / "in" / "interface" but got ""
+}
+
+func TestAnyString(t *testing.T) {
+	keyword := op.AnyString("in", "interface", "")
+	for _, test := range []struct {
+		input  string
+		offset int
+		ok     bool
+	}{
+		{"inter", 2, true},
+		{"interface", 9, true},
+		{"i", 0, false},
+		{"", 0, false},
+	} {
+		p, _ := parser.New([]byte(test.input + " "))
+		if _, ok := p.Check(keyword); ok != test.ok || p.Offset() != test.offset {
+			t.Errorf("%q: %v %d", test.input, ok, p.Offset())
+		}
+	}
+
+	p, _ := parser.New([]byte("INTERFACE"))
+	if _, ok := p.Check(op.CaseInsensitive{Value: keyword}); !ok || !p.Done() {
+		t.Error(p.Offset())
+	}
+}
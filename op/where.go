@@ -0,0 +1,9 @@
+package op
+
+// Where represents a value of which the matched text also needs to satisfy the
+// predicate, e.g. to reject numbers greater than 255 or identifiers that are
+// reserved words. If the predicate returns false, nothing is consumed.
+type Where struct {
+	Value interface{}
+	Pred  func(matched string) bool
+}
@@ -0,0 +1,27 @@
+package op_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+	"strconv"
+)
+
+func ExampleWhere() {
+	octet := op.Where{
+		Value: op.MinMax(1, 3, parser.CheckRuneRange('0', '9')),
+		Pred: func(matched string) bool {
+			n, _ := strconv.Atoi(matched)
+			return n <= 255
+		},
+	}
+	ip := op.And{octet, '.', octet, '.', octet, '.', octet}
+
+	p, _ := parser.New([]byte("192.168.0.1"))
+	fmt.Println(p.Expect(ip))
+	p, _ = parser.New([]byte("256"))
+	fmt.Println(p.Expect(octet))
+	// Output:
+	// U+0031: 1 <nil>
+	// <nil> parse conflict [00:002]: expected op.Where where(func{1,3}) but got "256"
+}
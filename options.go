@@ -0,0 +1,49 @@
+package parser
+
+// Option configures a Parser on creation.
+type Option func(p *Parser)
+
+// InvalidUTF8 is a policy that indicates how invalid UTF-8 should be handled.
+type InvalidUTF8 int
+
+const (
+	// ReplaceInvalid replaces every invalid byte by utf8.RuneError (U+FFFD).
+	// This is the default policy.
+	ReplaceInvalid InvalidUTF8 = iota
+	// RejectInvalid makes the constructor return an InvalidUTF8Error if the
+	// input contains invalid UTF-8. Streaming parsers stop when they encounter
+	// invalid UTF-8, the error is returned by Parser.Err.
+	RejectInvalid
+	// SkipInvalid skips all invalid bytes.
+	SkipInvalid
+)
+
+// WithInvalidUTF8 sets the policy for handling invalid UTF-8.
+// Use Cursor.Invalid to check whether invalid bytes were encountered.
+func WithInvalidUTF8(policy InvalidUTF8) Option {
+	return func(p *Parser) {
+		p.invalid = policy
+	}
+}
+
+// defaultTabWidth is the default tab width used to calculate visual columns.
+const defaultTabWidth = 8
+
+// WithTabWidth sets the tab width used to calculate visual columns. The
+// default tab width is 8. Values less than 1 are interpreted as 1.
+func WithTabWidth(width int) Option {
+	return func(p *Parser) {
+		if width < 1 {
+			width = 1
+		}
+		p.tabWidth = width
+	}
+}
+
+// WithFilename sets the name of the file that is being parsed. The filename is
+// included in cursors and errors.
+func WithFilename(filename string) Option {
+	return func(p *Parser) {
+		p.filename = filename
+	}
+}
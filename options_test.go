@@ -0,0 +1,65 @@
+package parser_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"strings"
+)
+
+func ExampleWithInvalidUTF8() {
+	input := []byte("a\xffb")
+
+	p, _ := parser.New(input)
+	fmt.Println(p.Next().Mark(), p.Mark().Invalid())
+
+	_, err := parser.New(input, parser.WithInvalidUTF8(parser.RejectInvalid))
+	fmt.Println(err)
+
+	p, _ = parser.New(input, parser.WithInvalidUTF8(parser.SkipInvalid))
+	fmt.Println(p.Next().Mark(), p.Mark().Invalid())
+	// Output:
+	// U+FFFD: � 1
+	// parser: invalid UTF-8 at offset 1
+	// U+0062: b 1
+}
+
+func ExampleWithInvalidUTF8_stream() {
+	p, _ := parser.NewReader(
+		strings.NewReader("a\xffb"), 8,
+		parser.WithInvalidUTF8(parser.RejectInvalid),
+	)
+	fmt.Println(p.Next().Done())
+	fmt.Println(p.Err())
+	// Output:
+	// true
+	// parser: invalid UTF-8 at offset 1
+}
+
+func ExampleWithTabWidth() {
+	p, _ := parser.New([]byte("\tx\t\ty"), parser.WithTabWidth(4))
+	p.Next() // Point at 'x'.
+	fmt.Println(p.Mark().Position())
+	fmt.Println(p.Mark().VisualColumn())
+
+	p.Next().Next().Next() // Point at 'y'.
+	fmt.Println(p.Mark().Position())
+	fmt.Println(p.Mark().VisualColumn())
+	fmt.Println(p.LookBack().VisualColumn())
+	// Output:
+	// 0 1
+	// 4
+	// 0 4
+	// 12
+	// 8
+}
+
+func ExampleWithFilename() {
+	p, _ := parser.New([]byte("a: 1\nb 2"), parser.WithFilename("config.yaml"))
+	_, _ = p.Expect("a: 1\nb")
+
+	fmt.Println(p.Mark().Filename())
+	fmt.Println(p.Expect(':'))
+	// Output:
+	// config.yaml
+	// <nil> config.yaml:2:2: expected int32 ':' but got ' '
+}
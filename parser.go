@@ -1,7 +1,11 @@
 package parser
 
 import (
+	"context"
+	"errors"
 	"github.com/di-wu/parser/op"
+	"io"
+	"unicode"
 	"unicode/utf8"
 )
 
@@ -11,39 +15,156 @@ const EOD = 1<<31 - 1
 // Parser represents a general purpose parser.
 type Parser struct {
 	buffer []byte
+	// text is the input as a string, only set if created by NewString.
+	text   string
+	stream *stream
 	cursor *Cursor
 	decode func([]byte) (rune, int)
+	// customDecode indicates whether DecodeRune was used.
+	customDecode bool
 
 	converter func(interface{}) interface{}
 	operator  func(interface{}) (*Cursor, error)
+
+	invalid InvalidUTF8
+	bom     bool
+	// fold indicates whether runes and strings are matched case insensitive.
+	fold bool
+	// states that are restored when backtracking.
+	states []State
+	// scans contains the pending writes of op.Scan, see store.
+	scans []func() error
+	// tabWidth is used to calculate visual columns.
+	tabWidth int
+	// filename is the name of the file that is being parsed.
+	filename string
+	// trivia is skipped automatically before runes, strings and classes.
+	trivia interface{}
+
+	// depth is the current number of nested expectations.
+	depth, maxDepth int
+	// fatal is an error that stops the parser, see enter and leave.
+	fatal error
+
+	ctx      context.Context
+	ctxCount int
+	// progress indicates whether repetitions should check for progress.
+	progress bool
+	// memo contains the memoized results, see WithMemoization.
+	memo *memo
+	// farthest is the failure that got the farthest into the input.
+	farthest farthest
+	// skipping indicates whether trivia is being skipped.
+	skipping bool
+	// labels is the number of labels that are being expected, see labeled.
+	labels int
+	// recovery contains the recovered errors, see WithRecovery.
+	recovery *recovery
+	trace    *trace
+	profiler *Profiler
+	hooks    *hooks
+}
+
+// New creates a new Parser. The input is not copied, runes are decoded on
+// demand while the parser advances.
+func New(input []byte, options ...Option) (*Parser, error) {
+	p := Parser{
+		buffer:   input,
+		decode:   utf8.DecodeRune,
+		tabWidth: defaultTabWidth,
+	}
+	for _, option := range options {
+		option(&p)
+	}
+	if err := p.init(); err != nil {
+		return nil, err
+	}
+	return &p, nil
 }
 
-// New creates a new Parser.
-func New(input []byte) (*Parser, error) {
+// NewString creates a new Parser from the given string. Unlike New, the input
+// does not need to be converted to a byte slice. Slice returns substrings of
+// the input instead of copying them.
+func NewString(input string, options ...Option) (*Parser, error) {
 	p := Parser{
-		buffer: input,
-		decode: utf8.DecodeRune,
+		buffer:   unsafeBytes(input),
+		text:     input,
+		decode:   utf8.DecodeRune,
+		tabWidth: defaultTabWidth,
+	}
+	for _, option := range options {
+		option(&p)
+	}
+	if err := p.init(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Reset resets the parser to the start of the given input. This allows you to
+// reuse the parser, it keeps its options, decoder, converter and operator.
+func (p *Parser) Reset(input []byte) error {
+	p.buffer = input
+	p.text = ""
+	p.stream = nil
+	p.depth, p.fatal = 0, nil
+	p.scans = p.scans[:0]
+	if p.memo != nil {
+		p.memo.entries = nil
+	}
+	if p.recovery != nil {
+		p.recovery.errors = nil
+	}
+	return p.init()
+}
+
+// init decodes the first rune of the input.
+func (p *Parser) init() error {
+	if p.bom && p.stream == nil {
+		p.stripBOM()
+	}
+	if p.invalid == RejectInvalid && p.stream == nil {
+		for position := 0; position < len(p.buffer); {
+			current, size := p.decode(p.buffer[position:])
+			if current == utf8.RuneError && size == 1 {
+				return &InvalidUTF8Error{
+					Offset: position,
+				}
+			}
+			position += size
+		}
 	}
 
-	current, size := p.decode(p.buffer)
-	if size == 0 {
+	if p.cursor == nil {
+		p.cursor = new(Cursor)
+	}
+	*p.cursor = Cursor{
+		filename: p.filename,
+	}
+	p.read(p.cursor)
+	if p.cursor.Rune == EOD {
 		// Nothing got decoded.
-		return nil, &InitError{
+		return &InitError{
 			Message: "failed to scan the first rune",
 		}
 	}
+	return nil
+}
 
-	p.cursor = &Cursor{
-		Rune: current,
-		size: size,
+// bytes returns the input starting from the given position. When streaming,
+// only the bytes needed to decode a single rune are returned.
+func (p *Parser) bytes(position int) []byte {
+	if p.stream != nil {
+		return p.stream.bytes(position)
 	}
-	return &p, nil
+	return p.buffer[position:]
 }
 
 // DecodeRune allows you to redefine the way runes are decoded form the byte
 // stream. By default utf8.DecodeRune is used.
 func (p *Parser) DecodeRune(d func(p []byte) (rune, int)) {
 	p.decode = d
+	p.customDecode = true
 }
 
 // SetConverter allows you to add additional (prioritized) converters to the
@@ -58,38 +179,126 @@ func (p *Parser) SetOperator(o func(i interface{}) (*Cursor, error)) {
 	p.operator = o
 }
 
+// SetCaseInsensitive sets whether runes and strings are matched using Unicode
+// case folding. See op.CaseInsensitive to only match specific values case
+// insensitive.
+func (p *Parser) SetCaseInsensitive(fold bool) {
+	p.fold = fold
+}
+
+// CaseInsensitive returns whether runes and strings are matched using Unicode
+// case folding.
+func (p *Parser) CaseInsensitive() bool {
+	return p.fold
+}
+
+// equal checks whether the current rune is equal to the given rune.
+func (p *Parser) equal(r rune) bool {
+	if p.fold {
+		return equalFold(p.cursor.Rune, r)
+	}
+	return p.cursor.Rune == r
+}
+
+// child returns the child of the given trie node that follows the current rune.
+func (p *Parser) child(node *op.Trie) *op.Trie {
+	current := p.cursor.Rune
+	if child := node.Child(current); child != nil || !p.fold {
+		return child
+	}
+	for r := unicode.SimpleFold(current); r != current; r = unicode.SimpleFold(r) {
+		if child := node.Child(r); child != nil {
+			return child
+		}
+	}
+	return nil
+}
+
+// equalFold checks whether the given runes are equal under Unicode case
+// folding.
+func equalFold(a, b rune) bool {
+	if a == b {
+		return true
+	}
+	for r := unicode.SimpleFold(a); r != a; r = unicode.SimpleFold(r) {
+		if r == b {
+			return true
+		}
+	}
+	return false
+}
+
 // Next advances the parser by one rune.
 func (p *Parser) Next() *Parser {
 	if p.Done() {
 		return p
 	}
 
+	var (
+		previous = p.cursor.Rune
+		size     = p.cursor.size
+	)
+	p.cursor.previousColumn = p.cursor.column
+	p.cursor.previousVisual = p.cursor.visual
 	// Move position to the next rune.
 	// |__|_| < next rune is position + size
 	//  ^
 	//  rune of size 2, position 0
-	p.cursor.position += p.cursor.size
+	p.cursor.position += size
 
-	current, size := p.decode(p.buffer[p.cursor.position:])
-	if size == 0 {
-		// Nothing got decoded.
-		current = EOD
-	}
+	p.read(p.cursor)
 
 	// Previous rune was an end of line, we are on a new line now.
-	if p.cursor.Rune == '\n' || (p.cursor.Rune == '\r' && current != '\n') {
+	if previous == '\n' || (previous == '\r' && p.cursor.Rune != '\n') {
 		p.cursor.row += 1
 		p.cursor.column = 0
+		p.cursor.visual = 0
 	} else {
-		p.cursor.column += p.cursor.size
+		p.cursor.column += size
+		p.cursor.visual = p.advanceVisual(p.cursor.visual, previous)
 	}
 
-	p.cursor.Rune = current
-	p.cursor.size = size
-
 	return p
 }
 
+// read decodes the rune at the position of the given cursor. Invalid UTF-8 is
+// handled based on the InvalidUTF8 policy of the parser.
+func (p *Parser) read(c *Cursor) {
+	c.invalid = 0
+	for {
+		current, size := p.decode(p.bytes(c.position))
+		if size == 0 {
+			// Nothing got decoded.
+			c.Rune, c.size = EOD, 0
+			return
+		}
+		if current != utf8.RuneError || size != 1 {
+			c.Rune, c.size = current, size
+			return
+		}
+
+		switch p.invalid {
+		case SkipInvalid:
+			c.position++
+			c.invalid++
+		case RejectInvalid:
+			// Only reachable when streaming, other input gets validated
+			// when the parser is initialized.
+			if p.stream != nil && (p.stream.err == nil || p.stream.err == io.EOF) {
+				p.stream.err = &InvalidUTF8Error{
+					Offset: c.position,
+				}
+			}
+			c.Rune, c.size = EOD, 0
+			return
+		default:
+			c.Rune, c.size = current, size
+			c.invalid = 1
+			return
+		}
+	}
+}
+
 // Current returns the value to which the cursor is pointing at.
 func (p *Parser) Current() rune {
 	return p.cursor.Rune
@@ -100,12 +309,44 @@ func (p *Parser) Done() bool {
 	return p.cursor.Rune == EOD
 }
 
+// Offset returns the byte offset of the current rune in the input.
+func (p *Parser) Offset() int {
+	return p.cursor.position
+}
+
+// Consumed returns the input before the current rune. When streaming, only the
+// part of the input that is still inside the window is returned.
+func (p *Parser) Consumed() string {
+	if p.stream != nil {
+		return p.slice(p.stream.ring.start, p.cursor.position)
+	}
+	return p.slice(0, p.cursor.position)
+}
+
+// Remaining returns the input starting from the current rune. When streaming,
+// only the part of the input that is already read is returned.
+func (p *Parser) Remaining() string {
+	if p.Done() {
+		return ""
+	}
+	if p.stream != nil {
+		return p.slice(p.cursor.position, p.stream.ring.end())
+	}
+	return p.slice(p.cursor.position, len(p.buffer))
+}
+
 // Mark returns a copy of the current cursor.
 func (p *Parser) Mark() *Cursor {
 	mark := *p.cursor
 	return &mark
 }
 
+// MarkV works the same as Mark, but returns the cursor as a value so it does
+// not need to be allocated. Use Jump(&mark) to jump back to it.
+func (p *Parser) MarkV() Cursor {
+	return *p.cursor
+}
+
 // LookBack returns the previous cursor without decreasing the parser.
 func (p *Parser) LookBack() *Cursor {
 	if p.cursor.position == 0 || p.Done() {
@@ -113,21 +354,16 @@ func (p *Parser) LookBack() *Cursor {
 		return p.Mark()
 	}
 
-	// We don't know the size of the previous rune... 1 or more?
-	previous, size := p.decode(p.buffer[p.cursor.position-1:])
-	for i := 2; previous == utf8.RuneError; i++ {
-		previous, size = p.decode(p.buffer[p.cursor.position-i:])
-	}
+	previous, size := p.decodeLast(p.cursor.position)
 
-	var (
-		row    = p.cursor.row
-		column = p.cursor.column
-	)
-	if p.cursor.Rune == '\n' || (p.cursor.Rune == '\r' && p.Peek().Rune != '\n') {
-		row -= 1
-		column = 0
-	} else {
-		column -= size
+	row, column, visual := p.cursor.row, p.cursor.previousColumn, p.cursor.previousVisual
+	if previous == '\n' || (previous == '\r' && p.cursor.Rune != '\n') {
+		// The previous rune ends the previous line.
+		row--
+	}
+	if column < 0 {
+		// The cursor itself was returned by LookBack.
+		column, visual = p.columnsOf(p.cursor.position - size)
 	}
 
 	return &Cursor{
@@ -136,20 +372,144 @@ func (p *Parser) LookBack() *Cursor {
 		position: p.cursor.position - size,
 		row:      row,
 		column:   column,
+		visual:   visual,
+		filename: p.cursor.filename,
+		user:     p.cursor.user,
+		indent:   p.cursor.indent,
+		captures: p.cursor.captures,
+		// The rune before the previous rune is not tracked.
+		previousColumn: -1,
+		previousVisual: -1,
+	}
+}
+
+// columnsOf returns the column and visual column of the rune at the given
+// position by scanning the runes from the start of its line.
+func (p *Parser) columnsOf(position int) (int, int) {
+	// Find the start of the line.
+	var (
+		start   = position
+		next, _ = p.decode(p.bytes(position))
+	)
+	for start != 0 {
+		r, size := p.decodeLast(start)
+		if r == '\n' || (r == '\r' && next != '\n') {
+			break
+		}
+		start -= size
+		next = r
+	}
+
+	var column, visual int
+	for start < position {
+		r, size := p.decode(p.bytes(start))
+		start += size
+		column += size
+		visual = p.advanceVisual(visual, r)
+	}
+	return column, visual
+}
+
+// advanceVisual returns the visual column after the given rune.
+func (p *Parser) advanceVisual(visual int, r rune) int {
+	if r == '\t' {
+		return (visual/p.tabWidth + 1) * p.tabWidth
 	}
+	return visual + 1
+}
+
+// decodeLast decodes the rune that ends right before the given position.
+func (p *Parser) decodeLast(position int) (rune, int) {
+	// We don't know the size of the previous rune... 1 or more?
+	previous, size := p.decode(p.bytes(position - 1))
+	for i := 2; previous == utf8.RuneError && i <= position; i++ {
+		previous, size = p.decode(p.bytes(position - i))
+	}
+	return previous, size
+}
+
+// LookBackRune returns the rune n positions before the current rune without
+// decreasing the parser. LookBackRune(1) returns the previous rune. Returns
+// EOD if there are less than n preceding runes.
+func (p *Parser) LookBackRune(n int) rune {
+	position := p.lookBack(n)
+	if position < 0 {
+		return EOD
+	}
+	r, _ := p.decode(p.bytes(position))
+	return r
+}
+
+// LookBackSlice returns the (at most) n runes preceding the current rune.
+func (p *Parser) LookBackSlice(n int) string {
+	position := p.lookBack(n)
+	if position < 0 {
+		position = 0
+	}
+	return p.slice(position, p.cursor.position)
+}
+
+// lookBack returns the position of the rune n positions before the current
+// rune. Returns -1 if there are less than n preceding runes.
+func (p *Parser) lookBack(n int) int {
+	position := p.cursor.position
+	for i := 0; i < n; i++ {
+		if position == 0 {
+			return -1
+		}
+		_, size := p.decodeLast(position)
+		position -= size
+	}
+	return position
 }
 
 // Peek returns the next cursor without advancing the parser.
 func (p *Parser) Peek() *Cursor {
-	start := p.Mark()
-	defer p.Jump(start)
+	start := p.MarkV()
+	defer p.Jump(&start)
 	return p.Next().Mark()
 }
 
+// PeekRune returns the rune n positions after the current rune without
+// advancing the parser. PeekRune(0) returns the current rune, PeekRune(1) the
+// next one. Returns EOD if the end of the data is reached.
+func (p *Parser) PeekRune(n int) rune {
+	start := p.MarkV()
+	defer p.Jump(&start)
+	for i := 0; i < n; i++ {
+		p.Next()
+	}
+	return p.cursor.Rune
+}
+
+// PeekSlice returns the (at most) n runes starting from the current rune
+// without advancing the parser.
+func (p *Parser) PeekSlice(n int) string {
+	start := p.MarkV()
+	defer p.Jump(&start)
+	for i := 0; i < n && !p.Done(); i++ {
+		p.Next()
+	}
+	return p.slice(start.position, p.cursor.position)
+}
+
 // Jump goes to the position of the given mark.
+//
+// When streaming, jumping to a mark that is not inside the window anymore
+// stops the parser. See NewReader.
 func (p *Parser) Jump(mark *Cursor) *Parser {
 	cursor := *mark
-	p.cursor = &cursor
+	if p.stream != nil && cursor.position < p.stream.ring.start {
+		if p.stream.err == nil || p.stream.err == io.EOF {
+			p.stream.err = &InvalidMarkError{
+				Mark: *mark,
+			}
+		}
+		cursor.Rune = EOD
+	}
+	// Copy the mark into the cursor, so the mark can be reused and jumping
+	// does not allocate.
+	*p.cursor = cursor
 	return p
 }
 
@@ -162,13 +522,57 @@ func (p *Parser) Slice(start *Cursor, end *Cursor) string {
 	if end == nil { // Just to be sure...
 		end = start
 	}
-	return string(p.buffer[start.position : end.position+end.size])
+	return p.slice(start.position, end.position+end.size)
+}
+
+// SliceBytes returns the bytes in between the two given cursors [start:end].
+// The end value is inclusive! Unless the parser was created by NewString or
+// NewReader, the returned slice shares its data with the input.
+func (p *Parser) SliceBytes(start *Cursor, end *Cursor) []byte {
+	if start.Rune == EOD {
+		return nil
+	}
+	if end == nil {
+		end = start
+	}
+	from, to := start.position, end.position+end.size
+	if p.stream != nil {
+		return p.stream.slice(from, to)
+	}
+	if p.text != "" {
+		return []byte(p.text[from:to])
+	}
+	return p.buffer[from:to]
+}
+
+// Text returns the value in between the two given cursors [start:end). Unlike
+// Slice, the end value is exclusive, e.g. the text from a mark up to the
+// current position. Returns an empty string if the end is before the start.
+func (p *Parser) Text(start *Cursor, end *Cursor) string {
+	if end.position <= start.position {
+		return ""
+	}
+	return p.slice(start.position, end.position)
+}
+
+// slice returns the input in between the given positions [from:to].
+func (p *Parser) slice(from, to int) string {
+	if p.stream != nil {
+		return string(p.stream.slice(from, to))
+	}
+	if p.text != "" {
+		return p.text[from:to]
+	}
+	return string(p.buffer[from:to])
 }
 
 // Expect checks whether the buffer contains the given value. It consumes their
 // corresponding runes and returns a mark to the last rune of the consumed
 // value. It returns an error if can not find a match with the given value.
 //
+// Multiple values are expected in sequence, the same as passing an op.And. If
+// one of the values does not match, the parser goes back to the start.
+//
 // It currently supports:
 //	- rune & string
 //	- func(p *Parser) (*Cursor, bool)
@@ -176,9 +580,52 @@ func (p *Parser) Slice(start *Cursor, end *Cursor) string {
 //	- []interface{}
 //	  (== op.And)
 //	- operators: op.Not, op.And, op.Or & op.XOr
-func (p *Parser) Expect(i interface{}) (*Cursor, error) {
-	state := state{p: p}
+//	- *Compiled (see Compile)
+func (p *Parser) Expect(i interface{}, is ...interface{}) (*Cursor, error) {
+	if len(is) != 0 {
+		i = append(op.And{i}, is...)
+	}
+	if c, ok := i.(*Compiled); ok {
+		if p.interpreted() {
+			return p.Expect(c.value)
+		}
+		return c.match(p)
+	}
+	return p.run(i, (*Parser).expect)
+}
+
+// run enters the given value, matches it with the given function and leaves it
+// again. The attached states are restored if the value does not match.
+func (p *Parser) run(i interface{}, match func(p *Parser, i interface{}) (*Cursor, error)) (*Cursor, error) {
+	if err := p.enter(i); err != nil {
+		return nil, err
+	}
+
+	var tx *Transaction
+	if len(p.states) != 0 {
+		// Restore the attached states on failure.
+		tx = p.Begin()
+	}
+	scans := len(p.scans)
+	mark, err := match(p, i)
+	if err != nil {
+		// Do not store the values that were scanned.
+		p.scans = p.scans[:scans]
+	}
+	if fatal := p.leave(i, err); fatal != nil {
+		mark, err = nil, fatal
+	}
+	if tx != nil {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}
+	return mark, err
+}
 
+func (p *Parser) expect(i interface{}) (*Cursor, error) {
 	i = ConvertAliases(i)
 	if p.converter != nil {
 		// Can undo previous conversions!
@@ -193,9 +640,34 @@ func (p *Parser) Expect(i interface{}) (*Cursor, error) {
 			return mark, err
 		}
 	}
+	if m, ok := i.(*op.Memo); ok {
+		return p.memoized(m)
+	}
+	return p.match(i)
+}
+
+// match matches the given (converted) value.
+func (p *Parser) match(i interface{}) (*Cursor, error) {
+	switch i.(type) {
+	case rune, string, *op.Trie, AnonymousClass:
+		if p.trivia != nil {
+			// The skipped trivia is not consumed if the value does not match.
+			origin := p.MarkV()
+			p.SkipTrivia()
+			trivia := p.trivia
+			p.trivia = nil
+			last, err := p.match(i)
+			p.trivia = trivia
+			if err != nil {
+				p.Jump(&origin)
+			}
+			return last, err
+		}
+	}
+	state := state{p: p}
 	switch start := p.Mark(); v := i.(type) {
 	case rune:
-		if p.cursor.Rune != v {
+		if !p.equal(v) {
 			return nil, p.ExpectedParseError(v, start, start)
 		}
 		state.Ok(p.Mark())
@@ -205,15 +677,48 @@ func (p *Parser) Expect(i interface{}) (*Cursor, error) {
 				Message: "can not parse empty string",
 			}
 		}
-		for _, r := range []rune(v) {
-			if p.cursor.Rune != r {
-				return nil, p.ExpectedParseError(v, start, p.Mark())
+		if p.hasPrefix(v) {
+			// Fast path, only the last mark is allocated.
+			var last Cursor
+			for range v {
+				last = *p.cursor
+				p.Next()
+			}
+			return &last, nil
+		}
+		for _, r := range v {
+			if !p.equal(r) {
+				// Reuse the interface of the value, so it is not allocated.
+				end := p.MarkV()
+				return nil, p.ExpectedParseError(i, start, &end)
 			}
 			state.Ok(p.Mark())
 		}
 
+	case *op.Trie:
+		var last *Cursor
+		for node := v; ; {
+			if node = p.child(node); node == nil {
+				break
+			}
+			mark := *p.cursor
+			p.Next()
+			if node.End() {
+				// Longest match so far.
+				last = &mark
+			}
+		}
+		if last == nil {
+			return nil, p.ExpectedParseError(v, start, start)
+		}
+		state.Ok(last)
+
 	case AnonymousClass:
+		// Classes are matched as a whole, without skipping trivia.
+		trivia := p.trivia
+		p.trivia = nil
 		last, passed := v(p)
+		p.trivia = trivia
 		if !passed {
 			if last == nil {
 				last = start
@@ -222,27 +727,247 @@ func (p *Parser) Expect(i interface{}) (*Cursor, error) {
 		}
 		state.Ok(last)
 
+	case op.Lexeme:
+		p.SkipTrivia()
+		last, err := p.lexeme(v.Value)
+		if err != nil {
+			p.Jump(start)
+			return nil, err
+		}
+		state.Ok(last)
+	case op.NoSkip:
+		last, err := p.lexeme(v.Value)
+		if err != nil {
+			return nil, err
+		}
+		state.Ok(last)
+
+	case op.CaseInsensitive:
+		fold := p.fold
+		p.fold = true
+		last, err := p.Expect(v.Value)
+		p.fold = fold
+		if err != nil {
+			return nil, err
+		}
+		state.Ok(last)
+
+	case *op.Memo:
+		last, err := p.Expect(v.Value)
+		if err != nil {
+			return nil, err
+		}
+		state.Ok(last)
+
+	case op.Capture:
+		begin, last, text, err := p.matchText(v.Value)
+		if err == nil {
+			err = p.matchedText(v, begin, last, text)
+		}
+		if err != nil {
+			p.Jump(start)
+			return nil, err
+		}
+		state.Ok(last)
+	case op.Backref:
+		text, ok := p.captured(v.Name)
+		if !ok {
+			return nil, p.ExpectedParseError(v, start, start)
+		}
+		if text == "" {
+			break
+		}
+		last, err := p.Expect(text)
+		if err != nil {
+			return nil, err
+		}
+		state.Ok(last)
+
+	case op.Keyword:
+		p.SkipTrivia()
+		begin := p.Mark()
+		last, err := p.Expect(string(v))
+		if err != nil {
+			p.Jump(start)
+			var conflict *ExpectedParseError
+			if errors.As(err, &conflict) {
+				conflict.Expected = v
+			}
+			return nil, err
+		}
+		if !p.Done() && isWord(p.cursor.Rune) {
+			// e.g. "forEach" for the keyword "for".
+			err := p.ExpectedParseError(v, begin, p.Mark())
+			p.Jump(start)
+			return nil, err
+		}
+		state.Ok(last)
+	case op.Reserved:
+		begin, last, text, err := p.matchText(v.Value)
+		if err == nil {
+			err = p.matchedText(v, begin, last, text)
+		}
+		if err != nil {
+			p.Jump(start)
+			return nil, err
+		}
+		state.Ok(last)
+
+	case op.Where:
+		begin, last, text, err := p.matchText(v.Value)
+		if err == nil {
+			err = p.matchedText(v, begin, last, text)
+		}
+		if err != nil {
+			p.Jump(start)
+			return nil, err
+		}
+		state.Ok(last)
+
+	case op.Scan:
+		begin, last, text, err := p.matchText(v.Value)
+		if err == nil {
+			err = p.matchedText(v, begin, last, text)
+		}
+		if err != nil {
+			p.Jump(start)
+			return nil, err
+		}
+		state.Ok(last)
+
+	case op.Label:
+		var last *Cursor
+		if err := p.labeled(v, func() (err error) {
+			last, err = p.Expect(v.Value)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		state.Ok(last)
+
+	case op.Trace:
+		var last *Cursor
+		if err := p.traced(v, func() (err error) {
+			last, err = p.Expect(v.Value)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		state.Ok(last)
+
+	case op.Lazy:
+		last, err := p.Expect(v())
+		if err != nil {
+			return nil, err
+		}
+		state.Ok(last)
+	case op.Rule:
+		value, ok := v.Rules.Lookup(v.Name)
+		if !ok {
+			return nil, &UndefinedRuleError{Name: v.Name}
+		}
+		last, err := p.Expect(value)
+		if err != nil {
+			return nil, NewRuleError(v.Name, err)
+		}
+		state.Ok(last)
+
+	case op.Recover:
+		last, err := p.Expect(v.Value)
+		if err != nil {
+			var ok bool
+			if last, ok = p.recover(err, v.Sync); !ok {
+				return nil, err
+			}
+		}
+		state.Ok(last)
+
+	case op.Indented:
+		last := p.skipIndentation()
+		column := p.cursor.visual
+		if column <= p.IndentLevel() {
+			return nil, p.ExpectedParseError(v, start, p.Mark())
+		}
+		p.PushIndent(column)
+		mark, err := p.Expect(v.Value)
+		if err != nil {
+			p.Jump(start)
+			return nil, err
+		}
+		p.PopIndent()
+		if mark != nil {
+			last = mark
+		}
+		state.Ok(last)
+	case op.Aligned:
+		last := p.skipIndentation()
+		if p.cursor.visual != p.IndentLevel() {
+			return nil, p.ExpectedParseError(v, start, p.Mark())
+		}
+		mark, err := p.Expect(v.Value)
+		if err != nil {
+			p.Jump(start)
+			return nil, err
+		}
+		if mark != nil {
+			last = mark
+		}
+		state.Ok(last)
+
 	case op.Not:
 		defer p.Jump(start)
 		if last, err := p.Expect(v.Value); err == nil {
 			return nil, p.ExpectedParseError(v, start, last)
 		}
 	case op.Ensure:
+		scans := len(p.scans)
 		if last, err := p.Expect(v.Value); err != nil {
+			// The cut does not reach beyond the lookahead.
+			err, _ = cutError(err)
 			return last, err
 		}
 		p.Jump(start)
+		// Nothing is consumed, so the scanned values are not stored either.
+		p.scans = p.scans[:scans]
+	case op.Anchor:
+		if !p.anchored(v) {
+			// Point at the conflict, not at the trivia in front of it.
+			conflict := p.Mark()
+			err := p.ExpectedParseError(v, conflict, conflict)
+			p.Jump(start)
+			return nil, err
+		}
+		// Anchors do not consume anything, not even trivia.
+		p.Jump(start)
+	case op.Fail:
+		return nil, p.ExpectedParseError(v, start, start)
+	case op.Error:
+		return nil, p.stop(p.cutParseError(p.ExpectedParseError(v, start, start)))
+	case op.Cut:
 	case op.And:
-		var last *Cursor
+		var (
+			last *Cursor
+			cut  bool
+		)
 		for _, i := range v {
+			if _, ok := i.(op.Cut); ok {
+				cut = true
+				continue
+			}
 			mark, err := p.Expect(i)
 			if err != nil {
+				if _, ok := cutError(err); cut || ok {
+					p.Jump(start)
+					return nil, p.cutParseError(err)
+				}
 				if last == nil {
 					last = start
 				}
-				return nil, p.ExpectedParseError(v, start, p.Jump(last).Peek())
+				return nil, p.FarthestParseError(v, start, p.Jump(last).Peek())
+			}
+			if mark != nil {
+				last = mark
 			}
-			last = mark
 		}
 		state.Ok(last)
 	case op.Or:
@@ -253,11 +978,33 @@ func (p *Parser) Expect(i interface{}) (*Cursor, error) {
 				last = mark
 				break
 			}
+			if err, ok := cutError(err); ok {
+				// Committed to this alternative.
+				return nil, err
+			}
 		}
 		if last == nil {
-			return nil, p.ExpectedParseError(v, start, start)
+			return nil, p.FarthestParseError(v, start, start)
 		}
 		state.Ok(last)
+	case op.Longest:
+		var (
+			last *Cursor
+			end  Cursor
+		)
+		position := -1
+		for _, i := range v {
+			mark, err := p.Expect(i)
+			if err == nil && position < p.cursor.position {
+				last, end, position = mark, p.MarkV(), p.cursor.position
+			}
+			p.Jump(start)
+		}
+		if position == -1 {
+			return nil, p.FarthestParseError(v, start, start)
+		}
+		p.Jump(&end)
+		state.Ok(last)
 	case op.XOr:
 		var last *Cursor
 		for _, i := range v {
@@ -276,19 +1023,75 @@ func (p *Parser) Expect(i interface{}) (*Cursor, error) {
 		}
 		state.Ok(last)
 
+	case op.Between:
+		p.SkipTrivia()
+		opened := p.Mark()
+		if _, err := p.Expect(v.Open); err != nil {
+			p.Jump(start)
+			return nil, err
+		}
+		if _, err := p.Expect(v.Body); err != nil {
+			p.Jump(start)
+			return nil, err
+		}
+		last, err := p.Expect(v.Close)
+		if err != nil {
+			err = p.unclosedParseError(v.Open, opened, err)
+			p.Jump(start)
+			return nil, err
+		}
+		state.Ok(last)
+	case op.SeparatedBy:
+		last, err := p.Expect(v.Element)
+		if err != nil {
+			return nil, err
+		}
+		state.Ok(last)
+		for {
+			offset, mark := p.cursor.position, p.MarkV()
+			separator, err := p.Expect(v.Separator)
+			if err != nil {
+				break
+			}
+			element, err := p.Expect(v.Element)
+			if err != nil {
+				if v.Trailing {
+					state.Ok(separator)
+				} else {
+					p.Jump(&mark)
+				}
+				break
+			}
+			if p.cursor.position == offset {
+				// Neither the separator nor the element consumed anything.
+				if err := p.noProgress(v); err != nil {
+					return nil, err
+				}
+				break
+			}
+			state.Ok(element)
+		}
 	case op.Range:
 		var (
 			count int
 			last  *Cursor
 		)
 		for {
+			offset := p.cursor.position
 			mark, err := p.Expect(v.Value)
 			if err != nil {
 				break
 			}
-			last = mark
+			if mark != nil {
+				last = mark
+			}
 			count++
 
+			if p.cursor.position == offset {
+				if err := p.noProgress(v); err != nil {
+					return nil, err
+				}
+			}
 			if v.Max != -1 && count == v.Max {
 				// Break if you have parsed the maximum amount of values.
 				// This way count will never be larger than v.Max.
@@ -308,16 +1111,43 @@ func (p *Parser) Expect(i interface{}) (*Cursor, error) {
 	return state.End(), nil
 }
 
+// hasPrefix checks whether the input at the current position starts with the
+// bytes of the given string. Always returns false if the bytes do not directly
+// correspond to the runes that would be decoded, e.g. when streaming or when
+// matching case insensitive.
+func (p *Parser) hasPrefix(s string) bool {
+	if !p.raw() {
+		return false
+	}
+	position := p.cursor.position
+	return len(s) <= len(p.buffer)-position && string(p.buffer[position:position+len(s)]) == s
+}
+
+// raw returns whether runes can be matched by comparing the bytes of the input.
+func (p *Parser) raw() bool {
+	return p.stream == nil && !p.fold && !p.customDecode && p.invalid != SkipInvalid
+}
+
 // Check works the same as Parser.Expect, but instead it returns a bool instead
 // of an error.
-func (p *Parser) Check(i interface{}) (*Cursor, bool) {
-	mark, err := p.Expect(i)
+func (p *Parser) Check(i interface{}, is ...interface{}) (*Cursor, bool) {
+	mark, err := p.Expect(i, is...)
 	if err != nil {
 		return mark, false
 	}
 	return mark, true
 }
 
+// CheckV works the same as Parser.Check, but returns the last mark as a value.
+// The zero Cursor is returned if the value did not consume anything.
+func (p *Parser) CheckV(i interface{}, is ...interface{}) (Cursor, bool) {
+	mark, ok := p.Check(i, is...)
+	if mark == nil {
+		return Cursor{}, ok
+	}
+	return *mark, ok
+}
+
 // ConvertAliases converts various default primitive types to aliases for type
 // matching.
 //
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/di-wu/parser"
 	"github.com/di-wu/parser/op"
+	"strings"
 	"testing"
 )
 
@@ -63,6 +64,27 @@ func TestParser_Slice(t *testing.T) {
 	}
 }
 
+func TestParser_Text(t *testing.T) {
+	p, _ := parser.New([]byte("abc"))
+	m1 := p.Mark()
+	m2 := p.Next().Mark()
+	m4 := p.Next().Next().Mark()
+
+	for _, test := range []struct {
+		start, end *parser.Cursor
+		text       string
+	}{
+		{m1, m2, "a"},
+		{m1, m4, "abc"},
+		{m2, m2, ""},
+		{m4, m1, ""},
+	} {
+		if s := p.Text(test.start, test.end); s != test.text {
+			t.Errorf("expected %q, got %q", test.text, s)
+		}
+	}
+}
+
 func Example_line_returns() {
 	// Unix, Unix, Windows, Mac, Windows, Mac
 	p, _ := parser.New([]byte("\n\n\r\n\r\r\n\r"))
@@ -262,3 +284,215 @@ func TestParser_Expect_class_err(t *testing.T) {
 		t.Error(expected.String)
 	}
 }
+
+func ExampleNewString() {
+	p, _ := parser.NewString("key=value")
+
+	start := p.Mark()
+	end, _ := p.Expect(op.MinOne(parser.CheckRuneRange('a', 'z')))
+	fmt.Println(p.Slice(start, end))
+	// Output:
+	// key
+}
+
+func TestNewString_slice(t *testing.T) {
+	p, _ := parser.NewString("abc")
+	start := p.Mark()
+	end, _ := p.Expect("abc")
+
+	if allocs := testing.AllocsPerRun(100, func() {
+		_ = p.Slice(start, end)
+	}); allocs != 0 {
+		t.Errorf("expected no allocations, got %v", allocs)
+	}
+}
+
+func ExampleParser_Reset() {
+	p, _ := parser.New([]byte("abc"))
+	fmt.Println(p.Expect("abc"))
+
+	_ = p.Reset([]byte("def"))
+	fmt.Println(p.Expect("abc"))
+	fmt.Println(p.Expect("def"))
+	// Output:
+	// U+0063: c <nil>
+	// <nil> parse conflict [00:000]: expected string "abc" but got 'd'
+	// U+0066: f <nil>
+}
+
+func TestParser_Reset(t *testing.T) {
+	p, _ := parser.New([]byte("abc"))
+	input := []byte("abc")
+	if allocs := testing.AllocsPerRun(100, func() {
+		_ = p.Reset(input)
+	}); allocs != 0 {
+		t.Errorf("expected no allocations, got %v", allocs)
+	}
+
+	if err := p.Reset(nil); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func ExampleParser_PeekRune() {
+	p, _ := parser.New([]byte("①23"))
+
+	fmt.Printf("%c\n", p.PeekRune(0))
+	fmt.Printf("%c\n", p.PeekRune(2))
+	fmt.Println(p.PeekRune(3) == parser.EOD)
+	fmt.Println(p.PeekSlice(2))
+	fmt.Println(p.PeekSlice(5))
+	// Output:
+	// ①
+	// 3
+	// true
+	// ①2
+	// ①23
+}
+
+func ExampleParser_LookBackRune() {
+	p, _ := parser.New([]byte("①23"))
+	p.Next().Next() // Point at '3'.
+
+	fmt.Printf("%c\n", p.LookBackRune(2))
+	fmt.Println(p.LookBackRune(3) == parser.EOD)
+	fmt.Println(p.LookBackSlice(1))
+	fmt.Println(p.LookBackSlice(5))
+	// Output:
+	// ①
+	// true
+	// 2
+	// ①2
+}
+
+func ExampleCursor_Offset() {
+	p, _ := parser.New([]byte("①2\n3"))
+	p.Next() // Point at '2'.
+
+	mark := p.Mark()
+	fmt.Println(mark.Offset())
+	fmt.Println(mark.Position())
+	fmt.Println(p.SliceBytes(p.LookBack(), mark))
+	// Output:
+	// 3
+	// 0 3
+	// [226 145 160 50]
+}
+
+func TestParser_LookBack_position(t *testing.T) {
+	input := "a\tb\ncd\r\n\tef\rg①\th"
+	p, _ := parser.New([]byte(input))
+
+	var marks []*parser.Cursor
+	for !p.Done() {
+		marks = append(marks, p.Mark())
+		p.Next()
+	}
+	for i := len(marks) - 1; 0 < i; i-- {
+		p.Jump(marks[i])
+		back := p.LookBack()
+		row, column := back.Position()
+		r, c := marks[i-1].Position()
+		if row != r || column != c || back.Offset() != marks[i-1].Offset() ||
+			back.VisualColumn() != marks[i-1].VisualColumn() {
+			t.Errorf("%d: expected %d:%d, got %d:%d", i, r, c, row, column)
+		}
+		if i == 1 {
+			continue
+		}
+		// Cursors returned by LookBack do not know their previous rune.
+		p.Jump(back)
+		back = p.LookBack()
+		row, column = back.Position()
+		r, c = marks[i-2].Position()
+		if row != r || column != c || back.VisualColumn() != marks[i-2].VisualColumn() {
+			t.Errorf("%d: expected %d:%d, got %d:%d", i-1, r, c, row, column)
+		}
+	}
+}
+
+func ExampleParser_Remaining() {
+	p, _ := parser.New([]byte("key = value"))
+	_, _ = p.Expect("key")
+
+	fmt.Println(p.Offset())
+	fmt.Printf("%q\n", p.Consumed())
+	fmt.Printf("%q\n", p.Remaining())
+	// Output:
+	// 3
+	// "key"
+	// " = value"
+}
+
+func ExampleParser_Expect_sequence() {
+	p, _ := parser.New([]byte("foo1 foo"))
+	digit := parser.CheckRuneRange('0', '9')
+
+	fmt.Println(p.Expect('f', 'o', 'o', digit))
+	fmt.Println(p.Expect(' ', "foo", digit))
+	fmt.Println(p.Check(' ', "foo"))
+	// Output:
+	// U+0031: 1 <nil>
+	// <nil> parse conflict [00:008]: expected op.And ' ' "foo" func but got " foo"
+	// U+006F: o true
+}
+
+func TestParser_MarkV(t *testing.T) {
+	p, _ := parser.New([]byte("abc"))
+	allocs := testing.AllocsPerRun(100, func() {
+		mark := p.MarkV()
+		p.Next()
+		_ = p.PeekRune(1)
+		p.Jump(&mark)
+	})
+	if allocs != 0 {
+		t.Errorf("expected no allocations, got %v", allocs)
+	}
+
+	if mark, ok := p.CheckV("ab"); !ok || mark.Rune != 'b' {
+		t.Error(mark, ok)
+	}
+	if mark, ok := p.CheckV(op.Optional('x')); !ok || mark != (parser.Cursor{}) {
+		t.Error(mark, ok)
+	}
+}
+
+func TestNew_allocations(t *testing.T) {
+	// The input is decoded on demand, the size of the input does not matter.
+	input := []byte(strings.Repeat("a①", 1<<16))
+	allocs := testing.AllocsPerRun(10, func() {
+		_, _ = parser.New(input)
+	})
+	if 2 < allocs {
+		t.Errorf("expected at most 2 allocations, got %v", allocs)
+	}
+}
+
+func BenchmarkParser_Expect_string(b *testing.B) {
+	input := []byte(strings.Repeat("function ", 64))
+	p, _ := parser.New(input)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = p.Reset(input)
+		for !p.Done() {
+			if _, err := p.Expect("function "); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkParser_Expect_or(b *testing.B) {
+	input := []byte(strings.Repeat("while ", 64))
+	keywords := op.Or{"if", "else", "for", "func", "return", "var", "const", "type", "struct", "while"}
+	p, _ := parser.New(input)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = p.Reset(input)
+		for !p.Done() {
+			if _, err := p.Expect(op.And{keywords, ' '}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Pretty writes the error followed by an excerpt of the line that contains the
+// conflict, with a caret under the conflicting column. e.g.
+//
+//	main.go:2:9: expected ';' but got '2'
+//	 2 | let x = 4 2
+//	   |           ^
+//
+// The excerpt is omitted for errors of streaming parsers, the line might not
+// be inside the window anymore.
+func (e *ExpectedParseError) Pretty(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, e.Error()); err != nil || e.input == nil {
+		return err
+	}
+
+	position := e.Conflict.position
+	if len(e.input) < position {
+		position = len(e.input)
+	}
+	start := bytes.LastIndexAny(e.input[:position], "\r\n") + 1
+	end := bytes.IndexAny(e.input[start:], "\r\n")
+	if end == -1 {
+		end = len(e.input)
+	} else {
+		end += start
+	}
+	line := string(e.input[start:end])
+
+	// Keep the tabs so the caret lines up with the excerpt.
+	var caret strings.Builder
+	for i, r := range line {
+		if i == e.Conflict.column {
+			break
+		}
+		if r == '\t' {
+			caret.WriteRune('\t')
+		} else {
+			caret.WriteRune(' ')
+		}
+	}
+
+	number := fmt.Sprint(e.Conflict.row + 1)
+	margin := strings.Repeat(" ", len(number))
+	_, err := fmt.Fprintf(w, " %s | %s\n %s | %s^\n", number, line, margin, caret.String())
+	return err
+}
@@ -0,0 +1,46 @@
+package parser_test
+
+import (
+	"github.com/di-wu/parser"
+	"os"
+	"strings"
+	"testing"
+)
+
+func ExampleExpectedParseError_Pretty() {
+	p, _ := parser.New([]byte("let x = 4\nlet y = 4 2;\n"), parser.WithFilename("main.txt"))
+	_, err := p.Expect("let x = 4\n", "let y = 4", ';')
+	_ = err.(*parser.ExpectedParseError).Pretty(os.Stdout)
+	// Output:
+	// main.txt:2:10: expected op.And "let x = 4\n" "let y = 4" ';' but got "let x = 4\nlet y = 4 "
+	//  2 | let y = 4 2;
+	//    |          ^
+}
+
+func TestExpectedParseError_Pretty(t *testing.T) {
+	for _, test := range []struct {
+		input  string
+		expect interface{}
+		lines  []string
+	}{
+		{"\tab", []interface{}{'\t', 'b'}, []string{" 1 | \tab", "   | \t^"}},
+		{"a\r\nb", []interface{}{'a', '\r', '\n', 'c'}, []string{" 2 | b", "   | ^"}},
+		{"ab", []interface{}{'a', 'b', 'c'}, []string{" 1 | ab", "   |   ^"}},
+	} {
+		p, _ := parser.New([]byte(test.input))
+		_, err := p.Expect(test.expect)
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+
+		var b strings.Builder
+		if err := err.(*parser.ExpectedParseError).Pretty(&b); err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+		lines = lines[len(lines)-2:]
+		if strings.Join(lines, "\n") != strings.Join(test.lines, "\n") {
+			t.Errorf("%q: got %q", test.input, lines)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// Profiler records statistics of every named value (e.g. a function) that is
+// expected by a parser. See WithProfiler.
+type Profiler struct {
+	rules map[string]*RuleProfile
+	// active is the number of unfinished calls per rule.
+	active map[string]int
+	// starts contains the start times of the unfinished calls.
+	starts []time.Time
+}
+
+// RuleProfile contains the statistics of a single named value.
+type RuleProfile struct {
+	Name string
+	// Calls is the number of times the value was expected.
+	Calls int
+	// Failures is the number of times the value did not match.
+	Failures int
+	// Duration is the cumulative time spent expecting the value, including
+	// nested values. Recursive calls are only counted once.
+	Duration time.Duration
+}
+
+// NewProfiler creates a new Profiler.
+func NewProfiler() *Profiler {
+	return &Profiler{
+		rules:  make(map[string]*RuleProfile),
+		active: make(map[string]int),
+	}
+}
+
+// WithProfiler makes the parser record statistics in the given profiler. The
+// same profiler can be used by multiple (sequential) parsers.
+func WithProfiler(profiler *Profiler) Option {
+	return func(p *Parser) {
+		p.profiler = profiler
+	}
+}
+
+func (pr *Profiler) enter(i interface{}) {
+	n := name(i)
+	if n == "" {
+		return
+	}
+	pr.active[n]++
+	pr.starts = append(pr.starts, time.Now())
+}
+
+func (pr *Profiler) leave(i interface{}, err error) {
+	n := name(i)
+	if n == "" {
+		return
+	}
+	start := pr.starts[len(pr.starts)-1]
+	pr.starts = pr.starts[:len(pr.starts)-1]
+
+	rule, ok := pr.rules[n]
+	if !ok {
+		rule = &RuleProfile{Name: n}
+		pr.rules[n] = rule
+	}
+	rule.Calls++
+	if err != nil {
+		rule.Failures++
+	}
+	if pr.active[n]--; pr.active[n] == 0 {
+		// Outermost call of the rule.
+		rule.Duration += time.Since(start)
+	}
+}
+
+// Report returns the statistics of all the named values, sorted by their
+// cumulative duration (longest first).
+func (pr *Profiler) Report() []RuleProfile {
+	report := make([]RuleProfile, 0, len(pr.rules))
+	for _, rule := range pr.rules {
+		report = append(report, *rule)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Duration != report[j].Duration {
+			return report[i].Duration > report[j].Duration
+		}
+		return report[i].Name < report[j].Name
+	})
+	return report
+}
+
+// WriteTo writes the report as a table to the given writer.
+func (pr *Profiler) WriteTo(w io.Writer) (int64, error) {
+	cw := &countWriter{writer: w}
+	tw := tabwriter.NewWriter(cw, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "RULE\tCALLS\tFAILURES\tDURATION")
+	for _, rule := range pr.Report() {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\n", rule.Name, rule.Calls, rule.Failures, rule.Duration)
+	}
+	err := tw.Flush()
+	return cw.n, err
+}
+
+// countWriter counts the number of bytes written to the writer.
+type countWriter struct {
+	writer io.Writer
+	n      int64
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,43 @@
+package parser_test
+
+import (
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+	"strings"
+	"testing"
+)
+
+func TestWithProfiler(t *testing.T) {
+	profiler := parser.NewProfiler()
+	p, _ := parser.New([]byte("1+2+x"), parser.WithProfiler(profiler))
+	_, _ = p.Expect(op.MinZero(op.Or{sum, digit}))
+
+	report := profiler.Report()
+	if len(report) != 2 {
+		t.Fatal(report)
+	}
+	for _, rule := range report {
+		var calls, failures int
+		switch rule.Name {
+		case "parser_test.sum":
+			calls, failures = 2, 1
+		case "parser_test.digit":
+			// 3 within sum, 1 on its own.
+			calls, failures = 4, 2
+		default:
+			t.Fatal(rule.Name)
+		}
+		if rule.Calls != calls || rule.Failures != failures {
+			t.Errorf("%s: %d calls, %d failures", rule.Name, rule.Calls, rule.Failures)
+		}
+	}
+
+	var b strings.Builder
+	n, err := profiler.WriteTo(&b)
+	if err != nil || int(n) != b.Len() {
+		t.Fatal(n, err)
+	}
+	if lines := strings.Split(strings.TrimSpace(b.String()), "\n"); len(lines) != 3 {
+		t.Error(lines)
+	}
+}
@@ -0,0 +1,26 @@
+package parser
+
+// WithProgressCheck makes repetitions (e.g. op.MinZero) that match without
+// consuming any input stop the parser with a NoProgressError. This is useful
+// to detect nullable expressions within repetitions. By default, these
+// repetitions keep repeating until their maximum is reached, e.g.
+// op.MinZero(op.Optional('a')) never ends.
+func WithProgressCheck() Option {
+	return func(p *Parser) {
+		p.progress = true
+	}
+}
+
+// noProgress should be called by repetitions if an iteration matched without
+// consuming any input. It stops the parser and returns a NoProgressError if
+// the parser checks for progress, otherwise it returns nil.
+func (p *Parser) noProgress(repetition interface{}) error {
+	if !p.progress {
+		return nil
+	}
+	p.fatal = &NoProgressError{
+		Value:  repetition,
+		Cursor: *p.cursor,
+	}
+	return p.fatal
+}
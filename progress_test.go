@@ -0,0 +1,16 @@
+package parser_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+)
+
+func ExampleWithProgressCheck() {
+	nullable := op.MinZero(op.Optional('a'))
+
+	p, _ := parser.New([]byte("aab"), parser.WithProgressCheck())
+	fmt.Println(p.Expect(nullable, 'b'))
+	// Output:
+	// <nil> parser [00:002]: repetition 'a'?* matches without consuming any input
+}
@@ -0,0 +1,150 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Escapes contains the common escape sequences, e.g. 'n' for a newline.
+var Escapes = map[rune]rune{
+	'0':  0,
+	'b':  '\b',
+	'f':  '\f',
+	'n':  '\n',
+	'r':  '\r',
+	't':  '\t',
+	'v':  '\v',
+	'\'': '\'',
+	'"':  '"',
+	'\\': '\\',
+}
+
+// DoubleQuoted matches a double quoted string literal with the common escape
+// sequences, including unicode escapes. e.g. "caf\u00e9\n".
+var DoubleQuoted = Quoted{
+	Open:    '"',
+	Escape:  '\\',
+	Escapes: Escapes,
+	Unicode: true,
+}
+
+// Quoted is a Class that matches a quoted string literal. Its value is the
+// literal without delimiters, of which the escape sequences are decoded.
+type Quoted struct {
+	// Open is the delimiter at the start of the literal, e.g. '"'.
+	Open rune
+	// Close is the delimiter at the end of the literal, defaults to Open.
+	Close rune
+	// Escape is the rune that starts an escape sequence, e.g. '\\'. Literals
+	// have no escape sequences if it is zero.
+	Escape rune
+	// Escapes maps the runes that can follow the escape rune to their value,
+	// e.g. 'n' to '\n'. The escape rune and the closing delimiter can always be
+	// escaped.
+	Escapes map[rune]rune
+	// Unicode allows unicode escape sequences of four (e.g. \u00e9) or eight
+	// (e.g. \U0001F600) hexadecimal digits.
+	Unicode bool
+	// Newlines allows the literal to contain newlines.
+	Newlines bool
+}
+
+func (q Quoted) Check(p *Parser) (*Cursor, bool) {
+	_, last, ok := q.Value(p)
+	return last, ok
+}
+
+// Value works the same as Check, but also returns the decoded value of the
+// literal. It can be used as a TypedClass.
+func (q Quoted) Value(p *Parser) (string, *Cursor, bool) {
+	if p.Current() != q.Open {
+		return "", nil, false
+	}
+	closing := q.Close
+	if closing == 0 {
+		closing = q.Open
+	}
+	last := p.Mark()
+	p.Next()
+
+	var value strings.Builder
+	for !p.Done() {
+		switch r := p.Current(); {
+		case r == closing:
+			return value.String(), p.Mark(), true
+		case (r == '\n' || r == '\r') && !q.Newlines:
+			return "", last, false
+		case r == q.Escape && r != 0:
+			p.Next()
+			v, end, ok := q.escape(p, closing)
+			if !ok {
+				return "", last, false
+			}
+			value.WriteRune(v)
+			last = end
+		default:
+			value.WriteRune(r)
+			last = p.Mark()
+			p.Next()
+		}
+	}
+	return "", last, false
+}
+
+// escape decodes the escape sequence after the escape rune. Returns a mark to
+// the last rune of the sequence.
+func (q Quoted) escape(p *Parser, closing rune) (rune, *Cursor, bool) {
+	r := p.Current()
+	if q.Unicode && (r == 'u' || r == 'U') {
+		n := 4
+		if r == 'U' {
+			n = 8
+		}
+		var digits []rune
+		var last *Cursor
+		for i := 0; i < n; i++ {
+			p.Next()
+			if digit(p.Current(), 16) == -1 {
+				return 0, nil, false
+			}
+			digits = append(digits, p.Current())
+			last = p.Mark()
+		}
+		v, _ := strconv.ParseUint(string(digits), 16, 32)
+		if !utf8.ValidRune(rune(v)) {
+			return 0, nil, false
+		}
+		p.Next()
+		return rune(v), last, true
+	}
+
+	v, ok := q.Escapes[r]
+	if r == q.Escape || r == closing {
+		v, ok = r, true
+	}
+	if !ok {
+		return 0, nil, false
+	}
+	last := p.Mark()
+	p.Next()
+	return v, last, true
+}
+
+// Unquote decodes the given literal, which should be matched by the class as a
+// whole.
+func (q Quoted) Unquote(s string) (string, error) {
+	p, err := NewString(s)
+	if err != nil {
+		return "", err
+	}
+	value, last, ok := q.Value(p)
+	if ok {
+		p.Jump(last).Next()
+	}
+	if !ok || !p.Done() {
+		return "", fmt.Errorf("invalid literal %q", s)
+	}
+	return value, nil
+}
@@ -0,0 +1,56 @@
+package parser_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"testing"
+)
+
+func ExampleQuoted() {
+	p, _ := parser.New([]byte(`"caf\u00e9\t\"ok\""`))
+	fmt.Println(p.Expect(parser.DoubleQuoted))
+
+	// Decode the value while matching.
+	p, _ = parser.New([]byte(`"caf\u00e9\t\"ok\""`))
+	value, _, _ := parser.DoubleQuoted.Value(p)
+	fmt.Printf("%q\n", value)
+
+	raw := parser.Quoted{Open: '\'', Newlines: true}
+	fmt.Println(raw.Unquote("'no \\escapes\n'"))
+	// Output:
+	// U+0022: " <nil>
+	// "café\t\"ok\""
+	// no \escapes
+	//  <nil>
+}
+
+func TestQuoted_Unquote(t *testing.T) {
+	brackets := parser.Quoted{Open: '[', Close: ']', Escape: '\\'}
+	for _, test := range []struct {
+		quoted parser.Quoted
+		input  string
+		value  string
+		err    bool
+	}{
+		{quoted: parser.DoubleQuoted, input: `""`, value: ""},
+		{quoted: parser.DoubleQuoted, input: `"a\nb"`, value: "a\nb"},
+		{quoted: parser.DoubleQuoted, input: `"\U0001F600"`, value: "\U0001F600"},
+		{quoted: parser.DoubleQuoted, input: `"\u00e"`, err: true},
+		{quoted: parser.DoubleQuoted, input: `"\UFFFFFFFF"`, err: true},
+		{quoted: parser.DoubleQuoted, input: `"\q"`, err: true},
+		{quoted: parser.DoubleQuoted, input: "\"a\nb\"", err: true},
+		{quoted: parser.DoubleQuoted, input: `"abc`, err: true},
+		{quoted: parser.DoubleQuoted, input: `"a"b`, err: true},
+		{quoted: brackets, input: `[a\]b]`, value: "a]b"},
+		{quoted: brackets, input: `[a\\]`, value: `a\`},
+		{quoted: brackets, input: `[\n]`, err: true},
+	} {
+		value, err := test.quoted.Unquote(test.input)
+		if (err != nil) != test.err {
+			t.Errorf("%q: unexpected error: %v", test.input, err)
+		}
+		if value != test.value {
+			t.Errorf("%q: expected %q, got %q", test.input, test.value, value)
+		}
+	}
+}
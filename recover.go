@@ -0,0 +1,62 @@
+package parser
+
+// WithRecovery enables error recovery, see op.Recover. Instead of stopping at
+// the first error, the recovered errors are collected and can be retrieved with
+// Parser.Errors. Errors recovered within values that get backtracked are
+// discarded.
+func WithRecovery() Option {
+	return func(p *Parser) {
+		p.recovery = new(recovery)
+		p.Attach(p.recovery)
+	}
+}
+
+// recovery contains the errors that were recovered from.
+type recovery struct {
+	errors []error
+}
+
+func (r *recovery) Snapshot() interface{} {
+	return len(r.errors)
+}
+
+func (r *recovery) Restore(snapshot interface{}) {
+	r.errors = r.errors[:snapshot.(int)]
+}
+
+// Errors returns all the errors that were recovered from.
+func (p *Parser) Errors() []error {
+	if p.recovery == nil {
+		return nil
+	}
+	return p.recovery.errors
+}
+
+// recover records the given error and skips everything up to and including the
+// given sync value, or until the end of the data. The parser should be at the
+// position where the failed value started. Returns a mark to the last skipped
+// rune, if any. Returns false if error recovery is not enabled, the error stops
+// the parser (except for a CutError) or if there is nothing left to skip.
+func (p *Parser) recover(err error, sync interface{}) (*Cursor, bool) {
+	if _, ok := p.fatal.(*CutError); ok && p.recovery != nil && !p.Done() {
+		// An op.Error can be recovered from.
+		p.fatal = nil
+	}
+	if p.recovery == nil || p.fatal != nil || p.Done() {
+		return nil, false
+	}
+	p.recovery.errors = append(p.recovery.errors, err)
+
+	var last *Cursor
+	for !p.Done() {
+		if mark, ok := p.Check(sync); ok {
+			if mark != nil {
+				last = mark
+			}
+			break
+		}
+		last = p.Mark()
+		p.Next()
+	}
+	return last, true
+}
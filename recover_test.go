@@ -0,0 +1,83 @@
+package parser_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+	"testing"
+)
+
+func ExampleWithRecovery() {
+	p, _ := parser.New([]byte("x=1;y=;z=3;=4;"), parser.WithRecovery())
+	letter := parser.CheckRuneRange('a', 'z')
+	digit := parser.CheckRuneRange('0', '9')
+	statement := op.Recover{
+		Value: op.And{letter, '=', digit, ';'},
+		Sync:  ';',
+	}
+
+	fmt.Println(p.Expect(op.MinZero(statement)))
+	for _, err := range p.Errors() {
+		fmt.Println(err)
+	}
+	// Output:
+	// U+003B: ; <nil>
+	// parse conflict [00:007]: expected parser.AnonymousClass func but got ";z"
+	// parse conflict [00:012]: expected op.And func '=' func ';' but got "=4"
+}
+
+func TestParser_Recover(t *testing.T) {
+	statement := op.Recover{Value: op.And{'a', ';'}, Sync: ';'}
+
+	// Without recovery, the error is returned.
+	p, _ := parser.New([]byte("b;"))
+	if _, err := p.Expect(statement); err == nil {
+		t.Error("expected an error")
+	}
+
+	// Errors within values that get backtracked are discarded.
+	p, _ = parser.New([]byte("b;c"), parser.WithRecovery())
+	if _, err := p.Expect(op.Or{op.And{statement, 'x'}, 'b'}); err != nil {
+		t.Fatal(err)
+	}
+	if errs := p.Errors(); len(errs) != 0 {
+		t.Error(errs)
+	}
+
+	// Skips until the end if there is no sync value.
+	_ = p.Reset([]byte("bbb"))
+	if _, err := p.Expect(statement); err != nil {
+		t.Fatal(err)
+	}
+	if !p.Done() || len(p.Errors()) != 1 {
+		t.Error(p.Current(), p.Errors())
+	}
+}
+
+func TestParser_Recover_cut(t *testing.T) {
+	digit := parser.CheckRuneRange('0', '9')
+	statement := op.Recover{
+		Value: op.Or{op.And{'x', op.Cut{}, '=', digit, ';'}, op.And{'x', ';'}},
+		Sync:  ';',
+	}
+
+	// The cut prevents the second alternative, the error is recovered from.
+	p, _ := parser.New([]byte("x=a;x=1;"), parser.WithRecovery())
+	if _, err := p.Expect(op.MinZero(statement)); err != nil {
+		t.Fatal(err)
+	}
+	if !p.Done() || len(p.Errors()) != 1 {
+		t.Fatal(p.Current(), p.Errors())
+	}
+	var conflict *parser.ExpectedParseError
+	if !errors.As(p.Errors()[0], &conflict) || conflict.Conflict.Offset() != 3 {
+		t.Error(p.Errors()[0])
+	}
+
+	// Without recovery, the error points at the conflict.
+	p, _ = parser.New([]byte("x=1;x=a;"))
+	if _, err := p.Expect(statement, statement); !errors.As(err, &conflict) || conflict.Conflict.Offset() != 7 {
+		t.Error(err)
+	}
+}
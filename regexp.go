@@ -0,0 +1,195 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"regexp/syntax"
+	"unicode/utf8"
+
+	"github.com/di-wu/parser/op"
+)
+
+// CheckRegexp returns an AnonymousClass that checks whether the following runes
+// match the given regular expression. The expression is anchored at the
+// current position of the parser. It panics if the expression can not be
+// parsed.
+//
+// The expression can read ahead up to the end of the input to find the longest
+// match. On streaming parsers (see NewReader), this is only supported if that
+// fits into the window. Otherwise the start of the match is not inside the
+// window anymore, the value fails and Err returns an InvalidMarkError.
+//
+// e.g. CheckRegexp(`[a-z][a-z0-9_]*`) matches identifiers.
+func CheckRegexp(expr string) AnonymousClass {
+	re := regexp.MustCompile(`\A(?:` + expr + `)`)
+	return func(p *Parser) (*Cursor, bool) {
+		start := p.Mark()
+		loc := re.FindReaderIndex(&runeReader{p: p})
+		p.Jump(start)
+		if _, ok := p.Err().(*InvalidMarkError); ok || loc == nil {
+			// No match, or the expression read beyond the window of the stream.
+			return nil, false
+		}
+
+		var last *Cursor
+		for end := start.position + loc[1]; p.cursor.position < end; p.Next() {
+			last = p.Mark()
+		}
+		return last, true
+	}
+}
+
+// runeReader reads runes from a parser, it advances the parser.
+type runeReader struct {
+	p *Parser
+}
+
+func (r *runeReader) ReadRune() (rune, int, error) {
+	if r.p.Done() {
+		return 0, 0, io.EOF
+	}
+	current, position := r.p.cursor.Rune, r.p.cursor.position
+	r.p.Next()
+	// Use the difference in position as size, this includes skipped bytes.
+	return current, r.p.cursor.position - position, nil
+}
+
+// FromRegexp translates the given regular expression into a value of op values
+// and classes, e.g. `[a-z]+(-[a-z]+)*` results in an op.And of an op.MinOne and
+// an op.MinZero. Unlike CheckRegexp, the result can be mixed with other values,
+// e.g. rules of a grammar or (ast) captures within groups.
+//
+// Character classes, alternations, greedy repetitions and anchors are
+// supported. Named groups result in an op.Capture, so they can be referenced by
+// an op.Backref. The result is matched as any other value: alternatives are
+// ordered and repetitions do not backtrack, e.g. `a*a` never matches.
+func FromRegexp(expr string) (interface{}, error) {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+	return fromRegexp(re)
+}
+
+// MustFromRegexp works the same as FromRegexp, but panics if the expression can
+// not be parsed or is not supported.
+func MustFromRegexp(expr string) interface{} {
+	i, err := FromRegexp(expr)
+	if err != nil {
+		panic(err)
+	}
+	return i
+}
+
+// fromRegexp translates the given (parsed) regular expression.
+func fromRegexp(re *syntax.Regexp) (interface{}, error) {
+	subs := func() ([]interface{}, error) {
+		values := make([]interface{}, len(re.Sub))
+		for i, sub := range re.Sub {
+			value, err := fromRegexp(sub)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return values, nil
+	}
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return op.Fail{Message: "no match"}, nil
+	case syntax.OpEmptyMatch:
+		return op.And{}, nil
+	case syntax.OpLiteral:
+		var value interface{} = string(re.Rune)
+		if len(re.Rune) == 1 {
+			value = re.Rune[0]
+		}
+		if re.Flags&syntax.FoldCase != 0 {
+			return op.CaseInsensitive{Value: value}, nil
+		}
+		return value, nil
+	case syntax.OpCharClass:
+		return runeClass(re.Rune), nil
+	case syntax.OpAnyCharNotNL:
+		return CheckNotRune('\n'), nil
+	case syntax.OpAnyChar:
+		return CheckRuneFunc(func(r rune) bool {
+			return r != EOD
+		}), nil
+	case syntax.OpBeginLine:
+		return op.BOL, nil
+	case syntax.OpEndLine:
+		return op.EOL, nil
+	case syntax.OpBeginText:
+		return op.SOI, nil
+	case syntax.OpEndText:
+		return op.EOI, nil
+	case syntax.OpWordBoundary:
+		return op.WordBoundary, nil
+	case syntax.OpNoWordBoundary:
+		return op.Not{Value: op.WordBoundary}, nil
+	case syntax.OpCapture:
+		value, err := fromRegexp(re.Sub[0])
+		if err != nil || re.Name == "" {
+			return value, err
+		}
+		return op.Capture{Name: re.Name, Value: value}, nil
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		if re.Flags&syntax.NonGreedy != 0 {
+			return nil, fmt.Errorf("unsupported non-greedy repetition %s", re)
+		}
+		value, err := fromRegexp(re.Sub[0])
+		if err != nil {
+			return nil, err
+		}
+		switch re.Op {
+		case syntax.OpStar:
+			return op.MinZero(value), nil
+		case syntax.OpPlus:
+			return op.MinOne(value), nil
+		case syntax.OpQuest:
+			return op.Optional(value), nil
+		}
+		switch re.Max {
+		case 0:
+			// Zero repetitions (e.g. a{0}) match the empty string.
+			return op.And{}, nil
+		case -1:
+			return op.Min(re.Min, value), nil
+		}
+		return op.MinMax(re.Min, re.Max, value), nil
+	case syntax.OpConcat:
+		values, err := subs()
+		return op.And(values), err
+	case syntax.OpAlternate:
+		values, err := subs()
+		return op.Or(values), err
+	default:
+		return nil, fmt.Errorf("unsupported regular expression %s", re)
+	}
+}
+
+// runeClass returns a value that matches a rune in one of the given ranges,
+// which are pairs of (inclusive) bounds.
+func runeClass(ranges []rune) interface{} {
+	switch {
+	case len(ranges) == 2 && ranges[0] == ranges[1]:
+		return ranges[0]
+	case len(ranges) == 2:
+		return CheckRuneRange(ranges[0], ranges[1])
+	case len(ranges) == 4 && ranges[0] == 0 && ranges[3] == utf8.MaxRune:
+		// A negated rune, e.g. [^a].
+		if ranges[1]+2 == ranges[2] {
+			return CheckNotRune(ranges[1] + 1)
+		}
+	}
+	return CheckRuneFunc(func(r rune) bool {
+		for i := 0; i < len(ranges); i += 2 {
+			if ranges[i] <= r && r <= ranges[i+1] {
+				return true
+			}
+		}
+		return false
+	})
+}
@@ -0,0 +1,110 @@
+package parser_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+	"strings"
+	"testing"
+)
+
+func ExampleCheckRegexp() {
+	p, _ := parser.New([]byte("foo_1 = 0x1F"))
+	identifier := parser.CheckRegexp(`[a-z][a-z0-9_]*`)
+	hex := parser.CheckRegexp(`0[xX][0-9a-fA-F]+`)
+
+	fmt.Println(p.Expect(identifier))
+	fmt.Println(p.Expect(" = "))
+	fmt.Println(p.Expect(op.Or{identifier, hex}))
+	fmt.Println(p.Done())
+	// Output:
+	// U+0031: 1 <nil>
+	// U+0020:   <nil>
+	// U+0046: F <nil>
+	// true
+}
+
+func ExampleFromRegexp() {
+	word := parser.MustFromRegexp(`[a-z]+(-[a-z]+)*`)
+	p, _ := parser.New([]byte("well-known = 42"))
+	fmt.Println(p.Expect(op.And{word, " = ", parser.MustFromRegexp(`\d+$`)}))
+	fmt.Println(p.Done())
+	// Output:
+	// U+0032: 2 <nil>
+	// true
+}
+
+func TestFromRegexp(t *testing.T) {
+	for _, test := range []struct {
+		expr  string
+		input string
+		ok    bool
+	}{
+		{expr: `abc`, input: "abc", ok: true},
+		{expr: `abc`, input: "abd"},
+		{expr: `(?i)abc`, input: "aBC", ok: true},
+		{expr: `a|bc|d`, input: "bc", ok: true},
+		{expr: `[a-c]+[^a-c]`, input: "abcd", ok: true},
+		{expr: `[a-c]+[^a-c]`, input: "abc"},
+		{expr: `[^\n]*\n`, input: "abc\n", ok: true},
+		{expr: `.+`, input: "a\nb"},
+		{expr: `(?s).+`, input: "a\nb", ok: true},
+		{expr: `\w{2,3}`, input: "abc", ok: true},
+		{expr: `\w{2,3}`, input: "abcd"},
+		{expr: `\w{2,}`, input: "abcd", ok: true},
+		{expr: `\w{2}`, input: "a"},
+		{expr: `xa{0}`, input: "x", ok: true},
+		{expr: `xa{0}`, input: "xaaa"},
+		{expr: `xa{0,0}`, input: "xa"},
+		{expr: `colou?r`, input: "color", ok: true},
+		{expr: `^a\b`, input: "a", ok: true},
+		{expr: `a\B`, input: "a"},
+		{expr: `(?m)a$\n^b`, input: "a\nb", ok: true},
+		// Repetitions do not backtrack.
+		{expr: `a*a`, input: "aaa"},
+	} {
+		value, err := parser.FromRegexp(test.expr)
+		if err != nil {
+			t.Errorf("%s: %v", test.expr, err)
+			continue
+		}
+		p, _ := parser.New([]byte(test.input))
+		_, err = p.Expect(value)
+		if ok := err == nil && p.Done(); ok != test.ok {
+			t.Errorf("%s on %q: expected %v, got %v", test.expr, test.input, test.ok, err)
+		}
+	}
+}
+
+func TestFromRegexp_captures(t *testing.T) {
+	quote := parser.MustFromRegexp(`(?P<quote>['"])`)
+	p, _ := parser.New([]byte(`"abc"`))
+	if _, err := p.Expect(op.And{quote, op.MinZero(parser.CheckRuneRange('a', 'z')), op.Backref{Name: "quote"}}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFromRegexp_unsupported(t *testing.T) {
+	for _, expr := range []string{`a*?`, `(`} {
+		if _, err := parser.FromRegexp(expr); err == nil {
+			t.Errorf("%s: expected an error", expr)
+		}
+	}
+}
+
+func TestCheckRegexp_stream(t *testing.T) {
+	// The expression reads ahead beyond the window of the stream.
+	p, _ := parser.NewReader(strings.NewReader(strings.Repeat("a", 64)+"b"), 8)
+	if _, err := p.Expect(parser.CheckRegexp(`a+b`)); err == nil {
+		t.Error("expected an error")
+	}
+	if _, ok := p.Err().(*parser.InvalidMarkError); !ok {
+		t.Errorf("expected an invalid mark, got %v", p.Err())
+	}
+
+	// Within the window, the stream is supported.
+	p, _ = parser.NewReader(strings.NewReader("ab"), 8)
+	if _, err := p.Expect(parser.CheckRegexp(`a+b`)); err != nil || !p.Done() {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"bytes"
+	"github.com/di-wu/parser/op"
+	"unicode/utf8"
+)
+
+// SkipUntil consumes all runes until the given value matches and returns the
+// number of consumed runes. The matching value itself is not consumed. If the
+// value never matches, all remaining runes are consumed.
+//
+// Strings and tries of strings (op.AnyString) are found by scanning the bytes of
+// the input, instead of checking the value at every position. This makes
+// skipping large blocks of text (e.g. comments) a lot faster.
+func (p *Parser) SkipUntil(i interface{}) int {
+	return p.skipUntil(i, nil)
+}
+
+// skipUntil works the same as SkipUntil, but also sets last to the last
+// consumed rune if it is not nil.
+func (p *Parser) skipUntil(i interface{}, last *Cursor) int {
+	var delimiters []string
+	switch v := i.(type) {
+	case string:
+		delimiters = []string{v}
+	case *op.Trie:
+		delimiters = v.Strings()
+	}
+	if delimiters != nil && p.raw() {
+		return p.advance(p.indexAny(delimiters), last)
+	}
+
+	var count int
+	for !p.Done() {
+		mark := p.MarkV()
+		if _, ok := p.Check(i); ok {
+			p.Jump(&mark)
+			break
+		}
+		p.consume(last)
+		count++
+	}
+	return count
+}
+
+// indexAny returns the position of the first occurrence of one of the given
+// (non-empty) strings, starting from the current position. Returns the length
+// of the input if none of them occur.
+func (p *Parser) indexAny(delimiters []string) int {
+	rest := p.buffer[p.cursor.position:]
+	index := len(rest)
+	for _, delimiter := range delimiters {
+		if delimiter == "" {
+			continue
+		}
+		// Only search up to the closest occurrence so far.
+		limit := index + len(delimiter)
+		if len(rest) < limit {
+			limit = len(rest)
+		}
+		if i := bytes.Index(rest[:limit], []byte(delimiter)); i != -1 {
+			index = i
+		}
+	}
+	return p.cursor.position + index
+}
+
+// advance advances the parser up to the given position and returns the number
+// of consumed runes. Sets last to the last consumed rune if it is not nil.
+func (p *Parser) advance(position int, last *Cursor) int {
+	segment := p.buffer[p.cursor.position:position]
+	if bytes.IndexAny(segment, "\r\t") != -1 {
+		// Carriage returns and tabs need special care, see Next.
+		var count int
+		for !p.Done() && p.cursor.position < position {
+			p.consume(last)
+			count++
+		}
+		return count
+	}
+
+	if len(segment) == 0 {
+		return 0
+	}
+	// The last rune is left to Next, so the previous position is tracked.
+	_, size := utf8.DecodeLastRune(segment)
+	segment = segment[:len(segment)-size]
+	count := utf8.RuneCount(segment)
+	if i := bytes.LastIndexByte(segment, '\n'); i != -1 {
+		p.cursor.row += bytes.Count(segment, []byte{'\n'})
+		p.cursor.column = len(segment[i+1:])
+		p.cursor.visual = utf8.RuneCount(segment[i+1:])
+	} else {
+		p.cursor.column += len(segment)
+		p.cursor.visual += count
+	}
+	p.cursor.position = position - size
+	p.read(p.cursor)
+	p.consume(last)
+	return count + 1
+}
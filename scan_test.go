@@ -0,0 +1,82 @@
+package parser_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+	"strings"
+	"testing"
+)
+
+func ExampleParser_SkipUntil() {
+	p, _ := parser.New([]byte("/* a\n * comment */ x"))
+	_, _ = p.Expect("/*")
+	fmt.Println(p.SkipUntil("*/"))
+	fmt.Println(p.Expect("*/"))
+	fmt.Println(p.Mark().Position())
+	// Output:
+	// 14
+	// U+002F: / <nil>
+	// 1 13
+}
+
+func TestParser_SkipUntil(t *testing.T) {
+	for _, test := range []struct {
+		input string
+		until interface{}
+		count int
+	}{
+		{"abc]]>", "]]>", 3},
+		{"abc", "]]>", 4}, // Including the NUL byte.
+		{"a\nb;c", op.AnyString(";", "\n"), 1},
+		{"ab;c\n", op.AnyString("\n", ";"), 2},
+		{"ab1", parser.CheckRuneRange('0', '9'), 2},
+		{"", ";", 1},
+	} {
+		// Both the fast and the default path.
+		for _, fold := range []bool{false, true} {
+			// Ends with a NUL byte, empty input is not allowed.
+			p, _ := parser.NewString(test.input + "\x00")
+			p.SetCaseInsensitive(fold)
+			if count := p.SkipUntil(test.until); count != test.count {
+				t.Errorf("%q: expected %d, got %d", test.input, test.count, count)
+			}
+		}
+	}
+}
+
+func BenchmarkParser_SkipUntil(b *testing.B) {
+	input := []byte("/*" + strings.Repeat("comment ", 1024) + "*/")
+	p, _ := parser.New(input)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = p.Reset(input)
+		p.SkipUntil("*/")
+	}
+}
+
+func TestParser_SkipUntil_position(t *testing.T) {
+	for _, input := range []string{
+		"a\nbc d\nef\x80g①;",
+		"a\nbc\td\r\nef\x80g①;",
+	} {
+		fast, _ := parser.New([]byte(input))
+		fast.SkipUntil(";")
+		slow, _ := parser.New([]byte(input))
+		slow.SkipUntil(';')
+
+		if fast.Offset() != slow.Offset() || fast.Current() != ';' {
+			t.Fatal(fast.Offset(), slow.Offset())
+		}
+		fastRow, fastColumn := fast.Mark().Position()
+		slowRow, slowColumn := slow.Mark().Position()
+		if fastRow != slowRow || fastColumn != slowColumn || fast.Mark().VisualColumn() != slow.Mark().VisualColumn() {
+			t.Errorf("%q: %d:%d, %d:%d", input, fastRow, fastColumn, slowRow, slowColumn)
+		}
+		fastBack, slowBack := fast.LookBack(), slow.LookBack()
+		if fmt.Sprint(fastBack.Position()) != fmt.Sprint(slowBack.Position()) ||
+			fastBack.VisualColumn() != slowBack.VisualColumn() {
+			t.Errorf("%q: look back %v, %v", input, fastBack, slowBack)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package parser
+
+// RuneClass is a class that matches a single rune out of a set of runes. ASCII
+// runes are stored in a bitmap, other runes in a map. It implements both the
+// Class and RuneSet interface.
+type RuneClass struct {
+	ascii  [2]uint64
+	others map[rune]struct{}
+	// negated indicates that the class matches all runes not in the set.
+	negated bool
+}
+
+// CheckRuneSet returns a RuneClass that checks whether the current rune of the
+// parser is one of the runes in the given string.
+//
+// e.g. CheckRuneSet("+-*/%") matches arithmetic operators.
+func CheckRuneSet(runes string) RuneClass {
+	var c RuneClass
+	for _, r := range runes {
+		if 0 <= r && r < 128 {
+			c.ascii[r/64] |= 1 << (r % 64)
+			continue
+		}
+		if c.others == nil {
+			c.others = make(map[rune]struct{})
+		}
+		c.others[r] = struct{}{}
+	}
+	return c
+}
+
+// CheckNoneOf returns a RuneClass that checks whether the current rune of the
+// parser is NOT one of the runes in the given string. It never matches EOD.
+//
+// e.g. CheckNoneOf("\"\\\n") matches the body of a string literal.
+func CheckNoneOf(runes string) RuneClass {
+	c := CheckRuneSet(runes)
+	c.negated = true
+	return c
+}
+
+// Contains checks whether the given rune is part of the set.
+func (c RuneClass) Contains(r rune) bool {
+	if c.negated {
+		return r != EOD && !c.contains(r)
+	}
+	return c.contains(r)
+}
+
+func (c RuneClass) contains(r rune) bool {
+	if 0 <= r && r < 128 {
+		return c.ascii[r/64]&(1<<(r%64)) != 0
+	}
+	_, ok := c.others[r]
+	return ok
+}
+
+// Check checks whether the current rune of the parser is part of the set.
+func (c RuneClass) Check(p *Parser) (*Cursor, bool) {
+	if !c.Contains(p.Current()) {
+		return nil, false
+	}
+	return p.Mark(), true
+}
@@ -0,0 +1,37 @@
+package parser_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"testing"
+)
+
+func ExampleCheckRuneSet() {
+	p, _ := parser.New([]byte("+*→x"))
+	operator := parser.CheckRuneSet("+-*/%→")
+
+	fmt.Println(p.Expect(operator))
+	fmt.Println(p.SkipWhile(operator))
+	fmt.Println(p.Expect(operator))
+	// Output:
+	// U+002B: + <nil>
+	// 2
+	// <nil> parse conflict [00:006]: expected parser.AnonymousClass parser.Class.Check but got 'x'
+}
+
+func TestRuneClass_Contains(t *testing.T) {
+	set := "\x00az~\x7Fé①"
+	class := parser.CheckRuneSet(set)
+	for r := rune(-1); r < 0x3000; r++ {
+		expected := false
+		for _, v := range set {
+			expected = expected || v == r
+		}
+		if class.Contains(r) != expected {
+			t.Errorf("%U: expected %v", r, expected)
+		}
+	}
+	if class.Contains(parser.EOD) {
+		t.Error("EOD")
+	}
+}
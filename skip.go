@@ -0,0 +1,106 @@
+package parser
+
+// Skip consumes the given value without returning a mark. It supports the same
+// values as Parser.Expect, but avoids allocating cursors for runes and strings
+// if nothing depends on their expectation, see direct.
+func (p *Parser) Skip(i interface{}) error {
+	switch v := ConvertAliases(i).(type) {
+	case rune:
+		if p.direct() {
+			if !p.equal(v) {
+				return p.ExpectedParseError(v, p.Mark(), nil)
+			}
+			p.Next()
+			return nil
+		}
+	case string:
+		if p.direct() && v != "" {
+			start := *p.cursor
+			for _, r := range v {
+				if !p.equal(r) {
+					// Only allocate a mark on failure.
+					mark := start
+					return p.ExpectedParseError(v, &mark, p.Mark())
+				}
+				p.Next()
+			}
+			return nil
+		}
+	}
+	_, err := p.Expect(i)
+	return err
+}
+
+// direct returns whether runes and strings can be matched directly, instead of
+// going through Expect. This is not the case if the parser skips trivia, has a
+// converter, an operator, hooks, a tracer, a profiler, a context or a maximum
+// depth, or if it is stopped.
+func (p *Parser) direct() bool {
+	return p.converter == nil && p.operator == nil && p.trivia == nil &&
+		p.hooks == nil && p.trace == nil && p.profiler == nil &&
+		p.ctx == nil && p.maxDepth < 1 && p.fatal == nil
+}
+
+// RuneSet is a set of runes. Classes that only match a single rune can
+// implement this interface to be skipped without allocating cursors.
+type RuneSet interface {
+	// Contains checks whether the given rune is part of the set.
+	Contains(r rune) bool
+}
+
+// SkipWhile consumes the given value as long as it matches and returns the
+// number of matches. Next to the values supported by Parser.Expect, it also
+// accepts a func(r rune) bool or a RuneSet which get checked for every rune,
+// without any allocations.
+func (p *Parser) SkipWhile(i interface{}) int {
+	return p.skipWhile(i, nil)
+}
+
+// skipWhile works the same as SkipWhile, but also sets last to the last
+// consumed rune if it is not nil.
+func (p *Parser) skipWhile(i interface{}, last *Cursor) int {
+	var count int
+	switch v := i.(type) {
+	case func(r rune) bool:
+		for !p.Done() && v(p.cursor.Rune) {
+			p.consume(last)
+			count++
+		}
+		return count
+	case RuneSet:
+		for !p.Done() && v.Contains(p.cursor.Rune) {
+			p.consume(last)
+			count++
+		}
+		return count
+	}
+
+	var begin Cursor
+	for !p.Done() {
+		mark := p.MarkV()
+		if err := p.Skip(i); err != nil || mark.position == p.cursor.position {
+			// Stop if it does not match or does not consume anything.
+			break
+		}
+		begin = mark
+		count++
+	}
+	if last != nil && count != 0 {
+		// Step through the last match again to find its last rune.
+		end := p.cursor.position
+		*p.cursor = begin
+		for p.cursor.position < end {
+			p.consume(last)
+		}
+	}
+	return count
+}
+
+// consume moves to the next rune and copies the consumed rune into last if it
+// is not nil.
+func (p *Parser) consume(last *Cursor) {
+	if last != nil {
+		*last = *p.cursor
+	}
+	p.Next()
+}
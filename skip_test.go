@@ -0,0 +1,71 @@
+package parser_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+	"testing"
+	"unicode"
+)
+
+func ExampleParser_Skip() {
+	p, _ := parser.New([]byte("// comment\nx"))
+
+	fmt.Println(p.Skip("//"))
+	fmt.Println(p.SkipWhile(op.And{op.Not{Value: '\n'}, parser.CheckRuneFunc(func(r rune) bool {
+		return r != parser.EOD
+	})}))
+	fmt.Println(p.Skip('\n'))
+	fmt.Println(p.Skip('y'))
+	fmt.Println(string(p.Current()))
+	// Output:
+	// <nil>
+	// 8
+	// <nil>
+	// parse conflict [01:000]: expected int32 'y' but got 'x'
+	// x
+}
+
+func ExampleParser_SkipWhile() {
+	p, _ := parser.New([]byte(" \t\nx"))
+
+	fmt.Println(p.SkipWhile(unicode.IsSpace))
+	fmt.Println(string(p.Current()))
+	// Output:
+	// 3
+	// x
+}
+
+func TestParser_Skip_allocs(t *testing.T) {
+	input := []byte("abc   def")
+	p, _ := parser.New(input)
+	if allocs := testing.AllocsPerRun(100, func() {
+		_ = p.Reset(input)
+		_ = p.Skip("abc")
+		_ = p.SkipWhile(unicode.IsSpace)
+		_ = p.Skip('d')
+	}); allocs != 0 {
+		t.Errorf("expected no allocations, got %v", allocs)
+	}
+}
+
+func TestParser_Skip_trivia(t *testing.T) {
+	for _, i := range []interface{}{'b', "bc"} {
+		p, _ := parser.New([]byte("a  bc"), parser.WithTrivia(' '))
+		_ = p.Skip('a')
+		if err := p.Skip(i); err != nil {
+			t.Errorf("%v: %v", i, err)
+		}
+	}
+}
+
+func TestParser_Skip_context(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p, _ := parser.New([]byte("a"), parser.WithContext(ctx))
+	if err := p.Skip('a'); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the context to be canceled, got %v", err)
+	}
+}
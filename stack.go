@@ -0,0 +1,39 @@
+package parser
+
+// userState is an immutable stack of user values. Cursors keep a reference to
+// the stack, so jumping to a mark restores the stack of that mark.
+type userState struct {
+	value  interface{}
+	parent *userState
+}
+
+// PushState pushes the given value on the user state stack. The stack is part
+// of the position of the parser: jumping back to a mark (e.g. when a value does
+// not match) discards all values that were pushed after the mark was made. This
+// is useful for context-sensitive grammars, e.g. matching closing tags.
+func (p *Parser) PushState(v interface{}) {
+	p.cursor.user = &userState{
+		value:  v,
+		parent: p.cursor.user,
+	}
+}
+
+// PopState removes the top value from the user state stack and returns it.
+// Returns nil if the stack is empty.
+func (p *Parser) PopState() interface{} {
+	top := p.cursor.user
+	if top == nil {
+		return nil
+	}
+	p.cursor.user = top.parent
+	return top.value
+}
+
+// State returns the top value of the user state stack without removing it.
+// Returns nil if the stack is empty.
+func (p *Parser) State() interface{} {
+	if p.cursor.user == nil {
+		return nil
+	}
+	return p.cursor.user.value
+}
@@ -0,0 +1,64 @@
+package parser_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+	"testing"
+)
+
+func ExampleParser_PushState() {
+	name := op.MinOne(parser.CheckRuneRange('a', 'z'))
+	open := func(p *parser.Parser) (*parser.Cursor, bool) {
+		start := p.Mark()
+		if _, ok := p.Check('<', name); !ok {
+			return nil, false
+		}
+		p.PushState(p.Slice(start, p.LookBack())[1:])
+		return p.Check('>')
+	}
+	close := func(p *parser.Parser) (*parser.Cursor, bool) {
+		tag, _ := p.State().(string)
+		if _, ok := p.Check("</", tag, '>'); !ok {
+			return nil, false
+		}
+		p.PopState()
+		return p.LookBack(), true
+	}
+	element := op.And{open, op.MinZero(parser.CheckRuneRange('a', 'z')), close}
+
+	p, _ := parser.New([]byte("<a>text</a><b>text</c>"))
+	fmt.Println(p.Check(element))
+	fmt.Println(p.Check(element))
+	fmt.Println(p.State())
+	// Output:
+	// U+003E: > true
+	// <nil> false
+	// <nil>
+}
+
+func TestParser_PopState(t *testing.T) {
+	p, _ := parser.New([]byte("ab"))
+	start := p.Mark()
+	p.PushState(1)
+	p.Next()
+	mark := p.Mark()
+	p.PushState(2)
+	if v := p.State(); v != 2 {
+		t.Error(v)
+	}
+
+	p.Jump(mark)
+	if v := p.PopState(); v != 1 {
+		t.Error(v)
+	}
+	if v := p.PopState(); v != nil {
+		t.Error(v)
+	}
+
+	// Marks made before a value was pushed do not contain the value.
+	p.Jump(start)
+	if v := p.State(); v != nil {
+		t.Error(v)
+	}
+}
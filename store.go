@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/di-wu/parser/op"
+)
+
+// store converts the text that was matched by the given op.Scan, starting at
+// the given cursor. The converted value is stored into its target once the
+// outermost expectation matches, values that are backtracked over are not
+// stored. Conversion errors are returned as parse errors at the start of the
+// text.
+func (p *Parser) store(scan op.Scan, start *Cursor, text string) error {
+	write, err := convert(text, scan.Into)
+	if err == nil {
+		p.scans = append(p.scans, write)
+		return nil
+	}
+	if _, ok := err.(*ExpectError); ok {
+		// Unsupported target.
+		return err
+	}
+	return p.ExpectedParseError(op.Fail{Message: err.Error()}, start, start)
+}
+
+// writeScans stores the values that were scanned into their targets. Returns
+// the first error of a target that is a function.
+func (p *Parser) writeScans() error {
+	scans := p.scans
+	p.scans = p.scans[:0]
+	for _, write := range scans {
+		if err := write(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// convert converts the given text for the given target, see op.Scan for the
+// supported targets. It returns a function that stores the converted value
+// into the target. Functions are only called when the value is stored.
+func convert(text string, into interface{}) (func() error, error) {
+	switch v := into.(type) {
+	case *string:
+		return func() error { *v = text; return nil }, nil
+	case *[]byte:
+		return func() error { *v = []byte(text); return nil }, nil
+	case *[]string:
+		return func() error { *v = append(*v, text); return nil }, nil
+	case func(string) error:
+		return func() error { return v(text) }, nil
+	}
+
+	rv := reflect.ValueOf(into)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil, &ExpectError{Message: fmt.Sprintf("unsupported scan target %T", into)}
+	}
+	elem := rv.Elem()
+	value := reflect.New(elem.Type())
+	if u, ok := value.Interface().(encoding.TextUnmarshaler); ok {
+		if err := u.UnmarshalText([]byte(text)); err != nil {
+			return nil, err
+		}
+		return func() error { elem.Set(value.Elem()); return nil }, nil
+	}
+	switch v := value.Elem(); elem.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return nil, err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(text, 0, elem.Type().Bits())
+		if err != nil {
+			return nil, err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(text, 0, elem.Type().Bits())
+		if err != nil {
+			return nil, err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(text, elem.Type().Bits())
+		if err != nil {
+			return nil, err
+		}
+		v.SetFloat(f)
+	default:
+		return nil, &ExpectError{Message: fmt.Sprintf("unsupported scan target %T", into)}
+	}
+	return func() error { elem.Set(value.Elem()); return nil }, nil
+}
@@ -0,0 +1,158 @@
+package parser
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+// NewReader creates a new streaming Parser. Instead of keeping the whole input
+// in memory, the parser only keeps a sliding window of the last read bytes in a
+// ring buffer. New data gets read from the reader when the parser advances.
+//
+// Marks pointing before the window get invalidated. Jumping to such a mark
+// stops the parser (it will be Done) and Err returns an InvalidMarkError. Make
+// sure the window is large enough to contain the longest backtrack of your
+// grammar. The window is at least 2 * utf8.UTFMax bytes large.
+func NewReader(r io.Reader, window int, options ...Option) (*Parser, error) {
+	if window < 2*utf8.UTFMax {
+		window = 2 * utf8.UTFMax
+	}
+	p := Parser{
+		decode:   utf8.DecodeRune,
+		tabWidth: defaultTabWidth,
+	}
+	for _, option := range options {
+		option(&p)
+	}
+	if p.bom {
+		r = stripReaderBOM(r)
+	}
+	p.stream = &stream{
+		reader: r,
+		ring: ring{
+			data: make([]byte, window),
+		},
+		chunk: make([]byte, window),
+	}
+	if err := p.init(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Err returns the first error that was encountered while streaming data, not
+// counting io.EOF. Always returns nil for parsers that are not streaming.
+func (p *Parser) Err() error {
+	if p.stream == nil || p.stream.err == io.EOF {
+		return nil
+	}
+	return p.stream.err
+}
+
+// stream reads data from a reader into a ring buffer.
+type stream struct {
+	reader io.Reader
+	ring   ring
+	// chunk is used to read data from the reader.
+	chunk []byte
+	// scratch is used to return a contiguous part of the ring.
+	scratch [utf8.UTFMax]byte
+	// err contains the error returned by the reader, or an InvalidMarkError.
+	err error
+}
+
+// fill reads data from the reader until at least utf8.UTFMax bytes are
+// available starting from the given position. Bytes at or after the given
+// position will never be overwritten.
+func (s *stream) fill(position int) {
+	for s.err == nil && s.ring.end() < position+utf8.UTFMax {
+		room := len(s.ring.data) - (s.ring.end() - position)
+		if len(s.chunk) < room {
+			room = len(s.chunk)
+		}
+		n, err := s.reader.Read(s.chunk[:room])
+		s.ring.write(s.chunk[:n])
+		s.err = err
+	}
+}
+
+// bytes returns (at most utf8.UTFMax) bytes starting from the given position.
+// Returns nil if the position is not inside the window anymore.
+func (s *stream) bytes(position int) []byte {
+	if position < s.ring.start {
+		return nil
+	}
+	s.fill(position)
+	n := s.ring.read(s.scratch[:], position)
+	return s.scratch[:n]
+}
+
+// slice returns a copy of the bytes in between the given positions [from:to].
+// Returns nil if the start position is not inside the window anymore.
+func (s *stream) slice(from, to int) []byte {
+	if from < s.ring.start || to < from {
+		return nil
+	}
+	b := make([]byte, to-from)
+	n := s.ring.read(b, from)
+	return b[:n]
+}
+
+// ring is a fixed size circular buffer of bytes. If the ring is full, writing
+// new data overwrites the oldest bytes.
+type ring struct {
+	data []byte
+	// head is the index of the oldest byte in data.
+	head int
+	// size is the amount of bytes stored in data.
+	size int
+	// start is the (absolute) position of the oldest byte.
+	start int
+}
+
+// end returns the (absolute) position after the newest byte.
+func (r *ring) end() int {
+	return r.start + r.size
+}
+
+// write appends the given bytes to the ring.
+func (r *ring) write(b []byte) {
+	for len(b) != 0 {
+		if r.size == len(r.data) {
+			// Full, drop the oldest bytes.
+			drop := len(b)
+			if r.size < drop {
+				drop = r.size
+			}
+			r.head = (r.head + drop) % len(r.data)
+			r.size -= drop
+			r.start += drop
+		}
+
+		tail := (r.head + r.size) % len(r.data)
+		limit := len(r.data)
+		if tail < r.head {
+			limit = r.head
+		}
+		n := copy(r.data[tail:limit], b)
+		r.size += n
+		b = b[n:]
+	}
+}
+
+// read copies the bytes starting from the given (absolute) position into dst.
+// Returns the number of bytes copied.
+func (r *ring) read(dst []byte, position int) int {
+	offset := position - r.start
+	if offset < 0 || r.size <= offset {
+		return 0
+	}
+	n := r.size - offset
+	if len(dst) < n {
+		n = len(dst)
+	}
+	i := (r.head + offset) % len(r.data)
+	c := copy(dst[:n], r.data[i:])
+	copy(dst[c:n], r.data)
+	return n
+}
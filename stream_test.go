@@ -0,0 +1,69 @@
+package parser_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+	"strings"
+	"testing"
+)
+
+func ExampleNewReader() {
+	p, _ := parser.NewReader(strings.NewReader("GET /index.html\n"), 16)
+
+	method, _ := p.Expect(op.MinOne(parser.CheckRuneRange('A', 'Z')))
+	fmt.Println(method)
+	fmt.Println(p.Expect(' '))
+	start := p.Mark()
+	end, _ := p.Expect(op.MinOne(op.And{op.Not{Value: '\n'}, parser.CheckRuneFunc(func(r rune) bool {
+		return r != parser.EOD
+	})}))
+	fmt.Println(p.Slice(start, end))
+	// Output:
+	// U+0054: T
+	// U+0020:   <nil>
+	// /index.html
+}
+
+func TestNewReader(t *testing.T) {
+	input := strings.Repeat("abc①", 1000)
+	p, err := parser.NewReader(strings.NewReader(input), 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range input {
+		if p.Current() != r {
+			t.Fatalf("expected %c, got %c", r, p.Current())
+		}
+		p.Next()
+	}
+	if !p.Done() {
+		t.Error(p.Current())
+	}
+	if err := p.Err(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNewReader_invalidMark(t *testing.T) {
+	p, _ := parser.NewReader(strings.NewReader(strings.Repeat("a", 64)), 8)
+	start := p.Mark()
+	for i := 0; i < 32; i++ {
+		p.Next()
+	}
+
+	p.Jump(start)
+	if !p.Done() {
+		t.Error("expected parser to be done")
+	}
+	if _, ok := p.Err().(*parser.InvalidMarkError); !ok {
+		t.Error(p.Err())
+	}
+}
+
+func TestNewReader_empty(t *testing.T) {
+	if _, err := parser.NewReader(strings.NewReader(""), 8); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,28 @@
+package parser
+
+// TakeWhile consumes the given value as long as it matches and returns the
+// matched text together with a mark to the last consumed rune. It accepts the
+// same values as Parser.SkipWhile. Returns an empty string and nil if nothing
+// matched.
+func (p *Parser) TakeWhile(i interface{}) (string, *Cursor) {
+	start := p.cursor.position
+	var last Cursor
+	if p.skipWhile(i, &last) == 0 {
+		return "", nil
+	}
+	return p.slice(start, p.cursor.position), &last
+}
+
+// TakeUntil consumes all runes until the given value matches and returns the
+// consumed text together with a mark to the last consumed rune. The matching
+// value itself is not consumed. If the value never matches, all remaining
+// runes are consumed. Returns an empty string and nil if nothing got consumed.
+// See SkipUntil for the values that are scanned for efficiently.
+func (p *Parser) TakeUntil(i interface{}) (string, *Cursor) {
+	start := p.cursor.position
+	var last Cursor
+	if p.skipUntil(i, &last) == 0 {
+		return "", nil
+	}
+	return p.slice(start, p.cursor.position), &last
+}
@@ -0,0 +1,75 @@
+package parser_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+	"testing"
+	"unicode"
+)
+
+func ExampleParser_TakeWhile() {
+	p, _ := parser.New([]byte("foo_bar42 = 1"))
+	identifier := func(r rune) bool {
+		return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+
+	value, last := p.TakeWhile(identifier)
+	fmt.Printf("%q %v\n", value, last)
+	value, last = p.TakeWhile(identifier)
+	fmt.Printf("%q %v\n", value, last)
+	value, _ = p.TakeWhile(' ')
+	fmt.Printf("%q\n", value)
+	// Output:
+	// "foo_bar42" U+0032: 2
+	// "" <nil>
+	// " "
+}
+
+func ExampleParser_TakeUntil() {
+	p, _ := parser.New([]byte("# comment\r\nx"))
+	_ = p.Skip('#')
+
+	value, last := p.TakeUntil("\r\n")
+	fmt.Printf("%q %v\n", value, last)
+	value, last = p.TakeUntil("\r\n")
+	fmt.Printf("%q %v\n", value, last)
+	_ = p.Skip("\r\n")
+	// The last consumed rune is returned at the end of the input.
+	value, last = p.TakeUntil('\n')
+	fmt.Printf("%q %v\n", value, last)
+	// Output:
+	// " comment" U+0074: t
+	// "" <nil>
+	// "x" U+0078: x
+}
+
+func TestParser_TakeWhile_end(t *testing.T) {
+	for _, i := range []interface{}{
+		unicode.IsLetter,
+		parser.CheckRuneRange('a', 'z'),
+		op.Or{"ab", 'c'},
+	} {
+		p, _ := parser.New([]byte("a\nabc"))
+		_ = p.Skip("a\n")
+		value, last := p.TakeWhile(i)
+		if value != "abc" || last == nil || last.Rune != 'c' || last.Offset() != 4 {
+			t.Errorf("%v: unexpected %q %v", i, value, last)
+		}
+		if row, column := last.Position(); row != 1 || column != 2 {
+			t.Errorf("%v: unexpected position %d:%d", i, row, column)
+		}
+	}
+}
+
+func TestParser_TakeUntil_end(t *testing.T) {
+	for _, input := range []string{"abc", "a\tc"} {
+		for _, i := range []interface{}{"\r\n", '\n', op.Or{"\r\n", '\n'}} {
+			p, _ := parser.New([]byte(input))
+			value, last := p.TakeUntil(i)
+			if value != input || last == nil || last.Rune != 'c' || last.Offset() != 2 {
+				t.Errorf("%v on %q: unexpected %q %v", i, input, value, last)
+			}
+		}
+	}
+}
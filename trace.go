@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"fmt"
+	"github.com/di-wu/parser/op"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// WithTrace writes a line to the given writer whenever a named value (e.g. a
+// function) is entered and left, including the position of the parser and the
+// result. Nested values are indented. e.g.
+//
+//	> main.expr [00:000]
+//	  > main.term [00:000]
+//	  < main.term [00:001] ok
+//	< main.expr [00:000] parse conflict [00:001]: expected ...
+func WithTrace(w io.Writer) Option {
+	return func(p *Parser) {
+		p.trace = &trace{
+			writer: w,
+		}
+	}
+}
+
+type trace struct {
+	writer io.Writer
+	// level is the number of named values that were entered, but not left.
+	level int
+}
+
+func (t *trace) enter(p *Parser, i interface{}) {
+	n := name(i)
+	if n == "" {
+		return
+	}
+	row, column := p.cursor.Position()
+	fmt.Fprintf(t.writer, "%s> %s [%02d:%03d]\n", strings.Repeat("  ", t.level), n, row, column)
+	t.level++
+}
+
+func (t *trace) leave(p *Parser, i interface{}, err error) {
+	n := name(i)
+	if n == "" {
+		return
+	}
+	t.level--
+	result := "ok"
+	if err != nil {
+		result = err.Error()
+	}
+	row, column := p.cursor.Position()
+	fmt.Fprintf(t.writer, "%s< %s [%02d:%03d] %s\n", strings.Repeat("  ", t.level), n, row, column, result)
+}
+
+// traced evaluates the given function, which should expect the value of the
+// given trace. The attempt and its result are logged to the writer of the trace.
+func (p *Parser) traced(trace op.Trace, f func() error) error {
+	w := trace.Writer
+	if w == nil {
+		w = os.Stderr
+	}
+	row, column := p.cursor.Position()
+	fmt.Fprintf(w, "> %s [%02d:%03d]\n", trace.Name, row, column)
+	err := f()
+	result := "ok"
+	if err != nil {
+		result = err.Error()
+	}
+	row, column = p.cursor.Position()
+	fmt.Fprintf(w, "< %s [%02d:%03d] %s\n", trace.Name, row, column, result)
+	return err
+}
+
+// name returns the name of the given value, or an empty string if it has no
+// name. Functions are named after their declaration, without the path of their
+// package (e.g. "main.expr"). Anonymous functions have no name. Rules, labels
+// and traces are named by their Name.
+func name(i interface{}) string {
+	if i == nil {
+		return ""
+	}
+	switch v := i.(type) {
+	case op.Rule:
+		return v.Name
+	case op.Label:
+		return v.Name
+	case op.Trace:
+		return v.Name
+	}
+	if v := reflect.ValueOf(i); v.Kind() != reflect.Func || v.IsNil() {
+		return ""
+	}
+	if n := op.String(i); n != "func" {
+		return n
+	}
+	return ""
+}
@@ -0,0 +1,29 @@
+package parser_test
+
+import (
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+	"os"
+)
+
+func digit(p *parser.Parser) (*parser.Cursor, bool) {
+	return p.Check(parser.CheckRuneRange('0', '9'))
+}
+
+func sum(p *parser.Parser) (*parser.Cursor, bool) {
+	return p.Check(digit, '+', digit)
+}
+
+func ExampleWithTrace() {
+	p, _ := parser.New([]byte("1+x"), parser.WithTrace(os.Stdout))
+	_, _ = p.Expect(op.Or{sum, digit})
+	// Output:
+	// > parser_test.sum [00:000]
+	//   > parser_test.digit [00:000]
+	//   < parser_test.digit [00:001] ok
+	//   > parser_test.digit [00:002]
+	//   < parser_test.digit [00:002] parse conflict [00:003]: expected parser.AnonymousClass parser_test.digit but got 'x'
+	// < parser_test.sum [00:000] parse conflict [00:001]: expected parser.AnonymousClass parser_test.sum but got "1+"
+	// > parser_test.digit [00:000]
+	// < parser_test.digit [00:001] ok
+}
@@ -0,0 +1,64 @@
+package parser
+
+// State represents additional state of a parser (e.g. line counters or user
+// data) that needs to be restored when the parser backtracks.
+type State interface {
+	// Snapshot returns a copy of the current state.
+	Snapshot() interface{}
+	// Restore restores the state to the given snapshot.
+	Restore(snapshot interface{})
+}
+
+// Attach attaches the given state to the parser. The state gets restored by
+// Transaction.Rollback and whenever Parser.Expect fails.
+func (p *Parser) Attach(s State) {
+	p.states = append(p.states, s)
+}
+
+// Transaction allows you to speculatively parse values. Rolling back a
+// transaction restores both the position of the parser and all its attached
+// states.
+type Transaction struct {
+	p         *Parser
+	mark      *Cursor
+	snapshots []interface{}
+	scans     int
+	done      bool
+}
+
+// Begin starts a new transaction.
+func (p *Parser) Begin() *Transaction {
+	snapshots := make([]interface{}, len(p.states))
+	for i, s := range p.states {
+		snapshots[i] = s.Snapshot()
+	}
+	return &Transaction{
+		p:         p,
+		mark:      p.Mark(),
+		snapshots: snapshots,
+		scans:     len(p.scans),
+	}
+}
+
+// Commit commits the transaction. The parser keeps its current position and
+// state. Does nothing if the transaction is already done.
+func (tx *Transaction) Commit() {
+	tx.done = true
+}
+
+// Rollback restores the position and attached states of the parser to the
+// moment the transaction began. Does nothing if the transaction is already
+// done.
+func (tx *Transaction) Rollback() {
+	if tx.done {
+		return
+	}
+	tx.done = true
+	tx.p.Jump(tx.mark)
+	if tx.scans < len(tx.p.scans) {
+		tx.p.scans = tx.p.scans[:tx.scans]
+	}
+	for i, snapshot := range tx.snapshots {
+		tx.p.states[i].Restore(snapshot)
+	}
+}
@@ -0,0 +1,53 @@
+package parser_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+)
+
+// counter counts the number of parsed digits.
+type counter struct {
+	digits int
+}
+
+func (c *counter) Snapshot() interface{} {
+	return c.digits
+}
+
+func (c *counter) Restore(snapshot interface{}) {
+	c.digits = snapshot.(int)
+}
+
+func ExampleTransaction() {
+	p, _ := parser.New([]byte("123abc"))
+	c := new(counter)
+	p.Attach(c)
+	digit := func(p *parser.Parser) (*parser.Cursor, bool) {
+		if r := p.Current(); '0' <= r && r <= '9' {
+			c.digits++
+			return p.Mark(), true
+		}
+		return nil, false
+	}
+
+	tx := p.Begin()
+	_, _ = p.Expect(op.MinOne(digit))
+	fmt.Println(c.digits, string(p.Current()))
+	tx.Rollback()
+	fmt.Println(c.digits, string(p.Current()))
+
+	// Failing expectations restore the state automatically.
+	_, err := p.Expect(op.And{op.MinOne(digit), "def"})
+	fmt.Println(c.digits, err != nil)
+
+	tx = p.Begin()
+	_, _ = p.Expect(op.MinOne(digit))
+	tx.Commit()
+	fmt.Println(c.digits, string(p.Current()))
+	// Output:
+	// 3 a
+	// 0 1
+	// 0 true
+	// 3 a
+}
@@ -0,0 +1,85 @@
+package parser
+
+import "github.com/di-wu/parser/op"
+
+// WithTrivia makes the parser skip the given value (e.g. whitespace and
+// comments) automatically before every rune, string and class it expects.
+// Use op.Lexeme and op.NoSkip to disable skipping locally.
+func WithTrivia(i interface{}) Option {
+	return func(p *Parser) {
+		p.trivia = i
+	}
+}
+
+// SetTrivia sets the value that is skipped automatically before every rune,
+// string and class. Nil disables skipping trivia.
+func (p *Parser) SetTrivia(i interface{}) {
+	p.trivia = i
+}
+
+// Trivia returns the value that is skipped automatically. Returns nil if the
+// parser does not skip trivia (at its current position in the grammar).
+func (p *Parser) Trivia() interface{} {
+	return p.trivia
+}
+
+// SkipTrivia skips all trivia at the current position.
+func (p *Parser) SkipTrivia() {
+	if p.trivia == nil {
+		return
+	}
+	// Disable skipping trivia within trivia.
+	trivia := p.trivia
+	p.trivia = nil
+	// Trivia is never expected, so its failures are not reported.
+	p.skipping = true
+	p.SkipWhile(trivia)
+	p.skipping = false
+	p.trivia = trivia
+}
+
+// lexeme expects the given value without skipping trivia.
+func (p *Parser) lexeme(i interface{}) (*Cursor, error) {
+	trivia := p.trivia
+	p.trivia = nil
+	defer func() { p.trivia = trivia }()
+	return p.Expect(i)
+}
+
+// matchText expects the given value, after skipping the leading trivia. It
+// returns the mark at the start of the value, its last mark and the matched
+// text, which does not include the trivia.
+func (p *Parser) matchText(i interface{}) (*Cursor, *Cursor, string, error) {
+	p.SkipTrivia()
+	begin := p.Mark()
+	last, err := p.Expect(i)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	var text string
+	if last != nil {
+		text = p.Slice(begin, last)
+	}
+	return begin, last, text, nil
+}
+
+// matchedText handles the text that was matched by the value of the given
+// op.Capture, op.Scan, op.Reserved or op.Where, see matchText. Last is nil if
+// nothing was matched. Returns an error if the value does not accept the text.
+func (p *Parser) matchedText(i interface{}, begin, last *Cursor, text string) error {
+	switch v := i.(type) {
+	case op.Capture:
+		p.capture(v.Name, text)
+	case op.Scan:
+		return p.store(v, begin, text)
+	case op.Reserved:
+		if last != nil && p.isReserved(v, text) {
+			return p.ExpectedParseError(v, begin, last)
+		}
+	case op.Where:
+		if !v.Pred(text) {
+			return p.ExpectedParseError(v, begin, last)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package parser_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+)
+
+func ExampleWithTrivia() {
+	trivia := op.Or{' ', '\t', '\n'}
+	p, _ := parser.New([]byte("let x =\n  42 ;"), parser.WithTrivia(trivia))
+	digit := parser.CheckRuneRange('0', '9')
+
+	fmt.Println(p.Expect("let", 'x', '=', op.MinOne(digit), ';'))
+
+	p, _ = parser.New([]byte("4 2;"), parser.WithTrivia(trivia))
+	fmt.Println(p.Expect(op.Lexeme{Value: op.MinOne(digit)}, ';'))
+	// Output:
+	// U+003B: ; <nil>
+	// <nil> parse conflict [00:002]: expected func or ';' but got " 2"
+}
+
+func ExampleParser_SkipTrivia() {
+	p, _ := parser.New([]byte(" a  "), parser.WithTrivia(' '))
+
+	fmt.Println(p.Expect(op.NoSkip{Value: 'a'}))
+	fmt.Println(p.Expect('a'))
+	p.SkipTrivia()
+	fmt.Println(p.Done())
+	// Output:
+	// <nil> parse conflict [00:000]: expected int32 'a' but got ' '
+	// U+0061: a <nil>
+	// true
+}
+
+func TestWithTrivia_failure(t *testing.T) {
+	for _, i := range []interface{}{
+		'b',
+		"bc",
+		op.AnyString("b", "bc"),
+		parser.CheckRune('b'),
+		op.Lexeme{Value: 'b'},
+	} {
+		p, _ := parser.New([]byte("   a"), parser.WithTrivia(' '))
+		if _, err := p.Expect(i); err == nil {
+			t.Errorf("%v: expected an error", i)
+		}
+		if offset := p.Offset(); offset != 0 {
+			t.Errorf("%v: trivia consumed, offset %d", i, offset)
+		}
+	}
+}
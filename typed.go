@@ -0,0 +1,27 @@
+//go:build go1.18
+// +build go1.18
+
+package parser
+
+// TypedClass represents a class that also returns a (typed) value, e.g. the
+// integer that was matched. It should return the last mark that matches the
+// class, the same as an AnonymousClass.
+type TypedClass[T any] func(p *Parser) (T, *Cursor, bool)
+
+// ExpectT works the same as Parser.Expect, but returns the value of the given
+// class directly. Returns the zero value of T if the class does not match.
+func ExpectT[T any](p *Parser, class TypedClass[T]) (T, *Cursor, error) {
+	var value T
+	mark, err := p.Expect(AnonymousClass(func(p *Parser) (*Cursor, bool) {
+		v, last, ok := class(p)
+		if ok {
+			value = v
+		}
+		return last, ok
+	}))
+	if err != nil {
+		var zero T
+		return zero, nil, err
+	}
+	return value, mark, nil
+}
@@ -0,0 +1,292 @@
+//go:build go1.18
+// +build go1.18
+
+// Package typed provides combinators that return typed values instead of marks,
+// e.g. an expression that matches a list of integers returns an []int.
+//
+// Typed expressions call each other directly. Only the values at the leaves get
+// expected by the parser, they get boxed once when the expression is created.
+// This avoids the boxing of the combinators and their results in hot paths.
+package typed
+
+import (
+	"errors"
+
+	"github.com/di-wu/parser"
+)
+
+// Expr is a typed expression. It returns its value and a mark to the last rune
+// it consumed. On failure, the parser should be back at the start. Expressions
+// should be expected with Expect.
+type Expr[T any] func(p *parser.Parser) (T, *parser.Cursor, error)
+
+// Pair contains the values of two expressions, see And.
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+// Expect expects the given expression as a whole, the same as Parser.Expect
+// does for a value. This makes sure that errors that stop the parser (e.g. a
+// cut) are not lost between the values of the expression. Returns the zero
+// value of T if it does not match.
+func Expect[T any](p *parser.Parser, e Expr[T]) (T, *parser.Cursor, error) {
+	var (
+		zero  T
+		value T
+		last  *parser.Cursor
+		start = p.Mark()
+	)
+	if _, err := p.Extension().Run(e, func(interface{}) (_ interface{}, err error) {
+		value, last, err = e(p)
+		return nil, err
+	}); err != nil {
+		p.Jump(start)
+		return zero, nil, err
+	}
+	return value, last, nil
+}
+
+// Value matches the given (untyped) value and returns the matched text, not
+// including the leading trivia.
+func Value(i interface{}) Expr[string] {
+	return func(p *parser.Parser) (string, *parser.Cursor, error) {
+		start := p.Mark()
+		p.SkipTrivia()
+		begin := p.Mark()
+		last, err := p.Expect(i)
+		if err != nil {
+			p.Jump(start)
+			return "", nil, err
+		}
+		if last == nil {
+			return "", nil, nil
+		}
+		return p.Slice(begin, last), last, nil
+	}
+}
+
+// Class matches the given typed class and returns its value.
+func Class[T any](class parser.TypedClass[T]) Expr[T] {
+	return func(p *parser.Parser) (T, *parser.Cursor, error) {
+		return parser.ExpectT(p, class)
+	}
+}
+
+// Map converts the value of the given expression with the given function.
+func Map[A, B any](e Expr[A], f func(A) B) Expr[B] {
+	return func(p *parser.Parser) (B, *parser.Cursor, error) {
+		a, last, err := e(p)
+		if err != nil {
+			var zero B
+			return zero, nil, err
+		}
+		return f(a), last, nil
+	}
+}
+
+// And matches both expressions in sequence and returns both values.
+func And[A, B any](a Expr[A], b Expr[B]) Expr[Pair[A, B]] {
+	return func(p *parser.Parser) (Pair[A, B], *parser.Cursor, error) {
+		var pair Pair[A, B]
+		start := p.Mark()
+		first, last, err := a(p)
+		if err != nil {
+			return pair, nil, err
+		}
+		second, mark, err := b(p)
+		if err != nil {
+			p.Jump(start)
+			return pair, nil, err
+		}
+		if mark != nil {
+			last = mark
+		}
+		pair.First, pair.Second = first, second
+		return pair, last, nil
+	}
+}
+
+// Left matches both expressions in sequence and returns the first value.
+func Left[A, B any](a Expr[A], b Expr[B]) Expr[A] {
+	return Map(And(a, b), func(pair Pair[A, B]) A {
+		return pair.First
+	})
+}
+
+// Right matches both expressions in sequence and returns the second value.
+func Right[A, B any](a Expr[A], b Expr[B]) Expr[B] {
+	return Map(And(a, b), func(pair Pair[A, B]) B {
+		return pair.Second
+	})
+}
+
+// Or returns the value of the first expression that matches. If none of them
+// match, it returns the error of the one that got the farthest.
+func Or[T any](es ...Expr[T]) Expr[T] {
+	return func(p *parser.Parser) (T, *parser.Cursor, error) {
+		var (
+			farthest error
+			offset   = -1
+		)
+		for _, e := range es {
+			value, last, err := e(p)
+			if err == nil {
+				return value, last, nil
+			}
+			var cut *parser.CutError
+			if errors.As(err, &cut) {
+				// Do not try the other alternatives.
+				var zero T
+				return zero, nil, cut.Err
+			}
+			if o := conflict(err); farthest == nil || offset < o {
+				farthest, offset = err, o
+			}
+		}
+		var zero T
+		return zero, nil, farthest
+	}
+}
+
+// conflict returns the offset of the conflict of the given error, or -1 if it
+// is unknown.
+func conflict(err error) int {
+	var expected *parser.ExpectedParseError
+	if errors.As(err, &expected) {
+		return expected.Conflict.Offset()
+	}
+	return -1
+}
+
+// Optional returns the given default value if the expression does not match.
+func Optional[T any](e Expr[T], def T) Expr[T] {
+	return func(p *parser.Parser) (T, *parser.Cursor, error) {
+		value, last, err := e(p)
+		if err != nil {
+			return def, nil, nil
+		}
+		return value, last, nil
+	}
+}
+
+// Many matches the given expression zero or more times and returns all values.
+func Many[T any](e Expr[T]) Expr[[]T] {
+	return func(p *parser.Parser) ([]T, *parser.Cursor, error) {
+		return many(p, e, nil, nil)
+	}
+}
+
+// MinOne matches the given expression one or more times and returns all values.
+func MinOne[T any](e Expr[T]) Expr[[]T] {
+	return func(p *parser.Parser) ([]T, *parser.Cursor, error) {
+		value, last, err := e(p)
+		if err != nil {
+			return nil, nil, err
+		}
+		return many(p, e, []T{value}, last)
+	}
+}
+
+// many appends the values of the given expression until it does not match.
+func many[T any](p *parser.Parser, e Expr[T], values []T, last *parser.Cursor) ([]T, *parser.Cursor, error) {
+	for {
+		offset := p.Offset()
+		value, mark, err := e(p)
+		if err != nil {
+			return values, last, nil
+		}
+		values = append(values, value)
+		if mark != nil {
+			last = mark
+		}
+		if p.Offset() == offset {
+			if err := p.Extension().NoProgress(e); err != nil {
+				return nil, nil, err
+			}
+			return values, last, nil
+		}
+	}
+}
+
+// SeparatedBy matches one or more values of the given expression, separated by
+// the given separator. Returns the values of the elements.
+func SeparatedBy[T, S any](e Expr[T], separator Expr[S]) Expr[[]T] {
+	next := Right(separator, e)
+	return func(p *parser.Parser) ([]T, *parser.Cursor, error) {
+		value, last, err := e(p)
+		if err != nil {
+			return nil, nil, err
+		}
+		return many(p, next, []T{value}, last)
+	}
+}
+
+// ChainLeft matches one or more operands separated by operators. The values of
+// the operands are combined with the given function, from the left. e.g. "1 - 2
+// - 3" results in fold(fold(1, "-", 2), "-", 3). If an operator is not followed
+// by an operand, the chain ends before the operator.
+func ChainLeft[T, O any](operand Expr[T], operator Expr[O], fold func(left T, operator O, right T) T) Expr[T] {
+	return func(p *parser.Parser) (T, *parser.Cursor, error) {
+		operands, operators, last, err := chain(p, operand, operator)
+		if err != nil {
+			var zero T
+			return zero, nil, err
+		}
+		value := operands[0]
+		for i, o := range operators {
+			value = fold(value, o, operands[i+1])
+		}
+		return value, last, nil
+	}
+}
+
+// ChainRight works the same as ChainLeft, but combines the values from the
+// right. e.g. "2 ^ 3 ^ 4" results in fold(2, "^", fold(3, "^", 4)).
+func ChainRight[T, O any](operand Expr[T], operator Expr[O], fold func(left T, operator O, right T) T) Expr[T] {
+	return func(p *parser.Parser) (T, *parser.Cursor, error) {
+		operands, operators, last, err := chain(p, operand, operator)
+		if err != nil {
+			var zero T
+			return zero, nil, err
+		}
+		value := operands[len(operands)-1]
+		for i := len(operators) - 1; 0 <= i; i-- {
+			value = fold(operands[i], operators[i], value)
+		}
+		return value, last, nil
+	}
+}
+
+// chain matches one or more operands separated by operators. Returns the values
+// of the operands and the operators in between them.
+func chain[T, O any](p *parser.Parser, operand Expr[T], operator Expr[O]) ([]T, []O, *parser.Cursor, error) {
+	value, last, err := operand(p)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	operands := []T{value}
+	var operators []O
+	for {
+		mark := p.Mark()
+		o, _, err := operator(p)
+		if err != nil {
+			break
+		}
+		value, end, err := operand(p)
+		if err != nil {
+			p.Jump(mark)
+			break
+		}
+		if p.Offset() == mark.Offset() {
+			// Nothing was consumed, stop instead of repeating it forever.
+			break
+		}
+		if end != nil {
+			last = end
+		}
+		operands = append(operands, value)
+		operators = append(operators, o)
+	}
+	return operands, operators, last, nil
+}
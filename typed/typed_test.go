@@ -0,0 +1,132 @@
+//go:build go1.18
+// +build go1.18
+
+package typed_test
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+	"github.com/di-wu/parser/typed"
+)
+
+var (
+	digits  = op.MinOne(parser.CheckRuneRange('0', '9'))
+	integer = typed.Map(typed.Value(digits), func(s string) int {
+		i, _ := strconv.Atoi(s)
+		return i
+	})
+	list = typed.Left(
+		typed.Right(typed.Value('['), typed.SeparatedBy(integer, typed.Value(", "))),
+		typed.Value(']'),
+	)
+)
+
+func Example() {
+	p, _ := parser.New([]byte("[1, 22, 333]"))
+	fmt.Println(typed.Expect(p, list))
+	// Output:
+	// [1 22 333] U+005D: ] <nil>
+}
+
+func ExampleOr() {
+	boolean := typed.Or(
+		typed.Map(typed.Value("true"), func(string) bool { return true }),
+		typed.Map(typed.Value("false"), func(string) bool { return false }),
+	)
+
+	p, _ := parser.New([]byte("false"))
+	fmt.Println(typed.Expect(p, boolean))
+	p, _ = parser.New([]byte("fals"))
+	fmt.Println(typed.Expect(p, boolean))
+	// Output:
+	// false U+0065: e <nil>
+	// false <nil> parse conflict [00:004]: expected string "false" but got "fals"
+}
+
+func ExampleChainLeft() {
+	sub := typed.ChainLeft(integer, typed.Value('-'), func(left int, _ string, right int) int {
+		return left - right
+	})
+	pow := typed.ChainRight(integer, typed.Value('^'), func(left int, _ string, right int) int {
+		value := 1
+		for i := 0; i < right; i++ {
+			value *= left
+		}
+		return value
+	})
+
+	p, _ := parser.New([]byte("10-2-3"))
+	fmt.Println(typed.Expect(p, sub))
+	p, _ = parser.New([]byte("2^3^2"))
+	fmt.Println(typed.Expect(p, pow))
+	// Output:
+	// 5 U+0033: 3 <nil>
+	// 512 U+0032: 2 <nil>
+}
+
+func ExampleExpect() {
+	p, _ := parser.New([]byte("[1, 2,]"))
+	// The trailing separator is not part of the list.
+	fmt.Println(typed.Expect(p, list))
+	// The parser is back at the start of the list.
+	fmt.Println(p.Offset())
+	// Output:
+	// [] <nil> parse conflict [00:005]: expected int32 ']' but got ','
+	// 0
+}
+
+func TestOptional(t *testing.T) {
+	sign := typed.Optional(typed.Value('-'), "+")
+	p, _ := parser.New([]byte("1"))
+	if s, last, err := typed.Expect(p, sign); s != "+" || last != nil || err != nil {
+		t.Error(s, last, err)
+	}
+}
+
+func TestChainLeft_noProgress(t *testing.T) {
+	// Both the operand and the operator can match nothing.
+	sub := typed.ChainLeft(
+		typed.Value(op.MinZero(parser.CheckRuneRange('0', '9'))), typed.Value(op.Optional('-')),
+		func(left string, _ string, right string) string {
+			return left + "-" + right
+		},
+	)
+	p, _ := parser.New([]byte("1x"))
+	if v, _, err := typed.Expect(p, sub); v != "1" || err != nil || p.Current() != 'x' {
+		t.Error(v, err, p.Mark())
+	}
+}
+
+func TestExpect_cut(t *testing.T) {
+	statement := typed.Or(
+		typed.Value(op.And{"if", ' ', op.Cut{}, digits}),
+		typed.Value(op.MinOne(parser.CheckRuneRange('a', 'z'))),
+	)
+	p, _ := parser.New([]byte("if x"))
+	if _, _, err := typed.Expect(p, statement); err == nil {
+		t.Error("expected the cut to skip the second alternative")
+	}
+
+	// The cut does not reach beyond the Or.
+	p, _ = parser.New([]byte("if x"))
+	if v, _, err := typed.Expect(p, typed.Many(statement)); len(v) != 0 || err != nil {
+		t.Error(v, err)
+	}
+}
+
+func BenchmarkExpect(b *testing.B) {
+	input := []byte("[" + strings.Repeat("1, 22, 333, ", 64) + "0]")
+	p, _ := parser.New(input)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = p.Reset(input)
+		if _, _, err := typed.Expect(p, list); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
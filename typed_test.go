@@ -0,0 +1,44 @@
+//go:build go1.18
+// +build go1.18
+
+package parser_test
+
+import (
+	"fmt"
+	"github.com/di-wu/parser"
+	"github.com/di-wu/parser/op"
+	"strconv"
+	"testing"
+)
+
+// integer matches a sequence of digits and returns its value.
+func integer(p *parser.Parser) (int, *parser.Cursor, bool) {
+	start := p.Mark()
+	end, ok := p.Check(op.MinOne(parser.CheckRuneRange('0', '9')))
+	if !ok {
+		return 0, nil, false
+	}
+	i, err := strconv.Atoi(p.Slice(start, end))
+	return i, end, err == nil
+}
+
+func ExampleExpectT() {
+	p, _ := parser.New([]byte("40+2"))
+	a, _, _ := parser.ExpectT(p, integer)
+	_, _ = p.Expect('+')
+	b, _, _ := parser.ExpectT(p, integer)
+	fmt.Println(a + b)
+	// Output:
+	// 42
+}
+
+func TestExpectT(t *testing.T) {
+	p, _ := parser.New([]byte("x"))
+	i, mark, err := parser.ExpectT(p, integer)
+	if err == nil || i != 0 || mark != nil {
+		t.Error(i, mark, err)
+	}
+	if p.Offset() != 0 {
+		t.Error(p.Offset())
+	}
+}
@@ -0,0 +1,12 @@
+//go:build go1.20
+// +build go1.20
+
+package parser
+
+import "unsafe"
+
+// unsafeBytes returns the bytes of the given string without copying them. The
+// returned slice must never be modified.
+func unsafeBytes(s string) []byte {
+	return unsafe.Slice(unsafe.StringData(s), len(s))
+}
@@ -0,0 +1,10 @@
+//go:build !go1.20
+// +build !go1.20
+
+package parser
+
+// unsafeBytes returns the bytes of the given string. Older versions of Go have
+// no safe way to share the data of the string, so the bytes get copied.
+func unsafeBytes(s string) []byte {
+	return []byte(s)
+}